@@ -0,0 +1,37 @@
+// Package blobstore keeps file contents addressed by their SHA-256 digest.
+// Storing the same bytes twice keeps a single copy; callers record the key
+// and decide when a blob is no longer needed.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned for keys the store doesn't hold.
+var ErrNotFound = errors.New("blob not found")
+
+// Blob describes stored content. Key is the lowercase hex SHA-256 of the
+// content. ModTime is when the blob was last written or deduplicated into.
+type Blob struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// BlobStore is implemented by LocalStore. Another backend, such as an
+// S3-compatible bucket, only has to provide these operations.
+type BlobStore interface {
+	// Put streams r into the store. If r fails, nothing is stored and the
+	// reader's error is returned unchanged.
+	Put(ctx context.Context, r io.Reader) (Blob, error)
+	// Open returns the content of a blob, seekable so it can serve range
+	// requests.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, Blob, error)
+	// Delete removes a blob. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	// Walk calls fn for every stored blob, stopping at the first error.
+	Walk(ctx context.Context, fn func(Blob) error) error
+}
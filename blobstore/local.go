@@ -0,0 +1,161 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalStore keeps blobs as files under a directory, sharded by the first
+// two hex digits of the key: <root>/ab/abcdef.... Uploads are written to
+// <root>/tmp and renamed into place, so a blob is never seen half written.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(filepath.Join(root, "tmp"), 0o755); err != nil {
+		return nil, fmt.Errorf("create blob store: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, r io.Reader) (Blob, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "upload-*")
+	if err != nil {
+		return Blob{}, fmt.Errorf("create temp blob: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // a no-op once renamed
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		tmp.Close()
+		return Blob{}, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return Blob{}, fmt.Errorf("sync blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Blob{}, fmt.Errorf("close blob: %w", err)
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	path := s.path(key)
+	now := time.Now()
+
+	if _, err := os.Stat(path); err == nil {
+		// Already stored; refresh the time so it reads as recently used. If
+		// it was collected in the meantime, store it again below.
+		err := os.Chtimes(path, now, now)
+		if err == nil {
+			return Blob{Key: key, Size: size, ModTime: now}, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return Blob{}, fmt.Errorf("touch blob: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return Blob{}, fmt.Errorf("create blob directory: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Blob{}, fmt.Errorf("store blob: %w", err)
+	}
+	return Blob{Key: key, Size: size, ModTime: now}, nil
+}
+
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, Blob, error) {
+	if !validKey(key) {
+		return nil, Blob{}, ErrNotFound
+	}
+
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, Blob{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Blob{}, fmt.Errorf("open blob: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, Blob{}, fmt.Errorf("stat blob: %w", err)
+	}
+	return f, Blob{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return nil
+	}
+	err := os.Remove(s.path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Walk(ctx context.Context, fn func(Blob) error) error {
+	shards, err := os.ReadDir(s.root)
+	if err != nil {
+		return fmt.Errorf("list blobs: %w", err)
+	}
+
+	for _, shard := range shards {
+		if !shard.IsDir() || len(shard.Name()) != 2 {
+			continue // tmp, or something that isn't ours
+		}
+
+		entries, err := os.ReadDir(filepath.Join(s.root, shard.Name()))
+		if err != nil {
+			return fmt.Errorf("list blobs: %w", err)
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !validKey(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // deleted meanwhile
+			}
+			if err != nil {
+				return fmt.Errorf("stat blob: %w", err)
+			}
+			if err := fn(Blob{Key: entry.Name(), Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, key[:2], key)
+}
+
+// validKey accepts only lowercase hex SHA-256 digests, so a key can never
+// name a path outside the store.
+func validKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range key {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Assignee is a user assigned to a task. Email is filled in when the
+// assignment is read back.
+type Assignee struct {
+	TaskID     int       `json:"task_id"`
+	UserID     int       `json:"user_id"`
+	Email      string    `json:"email"`
+	AssignedBy int       `json:"assigned_by"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
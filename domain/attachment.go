@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Attachment is a file attached to a task. The content lives in the blob
+// store under BlobKey, the SHA-256 of the bytes; attachments with the same
+// content share one blob.
+type Attachment struct {
+	ID          int       `json:"id"`
+	TaskID      int       `json:"task_id"`
+	BlobKey     string    `json:"blob_key"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  int       `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
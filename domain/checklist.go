@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// ChecklistItem is a step inside a task, lighter than a subtask: it has only
+// text and a done flag. Items are kept in Position order.
+type ChecklistItem struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Comment is a message on a task. Comments thread one level deep: ParentID
+// is 0 for a top-level comment or the ID of the top-level comment it
+// replies to. Deleting a comment only sets DeletedAt, so its replies keep
+// their place in the thread.
+type Comment struct {
+	ID          int        `json:"id"`
+	TaskID      int        `json:"task_id"`
+	AuthorID    int        `json:"author_id"`
+	AuthorEmail string     `json:"author_email"`
+	ParentID    int        `json:"parent_id,omitempty"`
+	Body        string     `json:"body"`
+	Mentions    []int      `json:"mentions,omitempty"` // IDs of the users @mentioned in Body
+	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+func (c Comment) Deleted() bool {
+	return c.DeletedAt != nil
+}
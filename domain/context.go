@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// UserFromContext returns the user AuthMiddleware stored in ctx, or false for
+// anonymous requests.
+func UserFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value("user").(*User)
+	return user, ok && user != nil
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Dependency records that TaskID is blocked by BlockedByID: it can't be
+// completed until BlockedByID is.
+type Dependency struct {
+	TaskID      int       `json:"task_id"`
+	BlockedByID int       `json:"blocked_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type ValidationError struct {
 	Field   string // Which field failed (e.g., "title")
@@ -11,6 +14,42 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("Validation error on field '%s': %s", e.Field, e.Message)
 }
 
+// ValidationErrors collects every field that failed validation so clients
+// can fix a request in one round trip instead of one field at a time.
+type ValidationErrors struct {
+	Errors []*ValidationError
+}
+
+func (e *ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("'%s' %s", fieldErr.Field, fieldErr.Message))
+	}
+	return fmt.Sprintf("Validation failed: %s", strings.Join(messages, "; "))
+}
+
+func (e *ValidationErrors) Add(field, message string) {
+	e.Errors = append(e.Errors, &ValidationError{Field: field, Message: message})
+}
+
+type PayloadTooLargeError struct {
+	Limit int64 // Maximum accepted body size in bytes
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("Request body exceeds the %d byte limit", e.Limit)
+}
+
+// TooManyRequestsError refuses a request over a per-user limit, such as
+// open event streams.
+type TooManyRequestsError struct {
+	Message string
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return e.Message
+}
+
 type NotFoundError struct {
 	Resource string // The resource that was not found (e.g., "Task")
 	ID       int    // The ID of the resource
@@ -29,6 +68,16 @@ func (e *DatabaseError) Error() string {
 	return fmt.Sprintf("Database error during %s: %v", e.Operation, e.Err)
 }
 
+// BatchAbortedError marks an operation that was valid on its own but was not
+// applied because another operation in the same atomic batch failed.
+type BatchAbortedError struct {
+	FailedIndex int // Index of the operation that caused the rollback
+}
+
+func (e *BatchAbortedError) Error() string {
+	return fmt.Sprintf("Not applied: operation %d in the same atomic batch failed", e.FailedIndex)
+}
+
 type AuthenticationError struct {
 	Message string
 }
@@ -45,6 +94,16 @@ func (e *UnauthorizedError) Error() string {
 	return e.Message
 }
 
+// ForbiddenError is returned when the user is authenticated but may not
+// perform the operation, such as editing a view someone else shared.
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
 type QueueError struct {
 	Message string
 }
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	IdempotencyPending   = "pending"
+	IdempotencyCompleted = "completed"
+)
+
+// IdempotencyRecord is the stored outcome of a request sent with an
+// Idempotency-Key header. Records are scoped per user so two clients cannot
+// collide on the same key.
+type IdempotencyRecord struct {
+	Key         string
+	UserID      int
+	Method      string
+	Path        string
+	Fingerprint string // SHA-256 of method, path and body
+	State       string
+	StatusCode  int
+	Headers     http.Header
+	Body        []byte
+	CreatedAt   time.Time
+	LockedUntil time.Time // a pending record past this time is abandoned
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// Label categorizes tasks. Labels belong to the user who created them;
+// shared labels can be applied by anyone but changed only by their owner.
+type Label struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Shared    bool      `json:"shared"`
+	CreatedAt time.Time `json:"created_at"`
+}
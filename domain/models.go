@@ -1,9 +1,111 @@
 package domain
 
+import "time"
+
+const (
+	StatusPending    = "Pending"
+	StatusInProgress = "In Progress"
+	StatusCompleted  = "Completed"
+)
+
+const (
+	PriorityLow    = "Low"
+	PriorityMedium = "Medium"
+	PriorityHigh   = "High"
+)
+
 type Task struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Priority    string `json:"priority"`
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Version     int        `json:"version"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Labels      []Label    `json:"labels,omitempty"`
+	ProjectID   int        `json:"project_id,omitempty"` // 0 when the task is in no project
+	Assignees   []Assignee `json:"assignees,omitempty"`
+	ParentID    int        `json:"parent_id,omitempty"` // 0 for top-level tasks
+	// AutoComplete marks the task Completed once every subtask and
+	// checklist item is done.
+	AutoComplete bool            `json:"auto_complete"`
+	Checklist    []ChecklistItem `json:"checklist,omitempty"`
+	// Subtasks and SubtasksDone count direct children; they are computed
+	// when the task is read and never written.
+	Subtasks     int `json:"subtasks"`
+	SubtasksDone int `json:"subtasks_done"`
+	// EstimateMinutes is how much work the task is expected to take; 0
+	// when it hasn't been estimated.
+	EstimateMinutes int `json:"estimate_minutes,omitempty"`
+	// BlockedBy and Blocks list the tasks this one depends on and the tasks
+	// that depend on it. Blocked is set while any of BlockedBy is not
+	// completed. All three are computed when the task is read.
+	BlockedBy []int `json:"blocked_by,omitempty"`
+	Blocks    []int `json:"blocks,omitempty"`
+	Blocked   bool  `json:"blocked"`
+	// Comments counts the task's comments that haven't been deleted.
+	Comments int `json:"comments"`
+	// OccurrenceOf is the recurring template the task was created from, or
+	// 0. It is computed when the task is read.
+	OccurrenceOf int `json:"occurrence_of,omitempty"`
+}
+
+// Progress is the percentage of the task's direct subtasks and checklist
+// items that are done. A task with neither is 0% until it is completed, and
+// a completed task is always 100%.
+func (t Task) Progress() int {
+	if t.Status == StatusCompleted {
+		return 100
+	}
+
+	total, done := t.Subtasks, t.SubtasksDone
+	for _, item := range t.Checklist {
+		total++
+		if item.Done {
+			done++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return done * 100 / total
+}
+
+// ChildrenDone reports whether the task has subtasks or checklist items and
+// all of them are done.
+func (t Task) ChildrenDone() bool {
+	if t.Subtasks == 0 && len(t.Checklist) == 0 {
+		return false
+	}
+	if t.SubtasksDone < t.Subtasks {
+		return false
+	}
+	for _, item := range t.Checklist {
+		if !item.Done {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// TaskOperation is one write in a batch applied by TaskRepository.ApplyBatch.
+// Task.ID identifies the target of updates and deletes.
+type TaskOperation struct {
+	Kind string
+	Task Task
+}
+
+// TaskOperationResult is the outcome of a single TaskOperation. Applied is
+// false when the operation failed or was rolled back with its batch.
+type TaskOperationResult struct {
+	Task    Task
+	Applied bool
+	Err     error
 }
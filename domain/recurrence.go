@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// Recurrence modes: when the next occurrence of a recurring task is
+// created.
+const (
+	// RecurrenceOnSchedule creates each occurrence when its time arrives,
+	// whether or not the previous one is done.
+	RecurrenceOnSchedule = "schedule"
+	// RecurrenceOnCompletion creates the next occurrence, ahead of its
+	// time, once the previous one is completed or deleted.
+	RecurrenceOnCompletion = "completion"
+)
+
+// Recurrence makes a task a template that is copied into a new task for
+// every occurrence of Rule, an RFC 5545 RRULE. StartsAt is the first
+// occurrence and Timezone the IANA zone the rule is evaluated in.
+type Recurrence struct {
+	TaskID    int       `json:"task_id"`
+	Rule      string    `json:"rule"`
+	Timezone  string    `json:"timezone"`
+	StartsAt  time.Time `json:"starts_at"`
+	Mode      string    `json:"mode"`
+	CreatedAt time.Time `json:"created_at"`
+	// Last is the most recent occurrence created, nil before the first.
+	// LastTaskID is the task created for it, 0 once that task is deleted,
+	// and LastOpen whether that task exists and isn't completed.
+	Last       *time.Time `json:"last,omitempty"`
+	LastTaskID int        `json:"last_task_id,omitempty"`
+	LastOpen   bool       `json:"last_open"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Reminder channels: how a reminder reaches its user.
+const (
+	ChannelInApp   = "in_app"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// Reminder states. A reminder is pending until it is delivered (sent),
+// dropped because it no longer applies (skipped), or given up on after
+// repeated delivery failures (failed).
+const (
+	ReminderPending = "pending"
+	ReminderSent    = "sent"
+	ReminderSkipped = "skipped"
+	ReminderFailed  = "failed"
+)
+
+// Reminder tells UserID about a task, either at RemindAt or OffsetMinutes
+// from the task's due date (negative for before it). Exactly one of the two
+// is set.
+type Reminder struct {
+	ID            int        `json:"id"`
+	TaskID        int        `json:"task_id"`
+	UserID        int        `json:"user_id"`
+	RemindAt      *time.Time `json:"remind_at,omitempty"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty"`
+	Channel       string     `json:"channel"`
+	Status        string     `json:"status"`
+	// FireAt is when the reminder is due, computed when it is read; nil for
+	// a relative reminder on a task without a due date. DeliverAfter holds
+	// delivery back past FireAt, for quiet hours or a retry.
+	FireAt       *time.Time `json:"fire_at,omitempty"`
+	DeliverAfter *time.Time `json:"deliver_after,omitempty"`
+	Attempts     int        `json:"attempts"`
+	LastError    string     `json:"last_error,omitempty"`
+	SentAt       *time.Time `json:"sent_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Notification is an in-app message for a user, such as a delivered
+// reminder.
+type Notification struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	TaskID     int        `json:"task_id"`
+	ReminderID int        `json:"reminder_id,omitempty"`
+	Message    string     `json:"message"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+}
+
+// NotificationSettings are a user's delivery preferences. Timezone is an
+// IANA name. QuietStart and QuietEnd are "HH:MM" in that zone, both empty
+// when the user has no quiet hours; reminders due in between wait until
+// QuietEnd.
+type NotificationSettings struct {
+	UserID     int    `json:"user_id"`
+	Timezone   string `json:"timezone"`
+	QuietStart string `json:"quiet_start,omitempty"`
+	QuietEnd   string `json:"quiet_end,omitempty"`
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+// TaskSearchHit is one search result. TitleHighlight and Snippet mark matched
+// words with search.MarkStart and search.MarkEnd.
+type TaskSearchHit struct {
+	Task           Task
+	Score          float64 // higher is more relevant
+	TitleHighlight string
+	Snippet        string
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// SavedView is a named filter a user can re-run from the sidebar. Query uses
+// the taskquery syntax and Sort a taskquery sort spec. Shared views are
+// visible to every user but only editable by their owner.
+type SavedView struct {
+	ID        int
+	UserID    int
+	Name      string
+	Query     string
+	Sort      string
+	Columns   []string
+	Shared    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// Webhook delivery states. A delivery is pending until the endpoint accepts
+// it (succeeded) or every attempt has failed (failed).
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook sends the events in Events, for tasks OwnerID can see, to URL.
+// Requests are signed with Secret. An endpoint that keeps failing is
+// disabled: Active turns false and DisabledAt is set.
+type Webhook struct {
+	ID         int        `json:"id"`
+	OwnerID    int        `json:"owner_id"`
+	URL        string     `json:"url"`
+	Secret     string     `json:"-"`
+	Events     []string   `json:"events"`
+	Active     bool       `json:"active"`
+	Failures   int        `json:"failures"` // consecutive failed attempts
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook wants events of eventType.
+func (w Webhook) Subscribes(eventType string) bool {
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one webhook. Payload is the
+// request body, fixed when the event is queued so every attempt, and every
+// redelivery, sends the same bytes.
+type WebhookDelivery struct {
+	ID            int              `json:"id"`
+	WebhookID     int              `json:"webhook_id"`
+	Event         string           `json:"event"`
+	Payload       []byte           `json:"-"`
+	Status        string           `json:"status"`
+	Attempts      int              `json:"attempts"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+	ResponseCode  int              `json:"response_code,omitempty"` // of the last attempt
+	LastError     string           `json:"last_error,omitempty"`
+	RedeliveryOf  int              `json:"redelivery_of,omitempty"`
+	DeliveredAt   *time.Time       `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Log           []WebhookAttempt `json:"log,omitempty"`
+}
+
+// WebhookAttempt records one request to the endpoint. ResponseCode is 0
+// when no response arrived; Error then says why.
+type WebhookAttempt struct {
+	ID           int       `json:"id"`
+	DeliveryID   int       `json:"delivery_id"`
+	ResponseCode int       `json:"response_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMS   int       `json:"duration_ms"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Workspace roles, from least to most privileged. Viewers can read the
+// workspace's tasks, members can also change them, admins manage projects
+// and members, and owners can additionally manage admins.
+const (
+	RoleViewer = "viewer"
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
+)
+
+var roleRank = map[string]int{RoleViewer: 1, RoleMember: 2, RoleAdmin: 3, RoleOwner: 4}
+
+// RoleAtLeast reports whether role grants everything min does. An empty or
+// unknown role grants nothing.
+func RoleAtLeast(role, min string) bool {
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[min]
+}
+
+type Workspace struct {
+	ID        int
+	Name      string
+	CreatedBy int
+	CreatedAt time.Time
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int
+	UserID      int
+	Email       string
+	Role        string
+	CreatedAt   time.Time
+}
+
+// Project belongs to one workspace. Archived projects stay readable but
+// their tasks can no longer be changed.
+type Project struct {
+	ID          int
+	WorkspaceID int
+	Name        string
+	Description string
+	ArchivedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (p Project) Archived() bool {
+	return p.ArchivedAt != nil
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// AttachmentDTO describes a file attached to a task. Checksum is the hex
+// SHA-256 of the content.
+type AttachmentDTO struct {
+	ID          int       `json:"id"`
+	TaskID      int       `json:"task_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	UploadedBy  int       `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
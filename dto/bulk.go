@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+const (
+	BulkModeAtomic  = "atomic"
+	BulkModePartial = "partial"
+)
+
+type BulkTaskRequestDTO struct {
+	Mode       string                 `json:"mode" validate:"omitempty,oneof=atomic partial"`
+	Operations []BulkTaskOperationDTO `json:"operations" validate:"required,min=1,max=100"`
+}
+
+// BulkTaskOperationDTO is a single create, update or delete. Task carries the
+// fields for create and update; ID targets update and delete.
+type BulkTaskOperationDTO struct {
+	Op   string       `json:"op" validate:"required,oneof=create update delete"`
+	ID   int          `json:"id,omitempty" validate:"omitempty,min=1"`
+	Task *BulkTaskDTO `json:"task,omitempty"`
+}
+
+// BulkTaskDTO is CreateTaskDTO for bulk operations. An update keeps the
+// task's project and parent when they are 0, and its AutoComplete and
+// EstimateMinutes when nil, like UpdateTaskDTO.
+type BulkTaskDTO struct {
+	Title        string     `json:"title" validate:"required,max=200"`
+	Description  string     `json:"description" validate:"required,max=5000"`
+	Status       string     `json:"status" validate:"omitempty,oneof=Pending 'In Progress' Completed"`
+	Priority     string     `json:"priority" validate:"omitempty,oneof=Low Medium High"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	ProjectID    int        `json:"project_id,omitempty" validate:"min=0"`
+	ParentID     int        `json:"parent_id,omitempty" validate:"min=0"`
+	AutoComplete *bool      `json:"auto_complete,omitempty"`
+	// EstimateMinutes weights the task on the critical path of its
+	// dependency graph.
+	EstimateMinutes *int `json:"estimate_minutes,omitempty" validate:"omitempty,min=0,max=1000000"`
+}
+
+type BulkTaskResultDTO struct {
+	Index  int              `json:"index"`
+	Op     string           `json:"op"`
+	ID     int              `json:"id,omitempty"`
+	Status int              `json:"status"`
+	Task   *TaskResponseDTO `json:"task,omitempty"`
+	Error  *ErrorResponse   `json:"error,omitempty"`
+}
+
+type BulkTaskResponseDTO struct {
+	Mode      string              `json:"mode"`
+	Committed bool                `json:"committed"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []BulkTaskResultDTO `json:"results"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type CacheInvalidationResponse struct {
+	Message     string `json:"message"`
+	Tag         string `json:"tag"`
+	Invalidated int    `json:"invalidated"`
+}
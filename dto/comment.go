@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// CreateCommentDTO posts a comment, or a reply when ParentID names a
+// top-level comment on the same task. "@email" in Body mentions a user.
+type CreateCommentDTO struct {
+	Body     string `json:"body" validate:"required,max=10000"`
+	ParentID int    `json:"parent_id,omitempty" validate:"min=0"`
+}
+
+type UpdateCommentDTO struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+// CommentDTO is a comment with its replies. A deleted comment keeps its
+// place in the thread with an empty body.
+type CommentDTO struct {
+	ID          int          `json:"id"`
+	TaskID      int          `json:"task_id"`
+	AuthorID    int          `json:"author_id"`
+	AuthorEmail string       `json:"author_email"`
+	ParentID    int          `json:"parent_id,omitempty"`
+	Body        string       `json:"body"`
+	Mentions    []int        `json:"mentions,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	EditedAt    *time.Time   `json:"edited_at,omitempty"`
+	Deleted     bool         `json:"deleted,omitempty"`
+	Replies     []CommentDTO `json:"replies,omitempty"`
+}
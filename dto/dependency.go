@@ -0,0 +1,36 @@
+package dto
+
+// AddDependencyDTO marks the task in the path as blocked by BlockedByID.
+type AddDependencyDTO struct {
+	BlockedByID int `json:"blocked_by_id" validate:"required,min=1"`
+}
+
+// DependencyGraphDTO is the dependency DAG around a task: everything it
+// transitively depends on and everything that transitively depends on it.
+// Tasks the caller can't see are left out, along with their edges.
+type DependencyGraphDTO struct {
+	TaskID int                 `json:"task_id"`
+	Nodes  []DependencyNodeDTO `json:"nodes"`
+	Edges  []DependencyEdgeDTO `json:"edges"`
+	// Order lists every node after the nodes that block it.
+	Order []int `json:"order"`
+	// CriticalPath is the chain of dependencies with the most open work,
+	// first blocker first, going by each task's estimate. Completed tasks
+	// count as no work.
+	CriticalPath        []int `json:"critical_path"`
+	CriticalPathMinutes int   `json:"critical_path_minutes"`
+}
+
+type DependencyNodeDTO struct {
+	ID              int    `json:"id"`
+	Title           string `json:"title"`
+	Status          string `json:"status"`
+	EstimateMinutes int    `json:"estimate_minutes"`
+	Blocked         bool   `json:"blocked"`
+}
+
+// DependencyEdgeDTO reads "TaskID is blocked by BlockedByID".
+type DependencyEdgeDTO struct {
+	TaskID      int `json:"task_id"`
+	BlockedByID int `json:"blocked_by_id"`
+}
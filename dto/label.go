@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// LabelDTO creates or replaces a label. Color is "#rrggbb"; a neutral grey
+// is used when it is empty.
+type LabelDTO struct {
+	Name   string `json:"name" validate:"required,max=50"`
+	Color  string `json:"color" validate:"omitempty,len=7"`
+	Shared bool   `json:"shared"`
+}
+
+type LabelResponseDTO struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Shared    bool      `json:"shared"`
+	OwnerID   int       `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskLabelDTO is the compact form of a label embedded in a task.
+type TaskLabelDTO struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type AddTaskLabelsDTO struct {
+	LabelIDs []int `json:"label_ids" validate:"required,min=1,max=50"`
+}
+
+// MergeLabelDTO moves every task from the label in the path onto Into and
+// deletes the former.
+type MergeLabelDTO struct {
+	Into int `json:"into" validate:"required,min=1"`
+}
+
+type LabelMergeResponseDTO struct {
+	Label        LabelResponseDTO `json:"label"`
+	TasksUpdated int              `json:"tasks_updated"`
+}
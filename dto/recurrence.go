@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// SetRecurrenceDTO makes a task a recurring template. Rule is an RFC 5545
+// RRULE such as "FREQ=WEEKLY;BYDAY=MO". Timezone defaults to UTC, StartsAt
+// (the first occurrence) to the task's due date or else the current
+// minute, and Mode to "schedule".
+type SetRecurrenceDTO struct {
+	Rule     string     `json:"rule" validate:"required,max=500"`
+	Timezone string     `json:"timezone,omitempty" validate:"max=64"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	Mode     string     `json:"mode,omitempty" validate:"omitempty,oneof=schedule completion"`
+}
+
+// RecurrenceDTO describes a recurring task. Next is the next occurrence not
+// yet created, nil once the series has ended.
+type RecurrenceDTO struct {
+	TaskID     int        `json:"task_id"`
+	Rule       string     `json:"rule"`
+	Timezone   string     `json:"timezone"`
+	StartsAt   time.Time  `json:"starts_at"`
+	Mode       string     `json:"mode"`
+	Last       *time.Time `json:"last,omitempty"`
+	LastTaskID int        `json:"last_task_id,omitempty"`
+	Next       *time.Time `json:"next,omitempty"`
+}
+
+// OccurrencesDTO previews the next occurrences of a recurring task, in its
+// time zone.
+type OccurrencesDTO struct {
+	TaskID      int         `json:"task_id"`
+	Timezone    string      `json:"timezone"`
+	Occurrences []time.Time `json:"occurrences"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// CreateReminderDTO sets a reminder for the caller, either at RemindAt or
+// OffsetMinutes from the task's due date (negative for before it). Channel
+// defaults to "in_app".
+type CreateReminderDTO struct {
+	RemindAt      *time.Time `json:"remind_at,omitempty"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty" validate:"min=-525600,max=525600"`
+	Channel       string     `json:"channel,omitempty" validate:"omitempty,oneof=in_app webhook email"`
+}
+
+// ReminderDTO is a reminder and its delivery state. FireAt is nil for a
+// relative reminder while the task has no due date; DeliverAfter is set
+// while delivery waits for the end of quiet hours or a retry.
+type ReminderDTO struct {
+	ID            int        `json:"id"`
+	TaskID        int        `json:"task_id"`
+	RemindAt      *time.Time `json:"remind_at,omitempty"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty"`
+	Channel       string     `json:"channel"`
+	Status        string     `json:"status"`
+	FireAt        *time.Time `json:"fire_at,omitempty"`
+	DeliverAfter  *time.Time `json:"deliver_after,omitempty"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type NotificationDTO struct {
+	ID         int        `json:"id"`
+	TaskID     int        `json:"task_id"`
+	ReminderID int        `json:"reminder_id,omitempty"`
+	Message    string     `json:"message"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+}
+
+// NotificationSettingsDTO holds the caller's delivery preferences.
+// Timezone is an IANA name, UTC when empty. QuietStart and QuietEnd are
+// "HH:MM" in that zone and are given together or not at all. WebhookURL
+// receives reminders sent over the webhook channel.
+type NotificationSettingsDTO struct {
+	Timezone   string `json:"timezone" validate:"max=64"`
+	QuietStart string `json:"quiet_start,omitempty" validate:"omitempty,datetime=15:04"`
+	QuietEnd   string `json:"quiet_end,omitempty" validate:"omitempty,datetime=15:04"`
+	WebhookURL string `json:"webhook_url,omitempty" validate:"omitempty,url,max=2000"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// TaskSearchQueryDTO holds the query string parameters of GET /tasks/search.
+type TaskSearchQueryDTO struct {
+	Q      string `json:"q" validate:"required,max=500"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Offset int    `json:"offset" validate:"min=0"`
+}
+
+// TaskSearchResultDTO is one match. TitleHighlight and Snippet are HTML with
+// the matched words wrapped in <mark>; the rest of the text is escaped.
+type TaskSearchResultDTO struct {
+	Task           TaskResponseDTO `json:"task"`
+	Score          float64         `json:"score"`
+	TitleHighlight string          `json:"title_highlight"`
+	Snippet        string          `json:"snippet"`
+}
+
+type TaskSearchResponseDTO struct {
+	Query   string                `json:"query"`
+	Total   int                   `json:"total"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+	Results []TaskSearchResultDTO `json:"results"`
+}
@@ -1,23 +1,114 @@
 package dto
 
+import "time"
+
+// CreateTaskDTO describes a new task. A task with a ParentID is a subtask and
+// always lives in its parent's project.
 type CreateTaskDTO struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Priority    string `json:"priority"`
+	Title        string     `json:"title" validate:"required,max=200"`
+	Description  string     `json:"description" validate:"required,max=5000"`
+	Status       string     `json:"status" validate:"omitempty,oneof=Pending 'In Progress' Completed"`
+	Priority     string     `json:"priority" validate:"omitempty,oneof=Low Medium High"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	ProjectID    int        `json:"project_id,omitempty" validate:"min=0"`
+	ParentID     int        `json:"parent_id,omitempty" validate:"min=0"`
+	AutoComplete bool       `json:"auto_complete,omitempty"`
+	// EstimateMinutes weights the task on the critical path of its
+	// dependency graph.
+	EstimateMinutes int `json:"estimate_minutes,omitempty" validate:"min=0,max=1000000"`
 }
 
+// UpdateTaskDTO replaces a task's fields. ProjectID moves the task: nil keeps
+// its current project and 0 takes it out of any project. ParentID works the
+// same way for the parent task; AutoComplete and EstimateMinutes are kept
+// when nil. Completing a task is refused while it is blocked.
 type UpdateTaskDTO struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Priority    string `json:"priority"`
+	Title        string     `json:"title" validate:"required,max=200"`
+	Description  string     `json:"description" validate:"required,max=5000"`
+	Status       string     `json:"status" validate:"omitempty,oneof=Pending 'In Progress' Completed"`
+	Priority     string     `json:"priority" validate:"omitempty,oneof=Low Medium High"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	ProjectID    *int       `json:"project_id,omitempty" validate:"omitempty,min=0"`
+	ParentID     *int       `json:"parent_id,omitempty" validate:"omitempty,min=0"`
+	AutoComplete *bool      `json:"auto_complete,omitempty"`
+
+	EstimateMinutes *int `json:"estimate_minutes,omitempty" validate:"omitempty,min=0,max=1000000"`
+}
+
+// What DeleteTask does with the subtasks of the task being deleted.
+const (
+	DeleteSubtasksRestrict = "restrict" // refuse while the task has subtasks
+	DeleteSubtasksCascade  = "delete"   // delete them too, at every depth
+	DeleteSubtasksPromote  = "promote"  // move them up to the task's parent
+)
+
+type ProcessTasksDTO struct {
+	TaskIDs []int `json:"task_ids" validate:"required,min=1,max=100"`
 }
 
 type TaskResponseDTO struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Priority    string `json:"priority"`
+	ID          int               `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Priority    string            `json:"priority"`
+	Version     int               `json:"version"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	DueDate     *time.Time        `json:"due_date,omitempty"`
+	Labels      []TaskLabelDTO    `json:"labels,omitempty"`
+	ProjectID   int               `json:"project_id,omitempty"`
+	Assignees   []TaskAssigneeDTO `json:"assignees,omitempty"`
+
+	ParentID     int                `json:"parent_id,omitempty"`
+	AutoComplete bool               `json:"auto_complete,omitempty"`
+	Checklist    []ChecklistItemDTO `json:"checklist,omitempty"`
+	Subtasks     int                `json:"subtasks,omitempty"`
+	SubtasksDone int                `json:"subtasks_done,omitempty"`
+	// Progress is the percentage of direct subtasks and checklist items
+	// that are done; completed tasks are always at 100.
+	Progress int `json:"progress"`
+
+	EstimateMinutes int   `json:"estimate_minutes,omitempty"`
+	BlockedBy       []int `json:"blocked_by,omitempty"`
+	Blocks          []int `json:"blocks,omitempty"`
+	// Blocked is set while any task in BlockedBy is not completed.
+	Blocked bool `json:"blocked"`
+
+	Comments int `json:"comments"`
+
+	// OccurrenceOf is the recurring task this one was created from.
+	OccurrenceOf int `json:"occurrence_of,omitempty"`
+}
+
+type ChecklistItemDTO struct {
+	ID       int    `json:"id"`
+	Text     string `json:"text"`
+	Done     bool   `json:"done"`
+	Position int    `json:"position"`
+}
+
+// ChecklistItemRequestDTO adds a checklist item, or replaces one on PUT.
+type ChecklistItemRequestDTO struct {
+	Text string `json:"text" validate:"required,max=500"`
+	Done bool   `json:"done"`
+}
+
+type TaskAssigneeDTO struct {
+	UserID     int       `json:"user_id"`
+	Email      string    `json:"email"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+type AddAssigneesDTO struct {
+	UserIDs []int `json:"user_ids" validate:"required,min=1,max=20"`
+}
+
+// TaskFilterDTO holds the filters accepted by GET /tasks. Labels selects
+// tasks carrying any of the named labels, or all of them when LabelMatch is
+// "all". Assignee takes the values of the assignee: query field, such as me.
+type TaskFilterDTO struct {
+	Query      string   `json:"query" validate:"max=1000"`
+	Labels     []string `json:"labels" validate:"max=20"`
+	LabelMatch string   `json:"labels_match" validate:"omitempty,oneof=any all"`
+	Assignee   string   `json:"assignee" validate:"max=200"`
 }
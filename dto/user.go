@@ -3,8 +3,8 @@ package dto
 import "time"
 
 type RegisterUserDTO struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 }
 
 type LoginUserDTO struct {
@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// SavedViewDTO creates or replaces a saved view. Query uses the GET /tasks
+// ?query= syntax and Sort a list such as "priority,-due".
+type SavedViewDTO struct {
+	Name    string   `json:"name" validate:"required,max=100"`
+	Query   string   `json:"query" validate:"max=1000"`
+	Sort    string   `json:"sort" validate:"max=200"`
+	Columns []string `json:"columns" validate:"max=20"`
+	Shared  bool     `json:"shared"`
+}
+
+type SavedViewResponseDTO struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Query   string   `json:"query"`
+	Sort    string   `json:"sort"`
+	Columns []string `json:"columns"`
+	Shared  bool     `json:"shared"`
+	OwnerID int      `json:"owner_id"`
+	// Count is the number of tasks the view currently matches, for sidebar
+	// badges.
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SavedViewTasksResponseDTO struct {
+	View  SavedViewResponseDTO `json:"view"`
+	Tasks []TaskResponseDTO    `json:"tasks"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+// CreateWebhookDTO subscribes URL to Events. Without a Secret one is
+// generated; either way it is only returned by the create call.
+type CreateWebhookDTO struct {
+	URL    string   `json:"url" validate:"required,url,max=2000"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=200"`
+	Events []string `json:"events" validate:"required,max=20"`
+}
+
+// UpdateWebhookDTO replaces a webhook's URL and events. Setting Active to
+// true re-enables a disabled webhook and clears its failure count.
+type UpdateWebhookDTO struct {
+	URL    string   `json:"url" validate:"required,url,max=2000"`
+	Events []string `json:"events" validate:"required,max=20"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type WebhookDTO struct {
+	ID         int        `json:"id"`
+	URL        string     `json:"url"`
+	Events     []string   `json:"events"`
+	Active     bool       `json:"active"`
+	Failures   int        `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	Secret     string     `json:"secret,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveryDTO is one queued event and how sending it went.
+// ResponseCode is from the last attempt. Payload and Log, the attempts
+// made, are only filled in for a single delivery.
+type WebhookDeliveryDTO struct {
+	ID            int                 `json:"id"`
+	WebhookID     int                 `json:"webhook_id"`
+	Event         string              `json:"event"`
+	Status        string              `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	NextAttemptAt *time.Time          `json:"next_attempt_at,omitempty"`
+	ResponseCode  int                 `json:"response_code,omitempty"`
+	LastError     string              `json:"last_error,omitempty"`
+	RedeliveryOf  int                 `json:"redelivery_of,omitempty"`
+	DeliveredAt   *time.Time          `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Payload       interface{}         `json:"payload,omitempty"`
+	Log           []WebhookAttemptDTO `json:"log,omitempty"`
+}
+
+type WebhookAttemptDTO struct {
+	ResponseCode int       `json:"response_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMS   int       `json:"duration_ms"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+}
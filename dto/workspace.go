@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+type WorkspaceDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// WorkspaceResponseDTO describes a workspace; Role is the caller's role in it.
+type WorkspaceResponseDTO struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkspaceMemberDTO adds the user with Email to a workspace, or changes
+// their role if they are already a member.
+type WorkspaceMemberDTO struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=viewer member admin owner"`
+}
+
+type WorkspaceMemberResponseDTO struct {
+	UserID   int       `json:"user_id"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type ProjectDTO struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
+type ProjectResponseDTO struct {
+	ID          int        `json:"id"`
+	WorkspaceID int        `json:"workspace_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Archived    bool       `json:"archived"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
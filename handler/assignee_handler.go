@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+func RegisterAssigneeRoutes(mux Router, uc *usecase.AssigneeUsecase, tasks *usecase.TaskUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("POST /tasks/{id}/assignees", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		addAssignees(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/assignees/{userId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		removeAssignee(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /users/me/tasks", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getMyTasks(w, r, tasks)
+	}))
+}
+
+func addAssignees(w http.ResponseWriter, r *http.Request, uc *usecase.AssigneeUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.AddAssigneesDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	task, err := uc.AddAssignees(r.Context(), user.ID, taskID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func removeAssignee(w http.ResponseWriter, r *http.Request, uc *usecase.AssigneeUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	userID, err := pathID(r, "userId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.RemoveAssignee(r.Context(), user.ID, taskID, userID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getMyTasks(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
+	includeCompleted, err := queryBool(r, "completed")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	tasks, err := uc.AssignedTasks(r.Context(), includeCompleted)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tasks)
+}
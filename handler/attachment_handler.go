@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/usecase"
+	"time"
+)
+
+// attachmentTransferTimeout replaces the server's read or write timeout for
+// a single upload or download, which can take far longer than an API call.
+const attachmentTransferTimeout = 10 * time.Minute
+
+// multipartOverhead allows for the part headers and boundaries around the
+// file, which count against the request body limit.
+const multipartOverhead = 64 << 10
+
+// RegisterAttachmentRoutes lets anyone who can see a task list and download
+// its attachments; uploading and deleting need a signed-in user who can
+// edit the task.
+func RegisterAttachmentRoutes(mux Router, uc *usecase.AttachmentUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /tasks/{id}/attachments", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listAttachments(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /tasks/{id}/attachments", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		uploadAttachment(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /tasks/{id}/attachments/{attachmentId}", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		downloadAttachment(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/attachments/{attachmentId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteAttachment(w, r, uc)
+	}))
+}
+
+func listAttachments(w http.ResponseWriter, r *http.Request, uc *usecase.AttachmentUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	attachments, err := uc.ListAttachments(r.Context(), taskID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, attachments)
+}
+
+// uploadAttachment streams the multipart "file" part straight into the blob
+// store; the body is never buffered in memory or spooled to disk first.
+func uploadAttachment(w http.ResponseWriter, r *http.Request, uc *usecase.AttachmentUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	// The write deadline also runs from the end of the headers, so without
+	// extending it a slow upload would be stored but never answered.
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Now().Add(attachmentTransferTimeout))
+	rc.SetWriteDeadline(time.Now().Add(attachmentTransferTimeout))
+	r.Body = http.MaxBytesReader(w, r.Body, uc.MaxBytes()+multipartOverhead)
+
+	part, err := filePart(r)
+	if err != nil {
+		HandleError(w, uploadError(err, uc.MaxBytes()))
+		return
+	}
+	defer part.Close()
+
+	attachment, err := uc.Upload(r.Context(), user.ID, taskID, part.FileName(), part)
+	if err != nil {
+		HandleError(w, uploadError(err, uc.MaxBytes()))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, attachment)
+}
+
+// filePart skips to the form's "file" part. Any other fields are ignored.
+func filePart(r *http.Request) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, &domain.ValidationError{Field: "file", Message: "request must be multipart/form-data"}
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, &domain.ValidationError{Field: "file", Message: "a file part is required"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// uploadError reports a body over the limit as 413 and a malformed
+// multipart body as 400.
+func uploadError(err error, limit int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &domain.PayloadTooLargeError{Limit: limit}
+	}
+	if strings.HasPrefix(err.Error(), "multipart:") || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &domain.ValidationError{Field: "file", Message: "malformed multipart body"}
+	}
+	return err
+}
+
+// downloadAttachment serves the file with http.ServeContent, which answers
+// Range and If-Range requests and conditional GETs against the ETag.
+func downloadAttachment(w http.ResponseWriter, r *http.Request, uc *usecase.AttachmentUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	attachmentID, err := pathID(r, "attachmentId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	file, err := uc.Open(r.Context(), taskID, attachmentID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+	defer file.Content.Close()
+
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(attachmentTransferTimeout))
+
+	attachment := file.Attachment
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("ETag", `"`+attachment.Checksum+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=0, must-revalidate")
+
+	http.ServeContent(w, r, "", attachment.CreatedAt, file.Content)
+}
+
+func deleteAttachment(w http.ResponseWriter, r *http.Request, uc *usecase.AttachmentUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	attachmentID, err := pathID(r, "attachmentId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteAttachment(r.Context(), taskID, attachmentID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
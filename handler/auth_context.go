@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/domain"
+	"task-manager-api/middleware"
+	"task-manager-api/usecase"
+)
+
+// authenticated wraps a handler so it only runs for requests carrying a
+// valid bearer token, with the user stored in the request context.
+func authenticated(auth *usecase.AuthUsecase, h http.HandlerFunc) http.HandlerFunc {
+	return middleware.AuthMiddleware(auth)(h).ServeHTTP
+}
+
+// optionallyAuthenticated wraps a handler that serves anonymous requests too,
+// storing the user in the request context when a valid token is sent.
+func optionallyAuthenticated(auth *usecase.AuthUsecase, h http.HandlerFunc) http.HandlerFunc {
+	return middleware.OptionalAuthMiddleware(auth)(h).ServeHTTP
+}
+
+// currentUser returns the user set by AuthMiddleware.
+func currentUser(r *http.Request) (*domain.User, error) {
+	user, ok := domain.UserFromContext(r.Context())
+	if !ok {
+		return nil, &domain.UnauthorizedError{Message: "user not found in context"}
+	}
+	return user, nil
+}
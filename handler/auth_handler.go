@@ -3,12 +3,12 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
-	"task-manager-api/domain"
 	"task-manager-api/dto"
 	"task-manager-api/usecase"
+	"task-manager-api/validation"
 )
 
-func RegisterAuthRoutes(mux *http.ServeMux, uc *usecase.AuthUsecase) {
+func RegisterAuthRoutes(mux Router, uc *usecase.AuthUsecase) {
 	mux.HandleFunc("POST /auth/register", func(w http.ResponseWriter, r *http.Request) {
 		register(w, r, uc)
 	})
@@ -17,16 +17,16 @@ func RegisterAuthRoutes(mux *http.ServeMux, uc *usecase.AuthUsecase) {
 		login(w, r, uc)
 	})
 
-	mux.HandleFunc("GET /auth/me", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("GET /auth/me", authenticated(uc, func(w http.ResponseWriter, r *http.Request) {
 		me(w, r, uc)
-	})
+	}))
 }
 
 func register(w http.ResponseWriter, r *http.Request, uc *usecase.AuthUsecase) {
 	var req dto.RegisterUserDTO
 
 	// Parse request body
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err := validation.DecodeJSON(w, r, &req)
 	if err != nil {
 		HandleError(w, err)
 		return
@@ -56,7 +56,7 @@ func login(w http.ResponseWriter, r *http.Request, uc *usecase.AuthUsecase) {
 	var req dto.LoginUserDTO
 
 	// Parse request body
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err := validation.DecodeJSON(w, r, &req)
 	if err != nil {
 		HandleError(w, err)
 		return
@@ -86,11 +86,9 @@ func me(w http.ResponseWriter, r *http.Request, uc *usecase.AuthUsecase) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Get user from context (set by auth middleware)
-	user, ok := r.Context().Value("user").(*domain.User)
-	if !ok {
-		HandleError(w, &domain.UnauthorizedError{
-			Message: "user not found in context",
-		})
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
 		return
 	}
 
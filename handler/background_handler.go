@@ -3,13 +3,13 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
-	"task-manager-api/domain"
 	"task-manager-api/dto"
 	"task-manager-api/usecase"
+	"task-manager-api/validation"
 )
 
 // RegisterBackgroundRoutes registers background processing routes
-func RegisterBackgroundRoutes(mux *http.ServeMux, processor *usecase.TaskProcessor) {
+func RegisterBackgroundRoutes(mux Router, processor *usecase.TaskProcessor) {
 	mux.HandleFunc("POST /tasks/process", func(w http.ResponseWriter, r *http.Request) {
 		processTasksInBackground(w, r, processor)
 	})
@@ -17,23 +17,15 @@ func RegisterBackgroundRoutes(mux *http.ServeMux, processor *usecase.TaskProcess
 
 // processTasksInBackground processes multiple tasks in the background (fire and forget)
 func processTasksInBackground(w http.ResponseWriter, r *http.Request, processor *usecase.TaskProcessor) {
-	var req struct {
-		TaskIDs []int `json:"task_ids"`
-	}
+	var req dto.ProcessTasksDTO
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		HandleError(w, &domain.ValidationError{
-			Field:   "body",
-			Message: "invalid request body",
-		})
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
 		return
 	}
 
-	if len(req.TaskIDs) == 0 {
-		HandleError(w, &domain.ValidationError{
-			Field:   "task_ids",
-			Message: "task_ids cannot be empty",
-		})
+	if err := validation.Validate(req); err != nil {
+		HandleError(w, err)
 		return
 	}
 
@@ -7,16 +7,19 @@ import (
 	"task-manager-api/usecase"
 )
 
-func RegisterCacheRoutes(mux *http.ServeMux, cache *usecase.CacheService) {
+func RegisterCacheRoutes(mux Router, cache usecase.Cache) {
 	mux.HandleFunc("GET /cache/stats", func(w http.ResponseWriter, r *http.Request) {
 		getCacheStats(w, r, cache)
 	})
 	mux.HandleFunc("DELETE /cache", func(w http.ResponseWriter, r *http.Request) {
 		clearCache(w, r, cache)
 	})
+	mux.HandleFunc("DELETE /cache/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		invalidateCacheTag(w, r, cache)
+	})
 }
 
-func getCacheStats(w http.ResponseWriter, r *http.Request, cache *usecase.CacheService) {
+func getCacheStats(w http.ResponseWriter, r *http.Request, cache usecase.Cache) {
 	stats := cache.GetStats()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -24,7 +27,7 @@ func getCacheStats(w http.ResponseWriter, r *http.Request, cache *usecase.CacheS
 	json.NewEncoder(w).Encode(stats)
 }
 
-func clearCache(w http.ResponseWriter, r *http.Request, cache *usecase.CacheService) {
+func clearCache(w http.ResponseWriter, r *http.Request, cache usecase.Cache) {
 	cache.Clear()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -33,3 +36,16 @@ func clearCache(w http.ResponseWriter, r *http.Request, cache *usecase.CacheServ
 		Message: "Cache cleared successfully",
 	})
 }
+
+func invalidateCacheTag(w http.ResponseWriter, r *http.Request, cache usecase.Cache) {
+	tag := r.PathValue("tag")
+	invalidated := cache.InvalidateTag(tag)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.CacheInvalidationResponse{
+		Message:     "Cache tag invalidated successfully",
+		Tag:         tag,
+		Invalidated: invalidated,
+	})
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+// RegisterChecklistRoutes follows the task routes: anyone who may edit a
+// task may edit its checklist.
+func RegisterChecklistRoutes(mux Router, uc *usecase.ChecklistUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("POST /tasks/{id}/checklist", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		addChecklistItem(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /tasks/{id}/checklist/{itemId}", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		updateChecklistItem(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/checklist/{itemId}", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteChecklistItem(w, r, uc)
+	}))
+}
+
+func addChecklistItem(w http.ResponseWriter, r *http.Request, uc *usecase.ChecklistUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.ChecklistItemRequestDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	task, err := uc.AddItem(r.Context(), taskID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func updateChecklistItem(w http.ResponseWriter, r *http.Request, uc *usecase.ChecklistUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	itemID, err := pathID(r, "itemId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.ChecklistItemRequestDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	task, err := uc.UpdateItem(r.Context(), taskID, itemID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func deleteChecklistItem(w http.ResponseWriter, r *http.Request, uc *usecase.ChecklistUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	itemID, err := pathID(r, "itemId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	task, err := uc.DeleteItem(r.Context(), taskID, itemID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
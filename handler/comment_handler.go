@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+// RegisterCommentRoutes lets anyone who can see a task read its comments;
+// writing needs a signed-in author.
+func RegisterCommentRoutes(mux Router, uc *usecase.CommentUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /tasks/{id}/comments", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listComments(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /tasks/{id}/comments", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createComment(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /tasks/{id}/comments/{commentId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		updateComment(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/comments/{commentId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteComment(w, r, uc)
+	}))
+}
+
+func listComments(w http.ResponseWriter, r *http.Request, uc *usecase.CommentUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	comments, err := uc.ListComments(r.Context(), taskID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, comments)
+}
+
+func createComment(w http.ResponseWriter, r *http.Request, uc *usecase.CommentUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.CreateCommentDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	comment, err := uc.CreateComment(r.Context(), user.ID, taskID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+func updateComment(w http.ResponseWriter, r *http.Request, uc *usecase.CommentUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	commentID, err := pathID(r, "commentId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.UpdateCommentDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	comment, err := uc.UpdateComment(r.Context(), user.ID, taskID, commentID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, comment)
+}
+
+func deleteComment(w http.ResponseWriter, r *http.Request, uc *usecase.CommentUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	commentID, err := pathID(r, "commentId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteComment(r.Context(), user.ID, taskID, commentID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
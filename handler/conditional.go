@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// writeNotModified sets the caching validators for a response and, when the
+// request's If-None-Match or If-Modified-Since shows the client already has
+// this representation, writes 304 Not Modified and reports true.
+//
+// Clients may keep the body but must revalidate before reusing it.
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if !isNotModified(r, etag, lastModified) {
+		return false
+	}
+
+	// A 304 carries the validators but no body or body headers.
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// isNotModified follows RFC 9110: If-None-Match takes precedence, and
+// If-Modified-Since is only consulted when it is absent.
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagListMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// HTTP dates have one-second resolution.
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagListMatches applies the weak comparison used for If-None-Match.
+func etagListMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+// RegisterDependencyRoutes follows the task routes: anyone who may edit a
+// task may change what blocks it.
+func RegisterDependencyRoutes(mux Router, uc *usecase.DependencyUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("POST /tasks/{id}/dependencies", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		addDependency(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/dependencies/{blockedById}", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		removeDependency(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /tasks/{id}/graph", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getDependencyGraph(w, r, uc)
+	}))
+}
+
+func addDependency(w http.ResponseWriter, r *http.Request, uc *usecase.DependencyUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.AddDependencyDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	task, err := uc.AddDependency(r.Context(), taskID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func removeDependency(w http.ResponseWriter, r *http.Request, uc *usecase.DependencyUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	blockedByID, err := pathID(r, "blockedById")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.RemoveDependency(r.Context(), taskID, blockedByID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getDependencyGraph(w http.ResponseWriter, r *http.Request, uc *usecase.DependencyUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	graph, err := uc.Graph(r.Context(), taskID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, graph)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+	openAPIErr  error
+)
+
+func RegisterDocsRoutes(mux Router) {
+	mux.HandleFunc("GET /openapi.json", getOpenAPISpec)
+	mux.HandleFunc("GET /docs", getDocs)
+}
+
+func getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	// The document only depends on apiOperations, so build it once.
+	openAPIOnce.Do(func() {
+		openAPIJSON, openAPIErr = json.MarshalIndent(BuildOpenAPIDocument(), "", "  ")
+	})
+	if openAPIErr != nil {
+		HandleError(w, openAPIErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPIJSON)
+}
+
+func getDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docsPage)
+}
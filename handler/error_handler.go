@@ -8,68 +8,99 @@ import (
 )
 
 func HandleError(w http.ResponseWriter, err error) {
+	status, response := errorResponse(err)
+
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
 
+// errorResponse maps a domain error to its HTTP status and response body.
+func errorResponse(err error) (int, dto.ErrorResponse) {
 	switch e := err.(type) {
 	case *domain.ValidationError:
 		// 400 Bad Request
-		response := dto.ErrorResponse{
+		return http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "ValidationError",
 			Message: e.Error(),
 			Details: map[string]string{"field": e.Field},
 		}
 
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(response)
+	case *domain.ValidationErrors:
+		// 400 Bad Request - one detail entry per invalid field
+		details := make(map[string]string, len(e.Errors))
+		for _, fieldErr := range e.Errors {
+			details[fieldErr.Field] = fieldErr.Message
+		}
+
+		return http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "ValidationError",
+			Message: e.Error(),
+			Details: details,
+		}
+
+	case *domain.PayloadTooLargeError:
+		// 413 Request Entity Too Large
+		return http.StatusRequestEntityTooLarge, dto.ErrorResponse{
+			Error:   "PayloadTooLarge",
+			Message: e.Error(),
+		}
+
+	case *domain.TooManyRequestsError:
+		// 429 Too Many Requests
+		return http.StatusTooManyRequests, dto.ErrorResponse{
+			Error:   "TooManyRequests",
+			Message: e.Error(),
+		}
+
 	case *domain.NotFoundError:
 		// 404 Not Found
-		response := dto.ErrorResponse{
+		return http.StatusNotFound, dto.ErrorResponse{
 			Error:   "NotFound",
 			Message: e.Error(),
 		}
 
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(response)
+	case *domain.BatchAbortedError:
+		// 424 Failed Dependency - rolled back with its atomic batch
+		return http.StatusFailedDependency, dto.ErrorResponse{
+			Error:   "BatchAborted",
+			Message: e.Error(),
+		}
 
 	case *domain.DatabaseError:
 		// 500 Internal Server Error
-		response := dto.ErrorResponse{
+		return http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "DatabaseError",
 			Message: e.Error(),
 		}
 
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
-
 	case *domain.AuthenticationError:
 		// 401 Unauthorized - Login failed
-		response := dto.ErrorResponse{
+		return http.StatusUnauthorized, dto.ErrorResponse{
 			Error:   "AuthenticationError",
 			Message: e.Error(),
 		}
 
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(response)
-
 	case *domain.UnauthorizedError:
 		// 401 Unauthorized - Invalid/missing token
-		response := dto.ErrorResponse{
+		return http.StatusUnauthorized, dto.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: e.Error(),
 		}
 
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(response)
+	case *domain.ForbiddenError:
+		// 403 Forbidden - authenticated but not allowed
+		return http.StatusForbidden, dto.ErrorResponse{
+			Error:   "Forbidden",
+			Message: e.Error(),
+		}
 
 	default:
 		// 500 Internal Server Error for unknown errors
-		response := dto.ErrorResponse{
+		return http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "InternalServerError",
 			Message: "An unexpected error occurred",
 		}
-
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
 	}
 }
 
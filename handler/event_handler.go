@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"task-manager-api/usecase"
+	"time"
+)
+
+const (
+	eventHeartbeat = 15 * time.Second
+	// eventWriteTimeout bounds each write to an event stream. It replaces
+	// the server's WriteTimeout, which would end every stream after its
+	// first few seconds.
+	eventWriteTimeout = 10 * time.Second
+)
+
+// RegisterEventRoutes serves GET /events, a Server-Sent Events stream of
+// the task changes the signed-in user can see.
+func RegisterEventRoutes(mux Router, hub *usecase.EventHub, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /events", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		streamEvents(w, r, hub)
+	}))
+}
+
+// streamEvents sends each task event as an SSE message whose id is the
+// event ID, whose event is the type (task.created, ...) and whose data is
+// the event as JSON. A client reconnecting with Last-Event-ID first gets the
+// events it missed, or a "reset" message when they are no longer available
+// and it should reload.
+func streamEvents(w http.ResponseWriter, r *http.Request, hub *usecase.EventHub) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	stream, missed, complete, err := hub.Subscribe(user.ID, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+	defer stream.Close()
+
+	rc := http.NewResponseController(w)
+	// The server's ReadTimeout would cancel the request's context once it
+	// passes; the stream reads nothing more after the headers.
+	rc.SetReadDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(message string) bool {
+		rc.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+		if _, err := fmt.Fprint(w, message); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	sendEvent := func(event usecase.StreamEvent) bool {
+		if !stream.Visible(r.Context(), event) {
+			return true
+		}
+		return send(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data))
+	}
+
+	if !send("retry: 3000\n\n") {
+		return
+	}
+	if !complete && !send("event: reset\ndata: {}\n\n") {
+		return
+	}
+	for _, event := range missed {
+		if !sendEvent(event) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-stream.Done():
+			return
+		case event := <-stream.Events():
+			if !sendEvent(event) {
+				return
+			}
+		case <-heartbeat.C:
+			if !send(": heartbeat\n\n") {
+				return
+			}
+		}
+	}
+}
@@ -13,13 +13,13 @@ type HealthResponse struct {
 	Cache    string `json:"cache"`
 }
 
-func RegisterHealthRoutes(mux *http.ServeMux, repo repository.TaskRepository, cache *usecase.CacheService) {
+func RegisterHealthRoutes(mux Router, repo repository.TaskRepository, cache usecase.Cache) {
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		getHealth(w, r, repo, cache)
 	})
 }
 
-func getHealth(w http.ResponseWriter, r *http.Request, repo repository.TaskRepository, cache *usecase.CacheService) {
+func getHealth(w http.ResponseWriter, r *http.Request, repo repository.TaskRepository, cache usecase.Cache) {
 	_, dbErr := repo.GetAll()
 
 	var dbStatus string
@@ -34,16 +34,22 @@ func getHealth(w http.ResponseWriter, r *http.Request, repo repository.TaskRepos
 	_, found := cache.Get("health_check")
 
 	var cacheStatus string
-	if !found {
-		cacheStatus = "error"
-	} else {
+	if found {
 		cacheStatus = "connected"
+	} else if !cache.GetStats().Available {
+		// A shared cache backend being down only makes the API slower, since
+		// requests fall through to the database.
+		cacheStatus = "unavailable"
+	} else {
+		cacheStatus = "error"
 	}
 
 	overallStatus := "healthy"
 
 	if dbStatus == "error" || cacheStatus == "error" {
 		overallStatus = "unhealthy"
+	} else if cacheStatus == "unavailable" {
+		overallStatus = "degraded"
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+func RegisterLabelRoutes(mux Router, uc *usecase.LabelUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /labels", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listLabels(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /labels", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createLabel(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /labels/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		updateLabel(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /labels/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteLabel(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /labels/{id}/merge", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		mergeLabel(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /tasks/{id}/labels", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		addTaskLabels(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/labels/{labelId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		removeTaskLabel(w, r, uc)
+	}))
+}
+
+func listLabels(w http.ResponseWriter, r *http.Request, uc *usecase.LabelUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	labels, err := uc.ListLabels(r.Context(), user.ID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, labels)
+}
+
+func createLabel(w http.ResponseWriter, r *http.Request, uc *usecase.LabelUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.LabelDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	label, err := uc.CreateLabel(r.Context(), user.ID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, label)
+}
+
+func updateLabel(w http.ResponseWriter, r *http.Request, uc *usecase.LabelUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.LabelDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	label, err := uc.UpdateLabel(r.Context(), user.ID, id, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, label)
+}
+
+func deleteLabel(w http.ResponseWriter, r *http.Request, uc *usecase.LabelUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteLabel(r.Context(), user.ID, id); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mergeLabel(w http.ResponseWriter, r *http.Request, uc *usecase.LabelUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.MergeLabelDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	result, err := uc.MergeLabel(r.Context(), user.ID, id, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func addTaskLabels(w http.ResponseWriter, r *http.Request, uc *usecase.LabelUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.AddTaskLabelsDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	task, err := uc.AddTaskLabels(r.Context(), user.ID, taskID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func removeTaskLabel(w http.ResponseWriter, r *http.Request, uc *usecase.LabelUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	labelID, err := pathID(r, "labelId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.RemoveTaskLabel(r.Context(), user.ID, taskID, labelID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,879 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"task-manager-api/dto"
+	"task-manager-api/openapi"
+	"task-manager-api/usecase"
+)
+
+// apiOperation documents one route relative to its API version prefix. Every
+// pattern registered in registerRoutes must have a matching entry;
+// openapi_test.go enforces it.
+type apiOperation struct {
+	Method    string
+	Path      string
+	Summary   string
+	Tag       string
+	Request   interface{} // zero value of the request body DTO, if any
+	Response  interface{} // zero value of the success body, if any
+	Status    int         // success status code
+	AltStatus []int       // other success codes returning the Response body
+	Errors    []int       // error status codes rendered as dto.ErrorResponse
+	Query     []apiParam
+	Auth      bool
+	// OptionalAuth marks routes that serve anonymous callers too but show
+	// signed-in callers their workspaces' project tasks.
+	OptionalAuth bool
+	// Conditional marks GETs that send ETag/Last-Modified and answer
+	// If-None-Match/If-Modified-Since with 304.
+	Conditional bool
+	// Upload marks requests sent as multipart/form-data with a "file" part
+	// instead of a JSON Request body.
+	Upload bool
+	// Download marks responses that are the raw file rather than JSON, with
+	// Range requests answered by 206.
+	Download bool
+	// Stream marks Server-Sent Events responses, resumed with Last-Event-ID.
+	Stream bool
+}
+
+type apiParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+var v1Operations = []apiOperation{
+	{
+		Method: http.MethodGet, Path: "/tasks", Tag: "Tasks",
+		Summary:  "List all tasks, optionally filtered",
+		Response: []dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest},
+		Query: []apiParam{
+			{Name: "query", Type: "string", Description: `Filter such as status:in_progress priority:high due<2026-11-01 label:backend assignee:me -status:completed "login bug"`},
+			{Name: "labels", Type: "string", Description: "Comma-separated label names"},
+			{Name: "labels_match", Type: "string", Description: "any (default) or all of labels"},
+			{Name: "assignee", Type: "string", Description: "Comma-separated user IDs, me or none"},
+		},
+		Conditional:  true,
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks", Tag: "Tasks",
+		Summary: "Create a task, optionally in a project",
+		Request: dto.CreateTaskDTO{}, Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/bulk", Tag: "Tasks",
+		Summary: "Apply create/update/delete operations in one transaction",
+		Request: dto.BulkTaskRequestDTO{}, Response: dto.BulkTaskResponseDTO{}, Status: http.StatusOK,
+		AltStatus:    []int{http.StatusMultiStatus},
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusRequestEntityTooLarge},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/search", Tag: "Tasks",
+		Summary:  "Full-text search over task titles and descriptions",
+		Response: dto.TaskSearchResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest},
+		Query: []apiParam{
+			{Name: "q", Type: "string", Description: `Search query: words, "phrases", prefix*, AND/OR/NOT, parentheses and title:/description: scoping`},
+			{Name: "limit", Type: "integer", Description: "Results per page, 1-100 (default 20)"},
+			{Name: "offset", Type: "integer", Description: "Results to skip (default 0)"},
+		},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}", Tag: "Tasks",
+		Summary:  "Get a task by ID",
+		Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPut, Path: "/tasks/{id}", Tag: "Tasks",
+		Summary: "Update a task",
+		Request: dto.UpdateTaskDTO{}, Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}", Tag: "Tasks",
+		Summary: "Delete a task",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Query: []apiParam{
+			{Name: "cascade", Type: "string", Description: "What to do with subtasks: restrict (default, refuse while there are any), delete or promote"},
+		},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/subtasks", Tag: "Tasks",
+		Summary:  "List a task's direct subtasks",
+		Response: []dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/{id}/checklist", Tag: "Tasks",
+		Summary: "Add a checklist item to a task",
+		Request: dto.ChecklistItemRequestDTO{}, Response: dto.TaskResponseDTO{}, Status: http.StatusCreated,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPut, Path: "/tasks/{id}/checklist/{itemId}", Tag: "Tasks",
+		Summary: "Replace a checklist item's text and done flag",
+		Request: dto.ChecklistItemRequestDTO{}, Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/checklist/{itemId}", Tag: "Tasks",
+		Summary:  "Remove a checklist item",
+		Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/{id}/dependencies", Tag: "Dependencies",
+		Summary: "Mark a task as blocked by another; refused if it would create a cycle",
+		Request: dto.AddDependencyDTO{}, Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/dependencies/{blockedById}", Tag: "Dependencies",
+		Summary:      "Remove a dependency",
+		Status:       http.StatusNoContent,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/graph", Tag: "Dependencies",
+		Summary:  "Get the dependency graph around a task, with a topological order and critical path",
+		Response: dto.DependencyGraphDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/comments", Tag: "Comments",
+		Summary:  "List a task's comments as threads, oldest first",
+		Response: []dto.CommentDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/{id}/comments", Tag: "Comments",
+		Summary: "Comment on a task or reply to a comment; @email mentions a user",
+		Request: dto.CreateCommentDTO{}, Response: dto.CommentDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPut, Path: "/tasks/{id}/comments/{commentId}", Tag: "Comments",
+		Summary: "Edit a comment (author or workspace admin)",
+		Request: dto.UpdateCommentDTO{}, Response: dto.CommentDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/comments/{commentId}", Tag: "Comments",
+		Summary: "Delete a comment (author or workspace admin)",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/recurrence", Tag: "Recurrence",
+		Summary:  "Get how a task recurs",
+		Response: dto.RecurrenceDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPut, Path: "/tasks/{id}/recurrence", Tag: "Recurrence",
+		Summary: "Make a task recur on an RRULE schedule, creating a copy per occurrence",
+		Request: dto.SetRecurrenceDTO{}, Response: dto.RecurrenceDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/recurrence", Tag: "Recurrence",
+		Summary:      "Stop a task recurring; occurrences already created are kept",
+		Status:       http.StatusNoContent,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/recurrence/occurrences", Tag: "Recurrence",
+		Summary:  "Preview the next occurrences not yet created",
+		Response: dto.OccurrencesDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Query: []apiParam{
+			{Name: "count", Type: "integer", Description: "How many occurrences, 1-100 (default 10)"},
+		},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/reminders", Tag: "Reminders",
+		Summary:  "List your reminders on a task",
+		Response: []dto.ReminderDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/{id}/reminders", Tag: "Reminders",
+		Summary: "Remind yourself about a task at a time or relative to its due date",
+		Request: dto.CreateReminderDTO{}, Response: dto.ReminderDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/reminders/{reminderId}", Tag: "Reminders",
+		Summary: "Delete one of your reminders",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodGet, Path: "/users/me/notifications", Tag: "Reminders",
+		Summary:  "List your in-app notifications, newest first",
+		Response: []dto.NotificationDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		Query: []apiParam{
+			{Name: "unread", Type: "boolean", Description: "Only unread notifications (default false)"},
+		},
+		Auth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/users/me/notifications/{notificationId}/read", Tag: "Reminders",
+		Summary:  "Mark a notification read",
+		Response: dto.NotificationDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/users/me/notification-settings", Tag: "Reminders",
+		Summary:  "Get your time zone, quiet hours and webhook URL",
+		Response: dto.NotificationSettingsDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPut, Path: "/users/me/notification-settings", Tag: "Reminders",
+		Summary: "Set your time zone, quiet hours and webhook URL",
+		Request: dto.NotificationSettingsDTO{}, Response: dto.NotificationSettingsDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/events", Tag: "Events",
+		Summary: "Stream the task changes you can see as Server-Sent Events",
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusUnauthorized, http.StatusTooManyRequests},
+		Auth:    true,
+		Stream:  true,
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks", Tag: "Webhooks",
+		Summary:  "List your webhooks",
+		Response: []dto.WebhookDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/webhooks", Tag: "Webhooks",
+		Summary: "Subscribe a URL to task events; the response is the only one that includes the signing secret",
+		Request: dto.CreateWebhookDTO{}, Response: dto.WebhookDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/{id}", Tag: "Webhooks",
+		Summary:  "Get one of your webhooks",
+		Response: dto.WebhookDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPut, Path: "/webhooks/{id}", Tag: "Webhooks",
+		Summary: "Change a webhook's URL and events, or re-enable it",
+		Request: dto.UpdateWebhookDTO{}, Response: dto.WebhookDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/webhooks/{id}", Tag: "Webhooks",
+		Summary: "Delete a webhook and its queued deliveries",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/{id}/deliveries", Tag: "Webhooks",
+		Summary:  "List a webhook's recent deliveries, newest first",
+		Response: []dto.WebhookDeliveryDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Query: []apiParam{
+			{Name: "limit", Type: "integer", Description: "How many deliveries, 1 to 100 (default 20)"},
+		},
+		Auth: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/{id}/deliveries/{deliveryId}", Tag: "Webhooks",
+		Summary:  "Get a delivery with its payload and every attempt made",
+		Response: dto.WebhookDeliveryDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/webhooks/{id}/deliveries/{deliveryId}/redeliver", Tag: "Webhooks",
+		Summary:  "Queue a delivery's payload again",
+		Response: dto.WebhookDeliveryDTO{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/attachments", Tag: "Attachments",
+		Summary:  "List a task's attachments",
+		Response: []dto.AttachmentDTO{}, Status: http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		OptionalAuth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/{id}/attachments", Tag: "Attachments",
+		Summary:  "Upload a file to a task; identical files are stored once",
+		Response: dto.AttachmentDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge},
+		Auth:   true,
+		Upload: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}/attachments/{attachmentId}", Tag: "Attachments",
+		Summary:      "Download an attachment, whole or by byte range",
+		Status:       http.StatusOK,
+		Errors:       []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		OptionalAuth: true,
+		Download:     true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/attachments/{attachmentId}", Tag: "Attachments",
+		Summary: "Delete an attachment",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/process", Tag: "Tasks",
+		Summary: "Queue tasks for background processing",
+		Request: dto.ProcessTasksDTO{}, Response: dto.SuccessResponse{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodPost, Path: "/auth/register", Tag: "Auth",
+		Summary: "Register a new user",
+		Request: dto.RegisterUserDTO{}, Response: dto.UserResponseDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodPost, Path: "/auth/login", Tag: "Auth",
+		Summary: "Exchange credentials for a JWT",
+		Request: dto.LoginUserDTO{}, Response: dto.LoginResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+	},
+	{
+		Method: http.MethodGet, Path: "/auth/me", Tag: "Auth",
+		Summary:  "Get the authenticated user",
+		Response: dto.UserResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/cache/stats", Tag: "Cache",
+		Summary:  "Get cache statistics",
+		Response: usecase.CacheStats{}, Status: http.StatusOK,
+	},
+	{
+		Method: http.MethodDelete, Path: "/cache", Tag: "Cache",
+		Summary:  "Clear the cache",
+		Response: dto.SuccessResponse{}, Status: http.StatusOK,
+	},
+	{
+		Method: http.MethodDelete, Path: "/cache/tags/{tag}", Tag: "Cache",
+		Summary:  "Invalidate every cache entry with a tag, such as tasks or task:42",
+		Response: dto.CacheInvalidationResponse{}, Status: http.StatusOK,
+	},
+	{
+		Method: http.MethodGet, Path: "/views", Tag: "Views",
+		Summary:  "List your saved views and those shared by others, with task counts",
+		Response: []dto.SavedViewResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/views", Tag: "Views",
+		Summary: "Save a view",
+		Request: dto.SavedViewDTO{}, Response: dto.SavedViewResponseDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/views/{id}", Tag: "Views",
+		Summary:  "Get a saved view",
+		Response: dto.SavedViewResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPut, Path: "/views/{id}", Tag: "Views",
+		Summary: "Replace a saved view you own",
+		Request: dto.SavedViewDTO{}, Response: dto.SavedViewResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/views/{id}", Tag: "Views",
+		Summary: "Delete a saved view you own",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodGet, Path: "/views/{id}/tasks", Tag: "Views",
+		Summary:  "Run a saved view",
+		Response: dto.SavedViewTasksResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/labels", Tag: "Labels",
+		Summary:  "List your labels and those shared by others",
+		Response: []dto.LabelResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/labels", Tag: "Labels",
+		Summary: "Create a label",
+		Request: dto.LabelDTO{}, Response: dto.LabelResponseDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPut, Path: "/labels/{id}", Tag: "Labels",
+		Summary: "Rename or recolor a label you own",
+		Request: dto.LabelDTO{}, Response: dto.LabelResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/labels/{id}", Tag: "Labels",
+		Summary: "Delete a label you own and remove it from every task",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodPost, Path: "/labels/{id}/merge", Tag: "Labels",
+		Summary: "Move every task onto another label and delete this one",
+		Request: dto.MergeLabelDTO{}, Response: dto.LabelMergeResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/{id}/labels", Tag: "Labels",
+		Summary: "Add labels to a task",
+		Request: dto.AddTaskLabelsDTO{}, Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/labels/{labelId}", Tag: "Labels",
+		Summary: "Remove a label from a task",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodGet, Path: "/workspaces", Tag: "Workspaces",
+		Summary:  "List the workspaces you belong to, with your role in each",
+		Response: []dto.WorkspaceResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/workspaces", Tag: "Workspaces",
+		Summary: "Create a workspace you own",
+		Request: dto.WorkspaceDTO{}, Response: dto.WorkspaceResponseDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/workspaces/{id}/members", Tag: "Workspaces",
+		Summary:  "List a workspace's members",
+		Response: []dto.WorkspaceMemberResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/workspaces/{id}/members", Tag: "Workspaces",
+		Summary: "Add a member or change their role (admins; owners for admin and owner roles)",
+		Request: dto.WorkspaceMemberDTO{}, Response: dto.WorkspaceMemberResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/workspaces/{id}/members/{userId}", Tag: "Workspaces",
+		Summary: "Remove a member, or leave the workspace",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodGet, Path: "/workspaces/{id}/projects", Tag: "Projects",
+		Summary:  "List a workspace's projects",
+		Response: []dto.ProjectResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Query: []apiParam{
+			{Name: "archived", Type: "boolean", Description: "Include archived projects (default false)"},
+		},
+		Auth: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/workspaces/{id}/projects", Tag: "Projects",
+		Summary: "Create a project (admins)",
+		Request: dto.ProjectDTO{}, Response: dto.ProjectResponseDTO{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/projects/{id}", Tag: "Projects",
+		Summary:  "Get a project",
+		Response: dto.ProjectResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPut, Path: "/projects/{id}", Tag: "Projects",
+		Summary: "Rename or describe a project (admins)",
+		Request: dto.ProjectDTO{}, Response: dto.ProjectResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/projects/{id}", Tag: "Projects",
+		Summary: "Delete a project without tasks (admins)",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodPost, Path: "/projects/{id}/archive", Tag: "Projects",
+		Summary:  "Archive a project, making its tasks read-only (admins)",
+		Response: dto.ProjectResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/projects/{id}/archive", Tag: "Projects",
+		Summary:  "Unarchive a project (admins)",
+		Response: dto.ProjectResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/projects/{id}/tasks", Tag: "Projects",
+		Summary:  "List a project's tasks",
+		Response: []dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks/{id}/assignees", Tag: "Assignees",
+		Summary: "Assign users to a task",
+		Request: dto.AddAssigneesDTO{}, Response: dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:   true,
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}/assignees/{userId}", Tag: "Assignees",
+		Summary: "Unassign a user from a task",
+		Status:  http.StatusNoContent,
+		Errors:  []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		Auth:    true,
+	},
+	{
+		Method: http.MethodGet, Path: "/users/me/tasks", Tag: "Assignees",
+		Summary:  "List the open tasks assigned to you, soonest due first",
+		Response: []dto.TaskResponseDTO{}, Status: http.StatusOK,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		Query: []apiParam{
+			{Name: "completed", Type: "boolean", Description: "Include completed tasks (default false)"},
+		},
+		Auth: true,
+	},
+}
+
+// rootOperations are served outside of any API version.
+var rootOperations = []apiOperation{
+	{
+		Method: http.MethodGet, Path: "/health", Tag: "Operations",
+		Summary:  "Report database and cache health",
+		Response: HealthResponse{}, Status: http.StatusOK,
+		Errors: []int{http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/openapi.json", Tag: "Operations",
+		Summary: "This OpenAPI document",
+		Status:  http.StatusOK,
+	},
+	{
+		Method: http.MethodGet, Path: "/docs", Tag: "Operations",
+		Summary: "Interactive API documentation",
+		Status:  http.StatusOK,
+	},
+}
+
+// BuildOpenAPIDocument assembles the OpenAPI 3.1 document from the operations
+// of every API version, deriving request and response schemas from the dto
+// structs. Unversioned legacy aliases are listed as deprecated.
+func BuildOpenAPIDocument() *openapi.Document {
+	registry := openapi.NewSchemaRegistry()
+	errorSchema := registry.SchemaFor(dto.ErrorResponse{})
+
+	doc := &openapi.Document{
+		OpenAPI: "3.1.0",
+		Info: openapi.Info{
+			Title:       "Task Manager API",
+			Version:     "1.0.0",
+			Description: "Task management with JWT authentication, caching and background processing.",
+		},
+		Paths: make(map[string]*openapi.PathItem),
+	}
+
+	seenTags := make(map[string]bool)
+	add := func(op apiOperation, prefix string, deprecated bool) {
+		if !seenTags[op.Tag] {
+			seenTags[op.Tag] = true
+			doc.Tags = append(doc.Tags, openapi.Tag{Name: op.Tag})
+		}
+
+		path := prefix + op.Path
+		item, exists := doc.Paths[path]
+		if !exists {
+			item = &openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+
+		operation := buildOperation(op, path, registry, errorSchema)
+		if deprecated {
+			operation.Deprecated = true
+			operation.Description = fmt.Sprintf("Deprecated alias of %s%s. Responses carry Deprecation and Sunset (%s) headers.",
+				legacyVersion.Prefix, op.Path, legacySunsetAt.Format("2006-01-02"))
+		}
+		item.SetMethod(op.Method, operation)
+	}
+
+	for _, version := range apiVersions {
+		for _, op := range version.Operations {
+			add(op, version.Prefix, false)
+		}
+	}
+	for _, op := range legacyVersion.Operations {
+		add(op, "", true)
+	}
+	for _, op := range rootOperations {
+		add(op, "", false)
+	}
+
+	doc.Components = openapi.Components{
+		Schemas: registry.Schemas(),
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}
+
+	return doc
+}
+
+func buildOperation(op apiOperation, path string, registry *openapi.SchemaRegistry, errorSchema *openapi.Schema) *openapi.Operation {
+	operation := &openapi.Operation{
+		OperationID: operationID(op.Method, path),
+		Summary:     op.Summary,
+		Tags:        []string{op.Tag},
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	for _, name := range pathParams(op.Path) {
+		operation.Parameters = append(operation.Parameters, openapi.Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &openapi.Schema{Type: pathParamType(name)},
+		})
+	}
+	for _, param := range op.Query {
+		operation.Parameters = append(operation.Parameters, openapi.Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Schema:      &openapi.Schema{Type: param.Type},
+		})
+	}
+
+	if op.Request != nil {
+		operation.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content: map[string]*openapi.MediaType{
+				"application/json": {Schema: registry.SchemaFor(op.Request)},
+			},
+		}
+	}
+
+	if op.Upload {
+		operation.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content: map[string]*openapi.MediaType{
+				"multipart/form-data": {Schema: &openapi.Schema{
+					Type:       "object",
+					Properties: map[string]*openapi.Schema{"file": binarySchema},
+					Required:   []string{"file"},
+				}},
+			},
+		}
+	}
+
+	success := &openapi.Response{Description: http.StatusText(op.Status)}
+	if op.Response != nil {
+		success.Content = map[string]*openapi.MediaType{
+			"application/json": {Schema: registry.SchemaFor(op.Response)},
+		}
+	}
+	operation.Responses[strconv.Itoa(op.Status)] = success
+
+	if op.Download {
+		operation.Parameters = append(operation.Parameters, openapi.Parameter{
+			Name:   "Range",
+			In:     "header",
+			Schema: &openapi.Schema{Type: "string"},
+		})
+		success.Content = map[string]*openapi.MediaType{"application/octet-stream": {Schema: binarySchema}}
+		success.Headers = map[string]*openapi.Header{
+			"Content-Disposition": {Description: "attachment, with the original file name", Schema: &openapi.Schema{Type: "string"}},
+			"ETag":                {Description: "SHA-256 of the content", Schema: &openapi.Schema{Type: "string"}},
+		}
+		operation.Responses[strconv.Itoa(http.StatusPartialContent)] = &openapi.Response{
+			Description: "The requested byte range",
+			Headers:     success.Headers,
+			Content:     success.Content,
+		}
+		operation.Responses[strconv.Itoa(http.StatusRequestedRangeNotSatisfiable)] = &openapi.Response{
+			Description: http.StatusText(http.StatusRequestedRangeNotSatisfiable),
+		}
+	}
+
+	if op.Stream {
+		operation.Parameters = append(operation.Parameters, openapi.Parameter{
+			Name:   "Last-Event-ID",
+			In:     "header",
+			Schema: &openapi.Schema{Type: "string"},
+		})
+		success.Content = map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{
+			Type:        "string",
+			Description: "One message per event: id, event (the event type) and data (the event as JSON)",
+		}}}
+	}
+
+	if op.Conditional {
+		for _, name := range []string{"If-None-Match", "If-Modified-Since"} {
+			operation.Parameters = append(operation.Parameters, openapi.Parameter{
+				Name:   name,
+				In:     "header",
+				Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+		success.Headers = map[string]*openapi.Header{
+			"ETag":          {Description: "Weak validator for the representation", Schema: &openapi.Schema{Type: "string"}},
+			"Last-Modified": {Description: "Time of the most recent change", Schema: &openapi.Schema{Type: "string"}},
+			"Cache-Control": {Schema: &openapi.Schema{Type: "string"}},
+		}
+		operation.Responses[strconv.Itoa(http.StatusNotModified)] = &openapi.Response{
+			Description: "The client's cached representation is still current",
+			Headers:     success.Headers,
+		}
+	}
+
+	for _, code := range op.AltStatus {
+		operation.Responses[strconv.Itoa(code)] = &openapi.Response{
+			Description: http.StatusText(code),
+			Content:     success.Content,
+		}
+	}
+
+	for _, code := range append(op.Errors, http.StatusInternalServerError) {
+		operation.Responses[strconv.Itoa(code)] = &openapi.Response{
+			Description: http.StatusText(code),
+			Content: map[string]*openapi.MediaType{
+				"application/json": {Schema: errorSchema},
+			},
+		}
+	}
+
+	if op.Auth {
+		operation.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+	if op.OptionalAuth {
+		operation.Security = []map[string][]string{{"bearerAuth": {}}, {}}
+	}
+
+	return operation
+}
+
+var binarySchema = &openapi.Schema{Type: "string", Format: "binary"}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, strings.TrimSuffix(strings.Trim(segment, "{}"), "..."))
+		}
+	}
+	return params
+}
+
+func pathParamType(name string) string {
+	if name == "id" || strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "_id") {
+		return "integer"
+	}
+	return "string"
+}
+
+// operationID turns "GET /tasks/{id}" into "getTasksById".
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+			segment = strings.Trim(segment, "{}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		}) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-manager-api/openapi"
+	"testing"
+)
+
+type recordingRouter struct {
+	patterns []string
+}
+
+func (r *recordingRouter) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.patterns = append(r.patterns, pattern)
+}
+
+func TestOpenAPISpecCoversRegisteredRoutes(t *testing.T) {
+	router := &recordingRouter{}
+	registerRoutes(router, Dependencies{})
+
+	doc := BuildOpenAPIDocument()
+
+	for _, pattern := range router.patterns {
+		method, path, hasMethod := strings.Cut(pattern, " ")
+		if !hasMethod {
+			method, path = "", pattern
+		}
+
+		if !specHasRoute(doc.Paths, method, path) {
+			t.Errorf("route %q is registered but missing from the OpenAPI document", pattern)
+		}
+	}
+}
+
+func TestOpenAPIOperationsAreServed(t *testing.T) {
+	mux := http.NewServeMux()
+	registerRoutes(mux, Dependencies{})
+
+	for specPath, item := range BuildOpenAPIDocument().Paths {
+		path := specPath
+		for _, name := range pathParams(specPath) {
+			path = strings.Replace(path, "{"+name+"}", "1", 1)
+		}
+
+		for _, method := range []string{"GET", "PUT", "POST", "DELETE", "PATCH"} {
+			if item.Method(method) == nil {
+				continue
+			}
+			req := httptest.NewRequest(method, path, nil)
+			if _, pattern := mux.Handler(req); pattern == "" {
+				t.Errorf("documented operation %s %s is not served by any registered route", method, specPath)
+			}
+		}
+	}
+}
+
+func TestOpenAPIDocumentReferencesResolve(t *testing.T) {
+	raw, err := json.Marshal(BuildOpenAPIDocument())
+	if err != nil {
+		t.Fatalf("marshal document: %v", err)
+	}
+
+	var doc struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("unmarshal document: %v", err)
+	}
+
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi version = %q, want 3.1.0", doc.OpenAPI)
+	}
+
+	const prefix = `"$ref":"#/components/schemas/`
+	text := string(raw)
+	for {
+		i := strings.Index(text, prefix)
+		if i < 0 {
+			break
+		}
+		text = text[i+len(prefix):]
+		name := text[:strings.Index(text, `"`)]
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("$ref to undefined schema %q", name)
+		}
+	}
+}
+
+// specHasRoute reports whether a registered mux pattern is documented. A
+// pattern without a method matches any documented method, and a subtree
+// pattern ("/tasks/") matches any documented path below it.
+func specHasRoute(paths map[string]*openapi.PathItem, method, path string) bool {
+	for specPath, item := range paths {
+		pathMatches := specPath == path ||
+			(strings.HasSuffix(path, "/") && strings.HasPrefix(specPath, path))
+		if !pathMatches {
+			continue
+		}
+		if method == "" {
+			return true
+		}
+		if item.Method(method) != nil {
+			return true
+		}
+	}
+	return false
+}
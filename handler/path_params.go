@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"task-manager-api/domain"
+)
+
+// pathID parses a positive integer path wildcard such as {id}.
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		return 0, &domain.ValidationError{Field: name, Message: "must be a positive integer"}
+	}
+	return id, nil
+}
+
+// queryInt parses an optional integer query parameter, returning def when it
+// is absent. Range checks are left to the DTO's validate tags.
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &domain.ValidationError{Field: name, Message: "must be an integer"}
+	}
+	return n, nil
+}
+
+// queryList splits a comma-separated query parameter, dropping empty items.
+func queryList(r *http.Request, name string) []string {
+	var items []string
+	for _, item := range strings.Split(r.URL.Query().Get(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// queryBool parses an optional boolean query parameter such as ?archived=true.
+func queryBool(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, &domain.ValidationError{Field: name, Message: "must be true or false"}
+	}
+	return b, nil
+}
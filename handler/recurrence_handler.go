@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+// RegisterRecurrenceRoutes follows the task routes: anyone who may edit a
+// task may make it recurring.
+func RegisterRecurrenceRoutes(mux Router, uc *usecase.RecurrenceUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /tasks/{id}/recurrence", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getRecurrence(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /tasks/{id}/recurrence", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		setRecurrence(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/recurrence", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteRecurrence(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /tasks/{id}/recurrence/occurrences", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		previewOccurrences(w, r, uc)
+	}))
+}
+
+func getRecurrence(w http.ResponseWriter, r *http.Request, uc *usecase.RecurrenceUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	recurrence, err := uc.GetRecurrence(r.Context(), taskID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recurrence)
+}
+
+func setRecurrence(w http.ResponseWriter, r *http.Request, uc *usecase.RecurrenceUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.SetRecurrenceDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	recurrence, err := uc.SetRecurrence(r.Context(), taskID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recurrence)
+}
+
+func deleteRecurrence(w http.ResponseWriter, r *http.Request, uc *usecase.RecurrenceUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteRecurrence(r.Context(), taskID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func previewOccurrences(w http.ResponseWriter, r *http.Request, uc *usecase.RecurrenceUsecase) {
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	count, err := queryInt(r, "count", 10)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	occurrences, err := uc.PreviewOccurrences(r.Context(), taskID, count)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, occurrences)
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+// RegisterReminderRoutes serves reminders, which belong to the signed-in
+// user who set them, and that user's notifications and settings.
+func RegisterReminderRoutes(mux Router, uc *usecase.ReminderUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /tasks/{id}/reminders", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listReminders(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /tasks/{id}/reminders", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createReminder(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}/reminders/{reminderId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteReminder(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /users/me/notifications", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listNotifications(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /users/me/notifications/{notificationId}/read", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		markNotificationRead(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /users/me/notification-settings", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getNotificationSettings(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /users/me/notification-settings", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		saveNotificationSettings(w, r, uc)
+	}))
+}
+
+func listReminders(w http.ResponseWriter, r *http.Request, uc *usecase.ReminderUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	reminders, err := uc.ListReminders(r.Context(), user.ID, taskID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reminders)
+}
+
+func createReminder(w http.ResponseWriter, r *http.Request, uc *usecase.ReminderUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.CreateReminderDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	reminder, err := uc.CreateReminder(r.Context(), user.ID, taskID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reminder)
+}
+
+func deleteReminder(w http.ResponseWriter, r *http.Request, uc *usecase.ReminderUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	taskID, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	reminderID, err := pathID(r, "reminderId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteReminder(r.Context(), user.ID, taskID, reminderID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func listNotifications(w http.ResponseWriter, r *http.Request, uc *usecase.ReminderUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	unreadOnly, err := queryBool(r, "unread")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	notifications, err := uc.ListNotifications(r.Context(), user.ID, unreadOnly)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, notifications)
+}
+
+func markNotificationRead(w http.ResponseWriter, r *http.Request, uc *usecase.ReminderUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	notificationID, err := pathID(r, "notificationId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	notification, err := uc.MarkNotificationRead(r.Context(), user.ID, notificationID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, notification)
+}
+
+func getNotificationSettings(w http.ResponseWriter, r *http.Request, uc *usecase.ReminderUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	settings, err := uc.GetSettings(r.Context(), user.ID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func saveNotificationSettings(w http.ResponseWriter, r *http.Request, uc *usecase.ReminderUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.NotificationSettingsDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	settings, err := uc.SaveSettings(r.Context(), user.ID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
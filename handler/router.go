@@ -1,15 +1,139 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 	"task-manager-api/repository"
 	"task-manager-api/usecase"
+	"time"
 )
 
-func SetupRoutes(mux *http.ServeMux, uc *usecase.TaskUsecase, authUc *usecase.AuthUsecase, processor *usecase.TaskProcessor, cache *usecase.CacheService, repo repository.TaskRepository) {
-	RegisterTaskRoutes(mux, uc)
-	RegisterAuthRoutes(mux, authUc)
-	RegisterBackgroundRoutes(mux, processor)
-	RegisterCacheRoutes(mux, cache)
-	RegisterHealthRoutes(mux, repo, cache)
+// Router is the part of *http.ServeMux the Register*Routes functions use.
+// Accepting an interface lets tests record every registered pattern and lets
+// API versions mount the same handlers under a prefix.
+type Router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// Dependencies are the usecases shared by every mounted API version.
+type Dependencies struct {
+	Tasks        *usecase.TaskUsecase
+	Auth         *usecase.AuthUsecase
+	Processor    *usecase.TaskProcessor
+	Cache        usecase.Cache
+	Views        *usecase.SavedViewUsecase
+	Labels       *usecase.LabelUsecase
+	Workspaces   *usecase.WorkspaceUsecase
+	Assignees    *usecase.AssigneeUsecase
+	Checklists   *usecase.ChecklistUsecase
+	Dependencies *usecase.DependencyUsecase
+	Comments     *usecase.CommentUsecase
+	Attachments  *usecase.AttachmentUsecase
+	Recurrences  *usecase.RecurrenceUsecase
+	Reminders    *usecase.ReminderUsecase
+	Webhooks     *usecase.WebhookUsecase
+	Events       *usecase.EventHub
+	Repo         repository.TaskRepository
+}
+
+// APIVersion is a set of handlers mounted under a path prefix. A /v2 is added
+// by appending another APIVersion to apiVersions; it receives the same
+// Dependencies as /v1, so only the handlers and DTO mapping need to change.
+type APIVersion struct {
+	Prefix     string
+	Register   func(r Router, deps Dependencies)
+	Operations []apiOperation
+}
+
+var apiVersions = []APIVersion{
+	{Prefix: "/v1", Register: registerV1Routes, Operations: v1Operations},
+}
+
+// legacyVersion is also served at the root path, without a prefix, for
+// clients written before versioning. Those responses carry Deprecation and
+// Sunset headers pointing them at the versioned route.
+var legacyVersion = apiVersions[0]
+
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+func SetupRoutes(mux *http.ServeMux, deps Dependencies) {
+	registerRoutes(mux, deps)
+}
+
+func registerRoutes(mux Router, deps Dependencies) {
+	for _, version := range apiVersions {
+		version.Register(prefixRouter{mux: mux, prefix: version.Prefix}, deps)
+	}
+	legacyVersion.Register(deprecatedRouter{mux: mux, successor: legacyVersion.Prefix}, deps)
+
+	// Operational endpoints are not part of the versioned API.
+	RegisterHealthRoutes(mux, deps.Repo, deps.Cache)
+	RegisterDocsRoutes(mux)
+}
+
+func registerV1Routes(r Router, deps Dependencies) {
+	RegisterTaskRoutes(r, deps.Tasks, deps.Auth)
+	RegisterAuthRoutes(r, deps.Auth)
+	RegisterBackgroundRoutes(r, deps.Processor)
+	RegisterCacheRoutes(r, deps.Cache)
+	RegisterViewRoutes(r, deps.Views, deps.Auth)
+	RegisterLabelRoutes(r, deps.Labels, deps.Auth)
+	RegisterWorkspaceRoutes(r, deps.Workspaces, deps.Tasks, deps.Auth)
+	RegisterAssigneeRoutes(r, deps.Assignees, deps.Tasks, deps.Auth)
+	RegisterChecklistRoutes(r, deps.Checklists, deps.Auth)
+	RegisterDependencyRoutes(r, deps.Dependencies, deps.Auth)
+	RegisterCommentRoutes(r, deps.Comments, deps.Auth)
+	RegisterAttachmentRoutes(r, deps.Attachments, deps.Auth)
+	RegisterRecurrenceRoutes(r, deps.Recurrences, deps.Auth)
+	RegisterReminderRoutes(r, deps.Reminders, deps.Auth)
+	RegisterWebhookRoutes(r, deps.Webhooks, deps.Auth)
+	RegisterEventRoutes(r, deps.Events, deps.Auth)
+}
+
+// prefixRouter mounts patterns such as "GET /tasks/{id}" under a prefix,
+// producing "GET /v1/tasks/{id}".
+type prefixRouter struct {
+	mux    Router
+	prefix string
+}
+
+func (p prefixRouter) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	method, path := splitPattern(pattern)
+	p.mux.HandleFunc(joinPattern(method, p.prefix+path), handler)
+}
+
+// deprecatedRouter registers patterns unchanged but marks every response as
+// deprecated in favour of the same path under successor.
+type deprecatedRouter struct {
+	mux       Router
+	successor string
+}
+
+func (d deprecatedRouter) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	d.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", legacyDeprecatedAt.Unix()))
+		w.Header().Set("Sunset", legacySunsetAt.Format(http.TimeFormat))
+		w.Header().Add("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", d.successor, r.URL.Path))
+
+		handler(w, r)
+	})
+}
+
+func splitPattern(pattern string) (string, string) {
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		return "", pattern
+	}
+	return method, path
+}
+
+func joinPattern(method, path string) string {
+	if method == "" {
+		return path
+	}
+	return method + " " + path
 }
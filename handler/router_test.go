@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLegacyAliasesCarryDeprecationHeaders(t *testing.T) {
+	mux := http.NewServeMux()
+	registerRoutes(mux, Dependencies{})
+
+	legacy := httptest.NewRecorder()
+	mux.ServeHTTP(legacy, httptest.NewRequest(http.MethodGet, "/tasks/not-a-number", nil))
+
+	if legacy.Code != http.StatusBadRequest {
+		t.Fatalf("legacy status = %d, want %d", legacy.Code, http.StatusBadRequest)
+	}
+	if legacy.Header().Get("Deprecation") == "" || legacy.Header().Get("Sunset") == "" {
+		t.Errorf("legacy response is missing Deprecation/Sunset headers: %v", legacy.Header())
+	}
+	if got, want := legacy.Header().Get("Link"), `</v1/tasks/not-a-number>; rel="successor-version"`; got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+
+	versioned := httptest.NewRecorder()
+	mux.ServeHTTP(versioned, httptest.NewRequest(http.MethodGet, "/v1/tasks/not-a-number", nil))
+
+	if versioned.Code != http.StatusBadRequest {
+		t.Fatalf("v1 status = %d, want %d", versioned.Code, http.StatusBadRequest)
+	}
+	if versioned.Header().Get("Deprecation") != "" {
+		t.Errorf("v1 response should not be deprecated: %v", versioned.Header())
+	}
+}
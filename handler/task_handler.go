@@ -2,65 +2,117 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
+	"task-manager-api/domain"
 	"task-manager-api/dto"
 	"task-manager-api/usecase"
+	"task-manager-api/validation"
 )
 
-func RegisterTaskRoutes(mux *http.ServeMux, uc *usecase.TaskUsecase) {
-	mux.HandleFunc("/tasks", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			getAllTasks(w, r, uc)
-
-		case http.MethodPost:
-			createTask(w, r, uc)
-
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
-
-	mux.HandleFunc("/tasks/", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			getTaskByID(w, r, uc)
+// RegisterTaskRoutes serves tasks to anonymous and signed-in callers alike;
+// the usecase decides which project tasks each caller can see.
+func RegisterTaskRoutes(mux Router, uc *usecase.TaskUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /tasks", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getAllTasks(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /tasks", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createTask(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /tasks/bulk", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		bulkTasks(w, r, uc)
+	}))
+
+	// The literal path takes precedence over the {id} wildcard.
+	mux.HandleFunc("GET /tasks/search", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		searchTasks(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /tasks/{id}", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getTaskByID(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /tasks/{id}", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		updateTask(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /tasks/{id}", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteTask(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /tasks/{id}/subtasks", optionallyAuthenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getSubtasks(w, r, uc)
+	}))
+}
 
-		case http.MethodPut:
-			updateTask(w, r, uc)
+func getAllTasks(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
+	params := r.URL.Query()
+
+	var list usecase.TaskList
+	var err error
+	if params.Has("query") || params.Has("labels") || params.Has("assignee") {
+		list, err = uc.FilterTasks(r.Context(), dto.TaskFilterDTO{
+			Query:      params.Get("query"),
+			Labels:     queryList(r, "labels"),
+			LabelMatch: params.Get("labels_match"),
+			Assignee:   params.Get("assignee"),
+		})
+	} else {
+		list, err = uc.ListTasks(r.Context())
+	}
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
 
-		case http.MethodDelete:
-			deleteTask(w, r, uc)
+	// The list depends on who is asking.
+	w.Header().Set("Vary", "Authorization")
+	if writeNotModified(w, r, list.ETag, list.LastModified) {
+		return
+	}
 
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	w.Header().Set("Content-Type", "application/json")
+	jsonData, err := json.Marshal(list.Tasks)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+	w.Write(jsonData)
 }
 
-func getAllTasks(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
-	w.Header().Set("Content-Type", "application/json")
+func searchTasks(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
+	req := dto.TaskSearchQueryDTO{Q: r.URL.Query().Get("q")}
 
-	tasks, err := uc.GetAllTasks(r.Context())
+	var err error
+	if req.Limit, err = queryInt(r, "limit", 20); err != nil {
+		HandleError(w, err)
+		return
+	}
+	if req.Offset, err = queryInt(r, "offset", 0); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	results, err := uc.SearchTasks(r.Context(), req)
 	if err != nil {
 		HandleError(w, err)
 		return
 	}
-	jsonData, err := json.Marshal(tasks)
+
+	w.Header().Set("Content-Type", "application/json")
+	jsonData, err := json.Marshal(results)
 	if err != nil {
 		HandleError(w, err)
 		return
 	}
-	fmt.Fprintf(w, string(jsonData))
+	w.Write(jsonData)
 }
 
 func createTask(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
 	var newTask dto.CreateTaskDTO
 
-	err := json.NewDecoder(r.Body).Decode(&newTask)
+	err := validation.DecodeJSON(w, r, &newTask)
 
 	if err != nil {
 		HandleError(w, err)
@@ -81,19 +133,17 @@ func createTask(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase)
 		return
 	}
 
-	fmt.Fprintf(w, string(taskResponse))
+	w.Write(taskResponse)
 }
 
 func getTaskByID(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
-	path := r.URL.Path
-	idStr := strings.TrimPrefix(path, "/tasks/")
-
-	id, err := strconv.Atoi(idStr)
+	id, err := pathID(r, "id")
 	if err != nil {
 		HandleError(w, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	task, err := uc.GetByID(r.Context(), id)
 	if err != nil {
 		HandleError(w, err)
@@ -106,21 +156,18 @@ func getTaskByID(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase
 		return
 	}
 
-	fmt.Fprintf(w, string(jsonData))
+	w.Write(jsonData)
 }
 
 func updateTask(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
-	path := r.URL.Path
-	idStr := strings.TrimPrefix(path, "/tasks/")
-
-	id, err := strconv.Atoi(idStr)
+	id, err := pathID(r, "id")
 	if err != nil {
 		HandleError(w, err)
 		return
 	}
 
 	var updateReq dto.UpdateTaskDTO
-	err = json.NewDecoder(r.Body).Decode(&updateReq)
+	err = validation.DecodeJSON(w, r, &updateReq)
 	if err != nil {
 		HandleError(w, err)
 		return
@@ -139,20 +186,17 @@ func updateTask(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase)
 		return
 	}
 
-	fmt.Fprintf(w, string(jsonData))
+	w.Write(jsonData)
 }
 
 func deleteTask(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
-	path := r.URL.Path
-	idStr := strings.TrimPrefix(path, "/tasks/")
-
-	id, err := strconv.Atoi(idStr)
+	id, err := pathID(r, "id")
 	if err != nil {
 		HandleError(w, err)
 		return
 	}
 
-	err = uc.DeleteTask(r.Context(), id)
+	err = uc.DeleteTask(r.Context(), id, r.URL.Query().Get("cascade"))
 	if err != nil {
 		HandleError(w, err)
 		return
@@ -160,3 +204,76 @@ func deleteTask(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase)
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func getSubtasks(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	list, err := uc.Subtasks(r.Context(), id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list.Tasks)
+}
+
+func bulkTasks(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
+	var req dto.BulkTaskRequestDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	outcome, err := uc.BulkTasks(r.Context(), req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	response := dto.BulkTaskResponseDTO{
+		Mode:      outcome.Mode,
+		Committed: outcome.Committed,
+		Results:   make([]dto.BulkTaskResultDTO, len(outcome.Results)),
+	}
+
+	for i, result := range outcome.Results {
+		item := dto.BulkTaskResultDTO{Index: i, Op: result.Op, ID: result.ID, Task: result.Task}
+
+		if result.Err != nil {
+			status, errBody := errorResponse(result.Err)
+			item.Status = status
+			item.Error = &errBody
+			response.Failed++
+		} else {
+			item.Status = bulkSuccessStatus(result.Op)
+			response.Succeeded++
+		}
+
+		response.Results[i] = item
+	}
+
+	// 207 tells clients to inspect the per-operation statuses.
+	status := http.StatusOK
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func bulkSuccessStatus(op string) int {
+	switch op {
+	case domain.OperationCreate:
+		return http.StatusCreated
+	case domain.OperationDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+func RegisterViewRoutes(mux Router, uc *usecase.SavedViewUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /views", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listViews(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /views", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createView(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /views/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getView(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /views/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		updateView(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /views/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteView(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /views/{id}/tasks", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getViewTasks(w, r, uc)
+	}))
+}
+
+func listViews(w http.ResponseWriter, r *http.Request, uc *usecase.SavedViewUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	views, err := uc.ListViews(r.Context(), user.ID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, views)
+}
+
+func createView(w http.ResponseWriter, r *http.Request, uc *usecase.SavedViewUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.SavedViewDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	view, err := uc.CreateView(r.Context(), user.ID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, view)
+}
+
+func getView(w http.ResponseWriter, r *http.Request, uc *usecase.SavedViewUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	view, err := uc.GetView(r.Context(), user.ID, id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, view)
+}
+
+func updateView(w http.ResponseWriter, r *http.Request, uc *usecase.SavedViewUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.SavedViewDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	view, err := uc.UpdateView(r.Context(), user.ID, id, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, view)
+}
+
+func deleteView(w http.ResponseWriter, r *http.Request, uc *usecase.SavedViewUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteView(r.Context(), user.ID, id); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getViewTasks(w http.ResponseWriter, r *http.Request, uc *usecase.SavedViewUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	result, err := uc.ViewTasks(r.Context(), user.ID, id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+// RegisterWebhookRoutes serves webhook subscriptions, which belong to the
+// signed-in user who created them, and their delivery logs.
+func RegisterWebhookRoutes(mux Router, uc *usecase.WebhookUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /webhooks", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listWebhooks(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /webhooks", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createWebhook(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /webhooks/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getWebhook(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /webhooks/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		updateWebhook(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /webhooks/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteWebhook(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /webhooks/{id}/deliveries", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listWebhookDeliveries(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /webhooks/{id}/deliveries/{deliveryId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getWebhookDelivery(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /webhooks/{id}/deliveries/{deliveryId}/redeliver", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		redeliverWebhook(w, r, uc)
+	}))
+}
+
+func listWebhooks(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	hooks, err := uc.ListWebhooks(r.Context(), user.ID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, hooks)
+}
+
+func createWebhook(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.CreateWebhookDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	hook, err := uc.CreateWebhook(r.Context(), user.ID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func getWebhook(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	hook, err := uc.GetWebhook(r.Context(), user.ID, id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, hook)
+}
+
+func updateWebhook(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.UpdateWebhookDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	hook, err := uc.UpdateWebhook(r.Context(), user.ID, id, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, hook)
+}
+
+func deleteWebhook(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteWebhook(r.Context(), user.ID, id); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func listWebhookDeliveries(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	limit, err := queryInt(r, "limit", 20)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	deliveries, err := uc.ListDeliveries(r.Context(), user.ID, id, limit)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func getWebhookDelivery(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	deliveryID, err := pathID(r, "deliveryId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	delivery, err := uc.GetDelivery(r.Context(), user.ID, id, deliveryID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+func redeliverWebhook(w http.ResponseWriter, r *http.Request, uc *usecase.WebhookUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	deliveryID, err := pathID(r, "deliveryId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	delivery, err := uc.Redeliver(r.Context(), user.ID, id, deliveryID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, delivery)
+}
@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"net/http"
+	"task-manager-api/dto"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+)
+
+func RegisterWorkspaceRoutes(mux Router, uc *usecase.WorkspaceUsecase, tasks *usecase.TaskUsecase, auth *usecase.AuthUsecase) {
+	mux.HandleFunc("GET /workspaces", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listWorkspaces(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /workspaces", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createWorkspace(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /workspaces/{id}/members", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listMembers(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /workspaces/{id}/members", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		setMember(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /workspaces/{id}/members/{userId}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		removeMember(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /workspaces/{id}/projects", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		listProjects(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /workspaces/{id}/projects", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		createProject(w, r, uc)
+	}))
+
+	mux.HandleFunc("GET /projects/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getProject(w, r, uc)
+	}))
+
+	mux.HandleFunc("PUT /projects/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		updateProject(w, r, uc)
+	}))
+
+	mux.HandleFunc("DELETE /projects/{id}", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		deleteProject(w, r, uc)
+	}))
+
+	mux.HandleFunc("POST /projects/{id}/archive", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		setProjectArchived(w, r, uc, true)
+	}))
+
+	mux.HandleFunc("DELETE /projects/{id}/archive", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		setProjectArchived(w, r, uc, false)
+	}))
+
+	mux.HandleFunc("GET /projects/{id}/tasks", authenticated(auth, func(w http.ResponseWriter, r *http.Request) {
+		getProjectTasks(w, r, tasks)
+	}))
+}
+
+func listWorkspaces(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	workspaces, err := uc.ListWorkspaces(r.Context(), user.ID)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, workspaces)
+}
+
+func createWorkspace(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.WorkspaceDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	workspace, err := uc.CreateWorkspace(r.Context(), user.ID, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, workspace)
+}
+
+func listMembers(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	members, err := uc.ListMembers(r.Context(), user.ID, id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+func setMember(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.WorkspaceMemberDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	member, err := uc.SetMember(r.Context(), user.ID, id, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, member)
+}
+
+func removeMember(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	memberID, err := pathID(r, "userId")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.RemoveMember(r.Context(), user.ID, id, memberID); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func listProjects(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	archived, err := queryBool(r, "archived")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	projects, err := uc.ListProjects(r.Context(), user.ID, id, archived)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func createProject(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.ProjectDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	project, err := uc.CreateProject(r.Context(), user.ID, id, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, project)
+}
+
+func getProject(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	project, err := uc.GetProject(r.Context(), user.ID, id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+func updateProject(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	var req dto.ProjectDTO
+	if err := validation.DecodeJSON(w, r, &req); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	project, err := uc.UpdateProject(r.Context(), user.ID, id, req)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+func deleteProject(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	if err := uc.DeleteProject(r.Context(), user.ID, id); err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setProjectArchived(w http.ResponseWriter, r *http.Request, uc *usecase.WorkspaceUsecase, archived bool) {
+	user, err := currentUser(r)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	project, err := uc.SetArchived(r.Context(), user.ID, id, archived)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+func getProjectTasks(w http.ResponseWriter, r *http.Request, uc *usecase.TaskUsecase) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	list, err := uc.ProjectTasks(r.Context(), id)
+	if err != nil {
+		HandleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list.Tasks)
+}
@@ -7,16 +7,130 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"task-manager-api/blobstore"
+	"task-manager-api/domain"
 	"task-manager-api/handler"
 	"task-manager-api/middleware"
 	"task-manager-api/repository"
+	"task-manager-api/resp"
 	"task-manager-api/usecase"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// newCache uses a Redis-compatible server when REDIS_ADDR is set, so replicas
+// share one cache, and the in-process cache otherwise. Keys are prefixed with
+// APP_ENV so environments can share a server.
+//
+// The in-process cache is snapshotted to CACHE_SNAPSHOT_PATH, if set, every
+// CACHE_SNAPSHOT_INTERVAL (default 1m) and on shutdown, and restored from it
+// on startup.
+func newCache(config usecase.CacheConfig) (usecase.Cache, func()) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		cache := usecase.NewCacheServiceWithConfig(config)
+		cache.StartJanitor(time.Minute)
+
+		if path := os.Getenv("CACHE_SNAPSHOT_PATH"); path != "" {
+			restored, err := cache.LoadSnapshot(path)
+			if err != nil {
+				log.Printf("Warning: could not restore cache snapshot %s: %v", path, err)
+			} else {
+				log.Printf("Restored %d cache entries from %s", restored, path)
+			}
+
+			interval := time.Minute
+			if value := os.Getenv("CACHE_SNAPSHOT_INTERVAL"); value != "" {
+				parsed, err := time.ParseDuration(value)
+				if err != nil || parsed <= 0 {
+					log.Fatalf("Invalid CACHE_SNAPSHOT_INTERVAL %q", value)
+				}
+				interval = parsed
+			}
+			cache.StartSnapshots(path, interval)
+		}
+
+		return cache, cache.Close
+	}
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "development"
+	}
+
+	client := resp.NewClient(addr, resp.Options{Password: os.Getenv("REDIS_PASSWORD")})
+	cache := usecase.NewRedisCache(client, "task-manager:"+env+":", config)
+	log.Printf("Using Redis cache at %s (env %s)", addr, env)
+	return cache, func() { cache.Close() }
+}
+
+// taskConfig reads the task rules from the environment.
+// TASK_MAX_SUBTASK_DEPTH (default 3) limits how deeply subtasks nest; 0
+// disables them.
+func taskConfig() usecase.TaskConfig {
+	config := usecase.TaskConfig{MaxSubtaskDepth: 3}
+	if value := os.Getenv("TASK_MAX_SUBTASK_DEPTH"); value != "" {
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			log.Fatalf("Invalid TASK_MAX_SUBTASK_DEPTH %q", value)
+		}
+		config.MaxSubtaskDepth = depth
+	}
+	return config
+}
+
+// attachmentConfig reads the upload limits. ATTACHMENT_ALLOWED_TYPES is a
+// comma-separated list of media types.
+func attachmentConfig() usecase.AttachmentConfig {
+	config := usecase.AttachmentConfig{
+		MaxBytes: 10 << 20,
+		AllowedTypes: []string{
+			"image/png", "image/jpeg", "image/gif", "image/webp",
+			"application/pdf", "text/plain", "application/zip", "application/x-gzip",
+		},
+	}
+	if value := os.Getenv("ATTACHMENT_MAX_BYTES"); value != "" {
+		maxBytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxBytes <= 0 {
+			log.Fatalf("Invalid ATTACHMENT_MAX_BYTES %q", value)
+		}
+		config.MaxBytes = maxBytes
+	}
+	if value := os.Getenv("ATTACHMENT_ALLOWED_TYPES"); value != "" {
+		config.AllowedTypes = nil
+		for _, contentType := range strings.Split(value, ",") {
+			if contentType = strings.TrimSpace(contentType); contentType != "" {
+				config.AllowedTypes = append(config.AllowedTypes, contentType)
+			}
+		}
+	}
+	return config
+}
+
+// reminderNotifiers sets up a notifier per reminder channel. Email goes
+// through the SMTP relay at SMTP_ADDR (default localhost:25), from
+// SMTP_FROM.
+func reminderNotifiers(notifications repository.NotificationRepository) map[string]usecase.Notifier {
+	smtpAddr := os.Getenv("SMTP_ADDR")
+	if smtpAddr == "" {
+		smtpAddr = "localhost:25"
+	}
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		smtpFrom = "reminders@localhost"
+	}
+
+	return map[string]usecase.Notifier{
+		domain.ChannelInApp:   usecase.NewInAppNotifier(notifications),
+		domain.ChannelWebhook: usecase.NewWebhookNotifier(10 * time.Second),
+		domain.ChannelEmail:   usecase.NewSMTPNotifier(smtpAddr, smtpFrom),
+	}
+}
+
 func main() {
 	err := godotenv.Load()
 	if err != nil {
@@ -35,9 +149,96 @@ func main() {
 	}
 	defer userRepo.Close()
 
+	viewRepo, err := repository.NewSQLiteSavedViewRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize saved view repository: %v", err)
+	}
+	defer viewRepo.Close()
+
+	labelRepo, err := repository.NewSQLiteLabelRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize label repository: %v", err)
+	}
+	defer labelRepo.Close()
+
+	workspaceRepo, err := repository.NewSQLiteWorkspaceRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize workspace repository: %v", err)
+	}
+	defer workspaceRepo.Close()
+
+	assigneeRepo, err := repository.NewSQLiteAssigneeRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize assignee repository: %v", err)
+	}
+	defer assigneeRepo.Close()
+
+	checklistRepo, err := repository.NewSQLiteChecklistRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize checklist repository: %v", err)
+	}
+	defer checklistRepo.Close()
+
+	dependencyRepo, err := repository.NewSQLiteDependencyRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize dependency repository: %v", err)
+	}
+	defer dependencyRepo.Close()
+
+	commentRepo, err := repository.NewSQLiteCommentRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize comment repository: %v", err)
+	}
+	defer commentRepo.Close()
+
+	attachmentRepo, err := repository.NewSQLiteAttachmentRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment repository: %v", err)
+	}
+	defer attachmentRepo.Close()
+
+	recurrenceRepo, err := repository.NewSQLiteRecurrenceRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize recurrence repository: %v", err)
+	}
+	defer recurrenceRepo.Close()
+
+	reminderRepo, err := repository.NewSQLiteReminderRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize reminder repository: %v", err)
+	}
+	defer reminderRepo.Close()
+
+	notificationRepo, err := repository.NewSQLiteNotificationRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize notification repository: %v", err)
+	}
+	defer notificationRepo.Close()
+
+	webhookRepo, err := repository.NewSQLiteWebhookRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize webhook repository: %v", err)
+	}
+	defer webhookRepo.Close()
+
+	attachmentDir := os.Getenv("ATTACHMENT_DIR")
+	if attachmentDir == "" {
+		attachmentDir = "attachments"
+	}
+	blobs, err := blobstore.NewLocalStore(attachmentDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment store: %v", err)
+	}
+
+	idempotencyRepo, err := repository.NewSQLiteIdempotencyRepository("tasks.db")
+	if err != nil {
+		log.Fatalf("Failed to initialize idempotency repository: %v", err)
+	}
+	defer idempotencyRepo.Close()
+
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, `{"message": "Hello from Task Manager API"}`)
 	})
@@ -47,20 +248,73 @@ func main() {
 		log.Fatalf("JWT_SECRET environment variable not set")
 	}
 
-	cache := usecase.NewCacheService(5 * time.Minute)
-	uc := usecase.NewTaskUsecase(repo, cache)
+	cache, closeCache := newCache(usecase.CacheConfig{
+		TTL:        5 * time.Minute,
+		MaxEntries: 10000,
+		MaxBytes:   32 << 20,
+
+		StaleWhileRevalidate: 30 * time.Second,
+		NegativeTTL:          10 * time.Second,
+	})
+	defer closeCache()
+	events := usecase.NewEventBus()
+
+	access := usecase.NewTaskAccess(workspaceRepo, cache)
+	webhooks := usecase.NewWebhookUsecase(webhookRepo, repo, access)
+	events.Subscribe("*", webhooks.Enqueue)
+	webhooks.Start(5 * time.Second)
+	defer webhooks.Close()
+	eventHub := usecase.NewEventHub(access)
+	for _, eventType := range []string{usecase.EventTaskCreated, usecase.EventTaskUpdated, usecase.EventTaskDeleted} {
+		events.Subscribe(eventType, eventHub.Publish)
+	}
+	uc := usecase.NewTaskUsecase(repo, cache, access, taskConfig(), events)
 	authUc := usecase.NewAuthUsecase(userRepo, jwtSecret)
 	processor := usecase.NewTaskProcessor(repo)
-
-	handler.SetupRoutes(mux, uc, authUc, processor, cache, repo)
+	attachments := usecase.NewAttachmentUsecase(attachmentRepo, repo, blobs, access, attachmentConfig())
+	attachments.StartGC(time.Hour)
+	defer attachments.Close()
+	recurrences := usecase.NewRecurrenceUsecase(recurrenceRepo, repo, cache, access, events)
+	recurrences.Start(time.Minute)
+	defer recurrences.Close()
+	reminders := usecase.NewReminderUsecase(reminderRepo, notificationRepo, repo, userRepo, access, reminderNotifiers(notificationRepo))
+	reminders.Start(30 * time.Second)
+	defer reminders.Close()
+
+	handler.SetupRoutes(mux, handler.Dependencies{
+		Tasks:        uc,
+		Auth:         authUc,
+		Processor:    processor,
+		Cache:        cache,
+		Views:        usecase.NewSavedViewUsecase(viewRepo, repo, cache, access),
+		Labels:       usecase.NewLabelUsecase(labelRepo, repo, cache, access),
+		Workspaces:   usecase.NewWorkspaceUsecase(workspaceRepo, userRepo, cache),
+		Assignees:    usecase.NewAssigneeUsecase(assigneeRepo, repo, userRepo, cache, access, events),
+		Checklists:   usecase.NewChecklistUsecase(checklistRepo, repo, cache, access, events),
+		Dependencies: usecase.NewDependencyUsecase(dependencyRepo, repo, cache, access, events),
+		Comments:     usecase.NewCommentUsecase(commentRepo, repo, userRepo, cache, access, events),
+		Attachments:  attachments,
+		Recurrences:  recurrences,
+		Reminders:    reminders,
+		Webhooks:     webhooks,
+		Events:       eventHub,
+		Repo:         repo,
+	})
 
 	rateLimiter := middleware.NewRateLimiter(20)
+	// Event streams stay open; they are capped per user by the hub instead.
+	rateLimiter.Exempt("/v1/events", "/events")
+
+	idempotency := middleware.NewIdempotencyMiddleware(idempotencyRepo, authUc, 24*time.Hour)
+	idempotency.StartJanitor(time.Hour)
+	defer idempotency.Close()
 
 	handler := middleware.Chain(
 		rateLimiter.RateLimiterMiddleware,
 		middleware.CorrelationIDMiddleware,
 		middleware.LoggingMiddleware,
 		middleware.RecoveryMiddleware,
+		idempotency.Middleware,
 	)(mux)
 
 	srv := &http.Server{
@@ -70,10 +324,20 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	// Shutdown waits for open requests, and event streams never finish.
+	srv.RegisterOnShutdown(eventHub.Close)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
+	// Preload hot keys so the first requests after a deploy don't all hit
+	// the database. Set CACHE_WARMUP=false to skip.
+	if os.Getenv("CACHE_WARMUP") != "false" {
+		warmCtx, cancelWarm := context.WithTimeout(context.Background(), 10*time.Second)
+		usecase.WarmCache(warmCtx, uc.CacheWarmers())
+		cancelWarm()
+	}
+
 	// TESTS
 	testConcurrentLogger()
 	testRaceCondition()
@@ -208,7 +472,7 @@ func testTaskQueue() {
 		fmt.Printf("Dequeued: %d\n", id)
 	}
 
-	fmt.Println("--- All tests passed! ---\n")
+	fmt.Println("--- All tests passed! ---")
 }
 
 func testTaskStream() {
@@ -230,7 +494,7 @@ func testTaskStream() {
 	}
 	stream.Close()
 
-	fmt.Println("--- Task Stream test passed! ---\n")
+	fmt.Println("--- Task Stream test passed! ---")
 }
 
 func testTaskRacer() {
@@ -242,7 +506,7 @@ func testTaskRacer() {
 		fmt.Printf("Race %d Winner: %s\n", i, winner)
 	}
 
-	fmt.Println("--- Task Racer test passed! ---\n")
+	fmt.Println("--- Task Racer test passed! ---")
 }
 
 func testFanIn() {
@@ -266,5 +530,5 @@ func testFanIn() {
 	}
 	fanIn.Close()
 
-	fmt.Println("--- Fan-In test passed! ---\n")
+	fmt.Println("--- Fan-In test passed! ---")
 }
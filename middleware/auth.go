@@ -33,3 +33,20 @@ func AuthMiddleware(authUsecase *usecase.AuthUsecase) func(http.Handler) http.Ha
 		})
 	}
 }
+
+// OptionalAuthMiddleware identifies the caller when a bearer token is sent
+// and lets anonymous requests through. A token that is sent but invalid is
+// still rejected, so clients notice an expired session.
+func OptionalAuthMiddleware(authUsecase *usecase.AuthUsecase) func(http.Handler) http.Handler {
+	required := AuthMiddleware(authUsecase)
+	return func(next http.Handler) http.Handler {
+		withUser := required(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			withUser.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/usecase"
+	"task-manager-api/validation"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// idempotencyLock is how long a pending key blocks duplicates before it is
+	// considered abandoned. It must outlast the slowest handler, including
+	// RetryWithBackoff delays.
+	idempotencyLock = 60 * time.Second
+	// idempotencyUploadLock covers uploads, which may stream for as long as
+	// the attachment handler's transfer timeout.
+	idempotencyUploadLock = 10 * time.Minute
+	// idempotencyUploadDrain is how much of an upload the middleware reads
+	// after its handler returns to finish fingerprinting it. Responses to
+	// uploads with more left over are not stored.
+	idempotencyUploadDrain  = 64 << 10
+	idempotencyWait         = 5 * time.Second
+	idempotencyPollInterval = 100 * time.Millisecond
+)
+
+// IdempotencyMiddleware makes POST, PUT, PATCH and DELETE requests carrying an
+// Idempotency-Key header safe to retry. The first request with a key runs
+// normally and its response is stored; retries with the same key and body get
+// the stored response replayed, while reusing the key with a different body
+// is rejected with 409 Conflict.
+//
+// Multipart uploads are streamed to their handler unread and must declare a
+// Content-Length. They are hashed as the handler reads them; until the first
+// upload completes, duplicates are compared by method, path and length only.
+//
+// A retry that arrives while the original is still running waits a few
+// seconds for it to finish, then gives up with 409.
+type IdempotencyMiddleware struct {
+	store repository.IdempotencyRepository
+	auth  *usecase.AuthUsecase
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]chan struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewIdempotencyMiddleware(store repository.IdempotencyRepository, auth *usecase.AuthUsecase, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		store:    store,
+		auth:     auth,
+		ttl:      ttl,
+		inFlight: make(map[string]chan struct{}),
+		stop:     make(chan struct{}),
+	}
+}
+
+func (m *IdempotencyMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isUnsafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			writeJSONError(w, http.StatusBadRequest, "ValidationError", "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		var body []byte
+		lock := idempotencyLock
+		upload := isUpload(r)
+		if upload {
+			if r.ContentLength < 0 {
+				writeJSONError(w, http.StatusLengthRequired, "LengthRequired",
+					"Uploads sent with an Idempotency-Key must have a Content-Length")
+				return
+			}
+			// A placeholder while the upload streams; execute stores the
+			// fingerprint of the full body.
+			body = []byte(fmt.Sprintf("upload of %d bytes", r.ContentLength))
+			lock = idempotencyUploadLock
+		} else {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(r.Body, validation.MaxBodyBytes+1))
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "ValidationError", "could not read request body")
+				return
+			}
+			if int64(len(body)) > validation.MaxBodyBytes {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, "PayloadTooLarge", (&domain.PayloadTooLargeError{Limit: validation.MaxBodyBytes}).Error())
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		now := time.Now()
+		record := domain.IdempotencyRecord{
+			Key:         key,
+			UserID:      m.userID(r),
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Fingerprint: fingerprint(r, body),
+			CreatedAt:   now,
+			LockedUntil: now.Add(lock),
+			ExpiresAt:   now.Add(m.ttl),
+		}
+
+		deadline := now.Add(idempotencyWait)
+		for {
+			existing, reserved, err := m.store.Reserve(record)
+			if err != nil {
+				log.Printf("[%s] idempotency reserve failed: %v", GetCorrelationID(r.Context()), err)
+				writeJSONError(w, http.StatusInternalServerError, "DatabaseError", "could not record Idempotency-Key")
+				return
+			}
+
+			if reserved {
+				m.execute(w, r, next, record)
+				return
+			}
+
+			if upload && existing.State == domain.IdempotencyCompleted {
+				m.replayUpload(w, r, existing)
+				return
+			}
+
+			if existing.Fingerprint != record.Fingerprint {
+				writeJSONError(w, http.StatusConflict, "IdempotencyKeyReused",
+					"Idempotency-Key was already used for a different request")
+				return
+			}
+
+			if existing.State == domain.IdempotencyCompleted {
+				replay(w, existing)
+				return
+			}
+
+			// The original request is still running, here or on another
+			// instance. Wait for it and loop: it either completed (replay) or
+			// was released after failing (we run it ourselves).
+			if !m.waitForInFlight(r, record, deadline) {
+				writeJSONError(w, http.StatusConflict, "IdempotencyKeyInProgress",
+					"A request with this Idempotency-Key is still being processed")
+				return
+			}
+		}
+	})
+}
+
+// execute runs the handler for a freshly reserved key and stores its response.
+// Server errors release the key so the client can retry.
+func (m *IdempotencyMiddleware) execute(w http.ResponseWriter, r *http.Request, next http.Handler, record domain.IdempotencyRecord) {
+	done := m.markInFlight(record)
+	defer m.clearInFlight(record, done)
+
+	recorder := &responseRecorder{header: http.Header{}, status: http.StatusOK, conn: http.NewResponseController(w)}
+
+	var uploadBody *hashingBody
+	if isUpload(r) {
+		uploadBody = newHashingBody(r)
+		r.Body = uploadBody
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			// The handler panicked; free the key before the panic propagates.
+			m.store.Release(record.Key, record.UserID)
+		}
+	}()
+
+	next.ServeHTTP(recorder, r)
+	completed = true
+
+	storable := recorder.status < http.StatusInternalServerError
+	if uploadBody != nil && storable {
+		record.Fingerprint, storable = uploadBody.fingerprint()
+	}
+
+	if !storable {
+		if err := m.store.Release(record.Key, record.UserID); err != nil {
+			log.Printf("[%s] idempotency release failed: %v", GetCorrelationID(r.Context()), err)
+		}
+	} else {
+		record.StatusCode = recorder.status
+		record.Headers = recorder.header.Clone()
+		record.Body = recorder.body.Bytes()
+		if err := m.store.Complete(record); err != nil {
+			log.Printf("[%s] idempotency complete failed: %v", GetCorrelationID(r.Context()), err)
+		}
+	}
+
+	for name, values := range recorder.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(recorder.status)
+	w.Write(recorder.body.Bytes())
+}
+
+// replayUpload answers a retried upload whose original has completed. Its
+// body has to be read in full to be compared with the original's.
+func (m *IdempotencyMiddleware) replayUpload(w http.ResponseWriter, r *http.Request, existing domain.IdempotencyRecord) {
+	http.NewResponseController(w).SetReadDeadline(time.Now().Add(idempotencyUploadLock))
+
+	body := newHashingBody(r)
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "ValidationError", "could not read request body")
+		return
+	}
+
+	if sum, _ := body.fingerprint(); sum != existing.Fingerprint {
+		writeJSONError(w, http.StatusConflict, "IdempotencyKeyReused",
+			"Idempotency-Key was already used for a different request")
+		return
+	}
+	replay(w, existing)
+}
+
+// waitForInFlight blocks until the in-flight request finishes locally, the
+// deadline passes or the client goes away. It polls the store as well so
+// duplicates handled by another instance are noticed. It reports whether the
+// caller should look at the key again.
+func (m *IdempotencyMiddleware) waitForInFlight(r *http.Request, record domain.IdempotencyRecord, deadline time.Time) bool {
+	m.mu.Lock()
+	done := m.inFlight[inFlightKey(record)]
+	m.mu.Unlock()
+
+	ticker := time.NewTicker(idempotencyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+
+		select {
+		case <-done:
+			return true
+		case <-r.Context().Done():
+			return false
+		case <-time.After(remaining):
+			return false
+		case <-ticker.C:
+			current, err := m.store.Get(record.Key, record.UserID)
+			if err != nil || current.State != domain.IdempotencyPending {
+				return true
+			}
+		}
+	}
+}
+
+func (m *IdempotencyMiddleware) markInFlight(record domain.IdempotencyRecord) chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	done := make(chan struct{})
+	m.inFlight[inFlightKey(record)] = done
+	return done
+}
+
+func (m *IdempotencyMiddleware) clearInFlight(record domain.IdempotencyRecord, done chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.inFlight[inFlightKey(record)] == done {
+		delete(m.inFlight, inFlightKey(record))
+	}
+	close(done)
+}
+
+// StartJanitor purges expired keys every interval until Close is called.
+func (m *IdempotencyMiddleware) StartJanitor(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				if _, err := m.store.DeleteExpired(time.Now()); err != nil {
+					log.Printf("idempotency janitor: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (m *IdempotencyMiddleware) Close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+}
+
+// userID scopes keys to the caller. Requests without a valid bearer token
+// share the anonymous scope (user 0).
+func (m *IdempotencyMiddleware) userID(r *http.Request) int {
+	if user, ok := r.Context().Value("user").(*domain.User); ok {
+		return user.ID
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if m.auth == nil || !strings.HasPrefix(authHeader, "Bearer ") {
+		return 0
+	}
+
+	user, err := m.auth.ValidateToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		return 0
+	}
+	return user.ID
+}
+
+func replay(w http.ResponseWriter, record domain.IdempotencyRecord) {
+	for name, values := range record.Headers {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	hash := newFingerprintHash(r)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func newFingerprintHash(r *http.Request) hash.Hash {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+"\n"+r.URL.RequestURI()+"\n")
+	return hash
+}
+
+// hashingBody fingerprints a request body as it is read.
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+	eof  bool
+}
+
+func newHashingBody(r *http.Request) *hashingBody {
+	return &hashingBody{ReadCloser: r.Body, hash: newFingerprintHash(r)}
+}
+
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+// fingerprint reads what is left of the body, up to idempotencyUploadDrain
+// bytes, and returns the fingerprint of the whole request. It reports false
+// if the body could not be read to the end.
+func (b *hashingBody) fingerprint() (string, bool) {
+	if !b.eof {
+		io.CopyN(io.Discard, b, idempotencyUploadDrain)
+	}
+	if !b.eof {
+		return "", false
+	}
+	return hex.EncodeToString(b.hash.Sum(nil)), true
+}
+
+// isUpload reports whether the request body is multipart/form-data, which
+// may be far larger than validation.MaxBodyBytes.
+func isUpload(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+func inFlightKey(record domain.IdempotencyRecord) string {
+	return fmt.Sprintf("%d:%s", record.UserID, record.Key)
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder buffers a response so it can be stored before it is sent.
+// Handlers can still move the connection's deadlines through it, but can't
+// flush it.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+	conn        *http.ResponseController
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+	rec.status = status
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) SetReadDeadline(deadline time.Time) error {
+	return rec.conn.SetReadDeadline(deadline)
+}
+
+func (rec *responseRecorder) SetWriteDeadline(deadline time.Time) error {
+	return rec.conn.SetWriteDeadline(deadline)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(dto.ErrorResponse{Error: code, Message: message})
+}
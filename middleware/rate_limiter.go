@@ -3,21 +3,37 @@ package middleware
 import "net/http"
 
 type RateLimiter struct {
-	queue chan struct{}
-	limit int
+	queue  chan struct{}
+	limit  int
+	exempt map[string]bool
 }
 
 func NewRateLimiter(limit int) *RateLimiter {
 	queue := make(chan struct{}, limit)
 
 	return &RateLimiter{
-		queue: queue,
-		limit: limit,
+		queue:  queue,
+		limit:  limit,
+		exempt: make(map[string]bool),
+	}
+}
+
+// Exempt lets requests for the given paths bypass the limiter. It is meant
+// for long-lived streams, each of which would hold a slot for as long as it
+// stays open. Call it before serving requests.
+func (rl *RateLimiter) Exempt(paths ...string) {
+	for _, path := range paths {
+		rl.exempt[path] = true
 	}
 }
 
 func (rl *RateLimiter) RateLimiterMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rl.exempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		rl.queue <- struct{}{}
 		next.ServeHTTP(w, r)
 		<-rl.queue
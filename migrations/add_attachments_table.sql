@@ -0,0 +1,19 @@
+-- Files attached to tasks. The bytes are in the blob store under blob_key;
+-- blobs no longer named by any row are garbage collected.
+CREATE TABLE IF NOT EXISTS attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    blob_key TEXT NOT NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    uploaded_by INTEGER NOT NULL,
+    created_at INTEGER NOT NULL -- unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_task ON attachments(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_attachments_blob ON attachments(blob_key);
+
+CREATE TRIGGER IF NOT EXISTS attachments_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM attachments WHERE task_id = old.id;
+END;
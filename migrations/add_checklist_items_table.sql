@@ -0,0 +1,15 @@
+-- Checklist items inside a task, shown in position order.
+CREATE TABLE IF NOT EXISTS checklist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    done INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL,
+    created_at INTEGER NOT NULL -- unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_checklist_items_task ON checklist_items(task_id, position);
+
+CREATE TRIGGER IF NOT EXISTS checklist_items_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM checklist_items WHERE task_id = old.id;
+END;
@@ -0,0 +1,31 @@
+-- Comments on tasks, threaded one level deep. Deleted comments keep their
+-- row with deleted_at set.
+CREATE TABLE IF NOT EXISTS comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    author_id INTEGER NOT NULL,
+    parent_id INTEGER, -- NULL for top-level comments
+    body TEXT NOT NULL,
+    created_at INTEGER NOT NULL, -- unix milliseconds
+    edited_at INTEGER,
+    deleted_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_task ON comments(task_id, created_at);
+
+-- Users @mentioned in a comment.
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_user ON comment_mentions(user_id);
+
+CREATE TRIGGER IF NOT EXISTS comments_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM comments WHERE task_id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comment_mentions_after_comment_delete AFTER DELETE ON comments BEGIN
+    DELETE FROM comment_mentions WHERE comment_id = old.id;
+END;
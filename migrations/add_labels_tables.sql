@@ -0,0 +1,24 @@
+-- Labels are owned by a user and optionally shared with everyone. Tasks and
+-- labels are linked many-to-many through task_labels.
+CREATE TABLE IF NOT EXISTS labels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL,
+    shared INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL -- unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_labels_user ON labels(user_id);
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id INTEGER NOT NULL,
+    label_id INTEGER NOT NULL,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label ON task_labels(label_id);
+
+CREATE TRIGGER IF NOT EXISTS task_labels_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM task_labels WHERE task_id = old.id;
+END;
@@ -0,0 +1,21 @@
+-- In-app notifications and per-user delivery settings. A reminder creates
+-- at most one notification, even if its delivery is retried.
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    task_id INTEGER NOT NULL,
+    reminder_id INTEGER UNIQUE,
+    message TEXT NOT NULL,
+    created_at INTEGER NOT NULL, -- unix milliseconds
+    read_at INTEGER -- unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id INTEGER PRIMARY KEY,
+    timezone TEXT NOT NULL,
+    quiet_start TEXT NOT NULL DEFAULT '',
+    quiet_end TEXT NOT NULL DEFAULT '',
+    webhook_url TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,26 @@
+-- Recurring tasks. A row in recurrences makes its task a template;
+-- recurrence_occurrences records every occurrence already turned into a
+-- task, so generation is idempotent across restarts.
+CREATE TABLE IF NOT EXISTS recurrences (
+    task_id INTEGER PRIMARY KEY,
+    rule TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    starts_at INTEGER NOT NULL, -- unix milliseconds
+    mode TEXT NOT NULL,
+    created_at INTEGER NOT NULL -- unix milliseconds
+);
+
+CREATE TABLE IF NOT EXISTS recurrence_occurrences (
+    template_id INTEGER NOT NULL,
+    occurs_at INTEGER NOT NULL, -- unix milliseconds
+    task_id INTEGER, -- NULL once the generated task is deleted
+    PRIMARY KEY (template_id, occurs_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurrence_occurrences_task ON recurrence_occurrences(task_id);
+
+CREATE TRIGGER IF NOT EXISTS recurrences_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM recurrences WHERE task_id = old.id;
+    DELETE FROM recurrence_occurrences WHERE template_id = old.id;
+    UPDATE recurrence_occurrences SET task_id = NULL WHERE task_id = old.id;
+END;
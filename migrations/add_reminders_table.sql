@@ -0,0 +1,28 @@
+-- Reminders fire at remind_at, or offset_minutes from their task's due date.
+-- A scheduler claims due reminders by setting claimed_by and claim_expires,
+-- so with several API instances each reminder is handled by one of them; a
+-- claim left by a crashed instance expires and is taken over.
+CREATE TABLE IF NOT EXISTS reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    remind_at INTEGER, -- unix milliseconds
+    offset_minutes INTEGER,
+    channel TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    deliver_after INTEGER, -- unix milliseconds
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    claimed_by TEXT,
+    claim_expires INTEGER, -- unix milliseconds
+    sent_at INTEGER, -- unix milliseconds
+    created_at INTEGER NOT NULL, -- unix milliseconds
+    CHECK ((remind_at IS NULL) <> (offset_minutes IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(status, task_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_user ON reminders(user_id, task_id);
+
+CREATE TRIGGER IF NOT EXISTS reminders_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM reminders WHERE task_id = old.id;
+END;
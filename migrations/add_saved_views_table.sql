@@ -0,0 +1,15 @@
+-- Named task filters, per user and optionally shared with everyone.
+CREATE TABLE IF NOT EXISTS saved_views (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    sort TEXT NOT NULL DEFAULT '',
+    columns TEXT NOT NULL DEFAULT '[]', -- JSON array of column names
+    shared INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL, -- unix milliseconds
+    updated_at INTEGER NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_views_shared ON saved_views(shared);
@@ -0,0 +1,14 @@
+-- Users assigned to tasks, many per task.
+CREATE TABLE IF NOT EXISTS task_assignees (
+    task_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    assigned_by INTEGER NOT NULL,
+    created_at INTEGER NOT NULL, -- unix milliseconds
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_assignees_user ON task_assignees(user_id);
+
+CREATE TRIGGER IF NOT EXISTS task_assignees_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM task_assignees WHERE task_id = old.id;
+END;
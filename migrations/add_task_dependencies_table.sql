@@ -0,0 +1,15 @@
+-- "task_id is blocked by blocked_by_id". The edges form a DAG; cycles are
+-- refused when a dependency is added.
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id INTEGER NOT NULL,
+    blocked_by_id INTEGER NOT NULL,
+    created_at INTEGER NOT NULL, -- unix milliseconds
+    PRIMARY KEY (task_id, blocked_by_id),
+    CHECK (task_id <> blocked_by_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocker ON task_dependencies(blocked_by_id);
+
+CREATE TRIGGER IF NOT EXISTS task_dependencies_after_task_delete AFTER DELETE ON tasks BEGIN
+    DELETE FROM task_dependencies WHERE task_id = old.id OR blocked_by_id = old.id;
+END;
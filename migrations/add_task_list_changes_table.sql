@@ -0,0 +1,15 @@
+-- Deleting a task changes the task list without leaving an updated_at behind,
+-- so the list's Last-Modified also takes the time of the last delete, kept in
+-- this single row.
+CREATE TABLE IF NOT EXISTS task_list_changes (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    deleted_at INTEGER NOT NULL -- unix milliseconds, 0 before the first delete
+);
+
+INSERT OR IGNORE INTO task_list_changes (id, deleted_at) VALUES (1, 0);
+
+CREATE TRIGGER IF NOT EXISTS task_list_changes_after_task_delete AFTER DELETE ON tasks BEGIN
+    UPDATE task_list_changes
+    SET deleted_at = CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER)
+    WHERE id = 1;
+END;
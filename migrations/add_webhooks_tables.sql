@@ -0,0 +1,56 @@
+-- webhook_deliveries is the outbox: events are queued there and a
+-- dispatcher claims due rows (claimed_by, claim_expires) and sends them, so
+-- queued events survive restarts and each is sent by one instance at a time.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL, -- comma-separated event types
+    active INTEGER NOT NULL DEFAULT 1,
+    failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at INTEGER, -- unix milliseconds
+    created_at INTEGER NOT NULL, -- unix milliseconds
+    updated_at INTEGER NOT NULL -- unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_owner ON webhooks(owner_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER, -- unix milliseconds; NULL once finished
+    response_code INTEGER,
+    last_error TEXT NOT NULL DEFAULT '',
+    redelivery_of INTEGER,
+    claimed_by TEXT,
+    claim_expires INTEGER, -- unix milliseconds
+    delivered_at INTEGER, -- unix milliseconds
+    created_at INTEGER NOT NULL -- unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delivery_id INTEGER NOT NULL,
+    response_code INTEGER,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL,
+    attempted_at INTEGER NOT NULL -- unix milliseconds
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery ON webhook_attempts(delivery_id);
+
+CREATE TRIGGER IF NOT EXISTS webhooks_after_delete AFTER DELETE ON webhooks BEGIN
+    DELETE FROM webhook_deliveries WHERE webhook_id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS webhook_deliveries_after_delete AFTER DELETE ON webhook_deliveries BEGIN
+    DELETE FROM webhook_attempts WHERE delivery_id = old.id;
+END;
@@ -3,7 +3,14 @@ CREATE TABLE IF NOT EXISTS tasks (
     title TEXT NOT NULL,
     description TEXT NOT NULL,
     status TEXT NOT NULL,
-    priority TEXT NOT NULL
+    priority TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    updated_at INTEGER NOT NULL DEFAULT 0,
+    due_date INTEGER,
+    project_id INTEGER,
+    parent_id INTEGER,
+    auto_complete INTEGER NOT NULL DEFAULT 0,
+    estimate_minutes INTEGER NOT NULL DEFAULT 0
 );
 
 -- Insert initial data
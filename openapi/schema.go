@@ -0,0 +1,213 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"task-manager-api/validation"
+	"time"
+)
+
+// SchemaRegistry derives JSON schemas from Go types. Named structs are
+// emitted once under components/schemas and referenced with $ref.
+type SchemaRegistry struct {
+	schemas map[string]*Schema
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]*Schema)}
+}
+
+// Schemas returns every named schema registered so far.
+func (r *SchemaRegistry) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+// SchemaFor returns the schema of the value's type, registering any named
+// structs it contains.
+func (r *SchemaRegistry) SchemaFor(v interface{}) *Schema {
+	return r.schemaForType(reflect.TypeOf(v))
+}
+
+func (r *SchemaRegistry) schemaForType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name := t.Name()
+		if _, exists := r.schemas[name]; !exists {
+			// Reserve the name first so self-referencing types terminate.
+			r.schemas[name] = &Schema{}
+			*r.schemas[name] = *r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaForType(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		// interface{} and anything else accepts any JSON value.
+		return &Schema{}
+	}
+}
+
+func (r *SchemaRegistry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	request := isRequest(t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := r.structSchema(indirect(field.Type))
+			for propName, prop := range embedded.Properties {
+				schema.Properties[propName] = prop
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		prop := r.schemaForType(field.Type)
+		required := applyValidateTag(prop, field.Tag.Get("validate"))
+		schema.Properties[name] = prop
+
+		// Request DTOs declare presence with `validate:"required"`; response
+		// fields without omitempty are always present.
+		if required || (!request && !omitempty) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// isRequest reports whether a struct carries validation rules, which marks it
+// as a request DTO whose presence requirements come from `validate` tags.
+func isRequest(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("validate") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyValidateTag maps validation rules onto schema keywords and reports
+// whether the field is required.
+func applyValidateTag(schema *Schema, tag string) bool {
+	if tag == "" || schema.Ref != "" {
+		return strings.Contains(tag, "required")
+	}
+
+	required := false
+	for _, part := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "datetime":
+			if param == "" {
+				schema.Format = "date-time"
+			} else if param == "2006-01-02" {
+				schema.Format = "date"
+			}
+		case "oneof":
+			for _, option := range validation.SplitOptions(param) {
+				if schema.Type == "integer" {
+					if n, err := strconv.Atoi(option); err == nil {
+						schema.Enum = append(schema.Enum, n)
+						continue
+					}
+				}
+				schema.Enum = append(schema.Enum, option)
+			}
+		case "min", "max", "len":
+			applyBound(schema, name, param)
+		}
+	}
+	return required
+}
+
+func applyBound(schema *Schema, rule, param string) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	f := float64(n)
+
+	switch schema.Type {
+	case "string":
+		if rule == "min" || rule == "len" {
+			schema.MinLength = &n
+		}
+		if rule == "max" || rule == "len" {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if rule == "min" || rule == "len" {
+			schema.MinItems = &n
+		}
+		if rule == "max" || rule == "len" {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		if rule == "min" || rule == "len" {
+			schema.Minimum = &f
+		}
+		if rule == "max" || rule == "len" {
+			schema.Maximum = &f
+		}
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(opts, "omitempty")
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,256 @@
+// Package recurrence parses the subset of RFC 5545 recurrence rules tasks
+// can repeat on and expands them into occurrence times:
+//
+//	FREQ=DAILY                          every day
+//	FREQ=WEEKLY;BYDAY=MO,WE,FR          three days a week
+//	FREQ=WEEKLY;INTERVAL=2;BYDAY=MO     every other Monday
+//	FREQ=MONTHLY;BYDAY=-1FR             the last Friday of each month
+//	FREQ=MONTHLY;COUNT=6                six months, on DTSTART's day
+//	FREQ=DAILY;UNTIL=20261231T170000Z   daily until the end of 2026
+//
+// FREQ is DAILY, WEEKLY or MONTHLY. INTERVAL, COUNT, UNTIL and BYDAY are
+// supported; other parts are rejected. Weeks start on Monday.
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// WeekdayNum is one BYDAY entry. N picks the Nth such weekday of the month,
+// counting from the end when negative; 0 means every one. N is only
+// allowed with FREQ=MONTHLY.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is a parsed RRULE. Count and Until are zero when the rule repeats
+// forever; at most one of them is set.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	Count    int
+	Until    Until
+	ByDay    []WeekdayNum
+}
+
+// Until is the UNTIL bound. A date-time in UTC ("20261231T170000Z") is an
+// instant; a date ("20261231") or a local date-time ("20261231T170000")
+// is read in the schedule's time zone, a date covering the whole day.
+type Until struct {
+	value   string
+	year    int
+	month   time.Month
+	day     int
+	clock   time.Duration // time of day; -1 for a date
+	utc     bool
+	present bool
+}
+
+// IsZero reports whether the rule has no UNTIL.
+func (u Until) IsZero() bool {
+	return !u.present
+}
+
+// In returns the last instant the bound allows in loc.
+func (u Until) In(loc *time.Location) time.Time {
+	if u.utc {
+		loc = time.UTC
+	}
+	if u.clock < 0 {
+		return time.Date(u.year, u.month, u.day+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+	}
+	return time.Date(u.year, u.month, u.day, 0, 0, 0, 0, loc).Add(u.clock)
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// maxRuleLength bounds the input; no supported rule comes close.
+const maxRuleLength = 500
+
+// Parse reads an RRULE value such as "FREQ=WEEKLY;BYDAY=MO". A leading
+// "RRULE:" is accepted. Errors are *domain.ValidationError on field "rule".
+func Parse(value string) (Rule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > maxRuleLength {
+		return Rule{}, ruleError("must be at most %d characters", maxRuleLength)
+	}
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+	if value == "" {
+		return Rule{}, ruleError("is required")
+	}
+
+	rule := Rule{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ";") {
+		name, arg, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		arg = strings.ToUpper(strings.TrimSpace(arg))
+		if !ok || name == "" || arg == "" {
+			return Rule{}, ruleError("%q is not a NAME=VALUE part", part)
+		}
+		if seen[name] {
+			return Rule{}, ruleError("%s is given more than once", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq, err = parseFreq(arg)
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(name, arg)
+		case "COUNT":
+			rule.Count, err = parsePositive(name, arg)
+		case "UNTIL":
+			rule.Until, err = parseUntil(arg)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(arg)
+		case "WKST":
+			if arg != "MO" {
+				err = ruleError("only WKST=MO is supported")
+			}
+		default:
+			err = ruleError("%s is not supported; use FREQ, INTERVAL, COUNT, UNTIL and BYDAY", name)
+		}
+		if err != nil {
+			return Rule{}, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, ruleError("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, ruleError("COUNT and UNTIL cannot both be given")
+	}
+	if rule.Freq != Monthly {
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return Rule{}, ruleError("numbered BYDAY values such as 1MO need FREQ=MONTHLY")
+			}
+		}
+	}
+	return rule, nil
+}
+
+// String formats the rule in the canonical form Parse accepts.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.value)
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToUpper(day.Day.String()[:2])
+			if day.N != 0 {
+				days[i] = strconv.Itoa(day.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+func parseFreq(arg string) (Frequency, error) {
+	switch freq := Frequency(arg); freq {
+	case Daily, Weekly, Monthly:
+		return freq, nil
+	}
+	return "", ruleError("FREQ must be DAILY, WEEKLY or MONTHLY")
+}
+
+func parsePositive(name, arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > 1000 {
+		return 0, ruleError("%s must be a whole number from 1 to 1000", name)
+	}
+	return n, nil
+}
+
+func parseUntil(arg string) (Until, error) {
+	until := Until{value: arg, clock: -1, present: true}
+
+	date, clock, hasClock := strings.Cut(arg, "T")
+	if hasClock {
+		until.utc = strings.HasSuffix(clock, "Z")
+		clock = strings.TrimSuffix(clock, "Z")
+	}
+
+	d, err := time.Parse("20060102", date)
+	if err != nil || len(date) != 8 {
+		return Until{}, ruleError("UNTIL must be a date such as 20261231 or a date-time such as 20261231T170000Z")
+	}
+	until.year, until.month, until.day = d.Date()
+
+	if hasClock {
+		t, err := time.Parse("150405", clock)
+		if err != nil || len(clock) != 6 {
+			return Until{}, ruleError("UNTIL must be a date such as 20261231 or a date-time such as 20261231T170000Z")
+		}
+		until.clock = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	}
+	return until, nil
+}
+
+func parseByDay(arg string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	seen := make(map[WeekdayNum]bool)
+
+	for _, item := range strings.Split(arg, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, ruleError("BYDAY value %q is not a weekday such as MO or -1FR", item)
+		}
+
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, ruleError("BYDAY value %q is not a weekday such as MO or -1FR", item)
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, ruleError("BYDAY value %q must be numbered from -5 to 5, without 0", item)
+			}
+		}
+
+		entry := WeekdayNum{N: n, Day: day}
+		if !seen[entry] {
+			seen[entry] = true
+			days = append(days, entry)
+		}
+	}
+	return days, nil
+}
+
+func ruleError(format string, args ...interface{}) error {
+	return &domain.ValidationError{Field: "rule", Message: fmt.Sprintf(format, args...)}
+}
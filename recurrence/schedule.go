@@ -0,0 +1,183 @@
+package recurrence
+
+import (
+	"slices"
+	"time"
+)
+
+// Schedule is a rule anchored at its first occurrence, DTSTART. Occurrences
+// are computed in Start's location, so a 09:00 occurrence stays at 09:00
+// local time across daylight-saving changes. Occurrences before Start are
+// never produced, even if the rule would match them.
+type Schedule struct {
+	Rule  Rule
+	Start time.Time
+}
+
+// maxPeriods bounds how many days, weeks or months are scanned, so a rule
+// that rarely or never matches cannot loop forever.
+const maxPeriods = 100000
+
+// Next returns the first occurrence after t, or false when the series has
+// ended.
+func (s Schedule) Next(t time.Time) (time.Time, bool) {
+	next := s.After(t, 1)
+	if len(next) == 0 {
+		return time.Time{}, false
+	}
+	return next[0], true
+}
+
+// After returns up to n occurrences after t, in order.
+func (s Schedule) After(t time.Time, n int) []time.Time {
+	var result []time.Time
+	s.each(func(occurrence time.Time) bool {
+		if occurrence.After(t) {
+			result = append(result, occurrence)
+		}
+		return len(result) < n
+	})
+	return result
+}
+
+// Latest returns the last occurrence after since and no later than t, or
+// false when there is none.
+func (s Schedule) Latest(since, t time.Time) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	s.each(func(occurrence time.Time) bool {
+		if occurrence.After(t) {
+			return false
+		}
+		if occurrence.After(since) {
+			latest, found = occurrence, true
+		}
+		return true
+	})
+	return latest, found
+}
+
+// each calls fn with every occurrence in order until fn returns false or
+// the series ends.
+func (s Schedule) each(fn func(time.Time) bool) {
+	rule := s.Rule
+	interval := max(rule.Interval, 1)
+	loc := s.Start.Location()
+
+	var until time.Time
+	if !rule.Until.IsZero() {
+		until = rule.Until.In(loc)
+	}
+
+	count := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, occurrence := range s.period(period * interval) {
+			if occurrence.Before(s.Start) {
+				continue
+			}
+			if !until.IsZero() && occurrence.After(until) {
+				return
+			}
+			if !fn(occurrence) {
+				return
+			}
+			count++
+			if rule.Count > 0 && count >= rule.Count {
+				return
+			}
+		}
+	}
+}
+
+// period returns the rule's candidates in the offset-th day, week or month
+// after the one holding Start, in order.
+func (s Schedule) period(offset int) []time.Time {
+	year, month, day := s.Start.Date()
+	hour, minute, second := s.Start.Clock()
+	loc := s.Start.Location()
+
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, minute, second, 0, loc)
+	}
+
+	var days []int // days of month, relative to the period's month
+
+	switch s.Rule.Freq {
+	case Daily:
+		date := at(year, month, day+offset)
+		if len(s.Rule.ByDay) == 0 || s.matchesWeekday(date.Weekday()) {
+			return []time.Time{date}
+		}
+		return nil
+
+	case Weekly:
+		monday := day - mondayOffset(s.Start.Weekday()) + 7*offset
+		if len(s.Rule.ByDay) == 0 {
+			return []time.Time{at(year, month, monday+mondayOffset(s.Start.Weekday()))}
+		}
+		for _, weekday := range s.Rule.ByDay {
+			days = append(days, monday+mondayOffset(weekday.Day))
+		}
+
+	case Monthly:
+		first := time.Date(year, month+time.Month(offset), 1, 0, 0, 0, 0, loc)
+		year, month = first.Year(), first.Month()
+		length := daysIn(year, month)
+
+		if len(s.Rule.ByDay) == 0 {
+			if day > length {
+				return nil // such as the 31st in a 30-day month
+			}
+			return []time.Time{at(year, month, day)}
+		}
+		for _, weekday := range s.Rule.ByDay {
+			days = append(days, nthWeekdays(first.Weekday(), length, weekday)...)
+		}
+	}
+
+	slices.Sort(days)
+	days = slices.Compact(days)
+
+	occurrences := make([]time.Time, len(days))
+	for i, d := range days {
+		occurrences[i] = at(year, month, d)
+	}
+	return occurrences
+}
+
+func (s Schedule) matchesWeekday(weekday time.Weekday) bool {
+	for _, day := range s.Rule.ByDay {
+		if day.Day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// nthWeekdays returns the days of a month matching a BYDAY entry, given the
+// weekday of the 1st and the month's length.
+func nthWeekdays(first time.Weekday, length int, weekday WeekdayNum) []int {
+	var all []int
+	for d := 1 + (int(weekday.Day)-int(first)+7)%7; d <= length; d += 7 {
+		all = append(all, d)
+	}
+
+	switch {
+	case weekday.N == 0:
+		return all
+	case weekday.N > 0 && weekday.N <= len(all):
+		return []int{all[weekday.N-1]}
+	case weekday.N < 0 && -weekday.N <= len(all):
+		return []int{all[len(all)+weekday.N]}
+	}
+	return nil
+}
+
+// mondayOffset is how many days weekday falls after Monday.
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "task-manager-api/domain"
+
+// AssigneeRepository links users to tasks. Add and Remove report whether
+// anything changed, and bump the task's version when it did.
+type AssigneeRepository interface {
+	Add(assignee domain.Assignee) (bool, error)
+	Remove(taskID, userID int) (bool, error)
+	Close() error
+}
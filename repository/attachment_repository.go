@@ -0,0 +1,15 @@
+package repository
+
+import "task-manager-api/domain"
+
+// AttachmentRepository stores attachment metadata; the content is in a
+// blobstore.BlobStore.
+type AttachmentRepository interface {
+	Create(attachment domain.Attachment) (domain.Attachment, error)
+	GetByID(id int) (domain.Attachment, error)
+	ListByTask(taskID int) ([]domain.Attachment, error)
+	Delete(id int) error
+	// BlobReferenced reports whether any attachment still uses the blob.
+	BlobReferenced(key string) (bool, error)
+	Close() error
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "task-manager-api/domain"
+
+// ChecklistRepository stores the checklist items inside tasks. Every change
+// bumps the owning task's version, since items are part of the task's body.
+type ChecklistRepository interface {
+	Add(item domain.ChecklistItem) (domain.ChecklistItem, error)
+	Update(item domain.ChecklistItem) error
+	Delete(taskID, itemID int) error
+	Close() error
+}
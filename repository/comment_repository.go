@@ -0,0 +1,18 @@
+package repository
+
+import "task-manager-api/domain"
+
+// CommentRepository stores task comments and their mentions. Create and
+// Delete bump the task's version, since they change its comment count.
+type CommentRepository interface {
+	Create(comment domain.Comment) (domain.Comment, error)
+	GetByID(id int) (domain.Comment, error)
+	// ListByTask returns a task's comments, deleted ones included, oldest
+	// first.
+	ListByTask(taskID int) ([]domain.Comment, error)
+	// Update replaces the body and mentions and sets EditedAt.
+	Update(comment domain.Comment) (domain.Comment, error)
+	// Delete soft-deletes a comment.
+	Delete(id int) error
+	Close() error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import "task-manager-api/domain"
+
+// DependencyRepository stores which tasks block which. Add refuses an edge
+// that would close a cycle with a ValidationError naming the cycle. Add and
+// Remove report whether anything changed, and bump the blocked task's
+// version when it did.
+type DependencyRepository interface {
+	Add(dependency domain.Dependency) (bool, error)
+	Remove(taskID, blockedByID int) (bool, error)
+	// Graph returns every dependency among the tasks taskID transitively
+	// depends on, and among the tasks that transitively depend on it.
+	Graph(taskID int) ([]domain.Dependency, error)
+	Close() error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"task-manager-api/domain"
+	"time"
+)
+
+type IdempotencyRepository interface {
+	// Reserve stores record as pending unless a live record already exists for
+	// the same key and user, in which case that record is returned instead.
+	Reserve(record domain.IdempotencyRecord) (existing domain.IdempotencyRecord, reserved bool, err error)
+	Get(key string, userID int) (domain.IdempotencyRecord, error)
+	// Complete stores the response, and the final fingerprint, of a reserved key.
+	Complete(record domain.IdempotencyRecord) error
+	Release(key string, userID int) error
+	DeleteExpired(now time.Time) (int64, error)
+	Close() error
+}
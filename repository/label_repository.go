@@ -0,0 +1,20 @@
+package repository
+
+import "task-manager-api/domain"
+
+// LabelRepository stores labels and their task associations. Operations
+// that change what tasks display return the IDs of the tasks affected, and
+// bump those tasks' version and updated_at in the same transaction.
+type LabelRepository interface {
+	Create(label domain.Label) (domain.Label, error)
+	GetByID(id int) (domain.Label, error)
+	// ListVisible returns the user's labels and labels shared by others.
+	ListVisible(userID int) ([]domain.Label, error)
+	Update(label domain.Label) (domain.Label, []int, error)
+	Delete(id int) ([]int, error)
+	// Merge moves every task from source to target and deletes source.
+	Merge(sourceID, targetID int) ([]int, error)
+	AddToTask(taskID, labelID int) error
+	RemoveFromTask(taskID, labelID int) error
+	Close() error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import "task-manager-api/domain"
+
+type NotificationRepository interface {
+	// Create stores a notification. A second notification for the same
+	// reminder is ignored and the first one returned.
+	Create(notification domain.Notification) (domain.Notification, error)
+	ListByUser(userID int, unreadOnly bool) ([]domain.Notification, error)
+	MarkRead(id, userID int) (domain.Notification, error)
+	// GetSettings returns the user's settings, or the defaults (UTC, no
+	// quiet hours) if they haven't saved any.
+	GetSettings(userID int) (domain.NotificationSettings, error)
+	SaveSettings(settings domain.NotificationSettings) (domain.NotificationSettings, error)
+	Close() error
+}
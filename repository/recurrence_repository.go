@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"task-manager-api/domain"
+	"time"
+)
+
+type RecurrenceRepository interface {
+	// Set creates or replaces the task's recurrence. Occurrences already
+	// created are kept, so a new rule continues after the last of them.
+	Set(recurrence domain.Recurrence) (domain.Recurrence, error)
+	Get(taskID int) (domain.Recurrence, error)
+	List() ([]domain.Recurrence, error)
+	Delete(taskID int) error
+	// Materialize records the template's occurrence at occursAt and creates
+	// task for it, copying the template's labels and assignees, in one
+	// transaction. If the occurrence was already recorded it does nothing
+	// and returns false.
+	Materialize(templateID int, occursAt time.Time, task domain.Task) (domain.Task, bool, error)
+	Close() error
+}
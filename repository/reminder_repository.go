@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"task-manager-api/domain"
+	"time"
+)
+
+type ReminderRepository interface {
+	Create(reminder domain.Reminder) (domain.Reminder, error)
+	GetByID(id int) (domain.Reminder, error)
+	ListByTask(taskID, userID int) ([]domain.Reminder, error)
+	Delete(id int) error
+	// Claim leases up to limit pending reminders due at now to owner until
+	// now+lease and returns them. A reminder claimed by one owner is not
+	// returned to another until the lease expires.
+	Claim(owner string, now time.Time, lease time.Duration, limit int) ([]domain.Reminder, error)
+	// Postpone releases owner's claim and holds the reminder back until
+	// until, recording a failed attempt when errMsg isn't empty.
+	Postpone(id int, owner string, until time.Time, errMsg string) error
+	// Finish moves a reminder claimed by owner out of pending.
+	Finish(id int, owner, status, errMsg string) error
+	Close() error
+}
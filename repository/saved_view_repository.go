@@ -0,0 +1,14 @@
+package repository
+
+import "task-manager-api/domain"
+
+type SavedViewRepository interface {
+	Create(view domain.SavedView) (domain.SavedView, error)
+	GetByID(id int) (domain.SavedView, error)
+	// ListVisible returns the user's own views followed by views other
+	// users have shared.
+	ListVisible(userID int) ([]domain.SavedView, error)
+	Update(view domain.SavedView) (domain.SavedView, error)
+	Delete(id int) error
+	Close() error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"database/sql"
+	"task-manager-api/domain"
+)
+
+// addColumnIfMissing adds a column to an existing SQLite table. SQLite has no
+// ADD COLUMN IF NOT EXISTS, so the table's columns are checked first.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "inspect " + table, Err: err}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return &domain.DatabaseError{Operation: "inspect " + table, Err: err}
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "inspect " + table, Err: err}
+	}
+
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "add column " + table + "." + column, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteAssigneeRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAssigneeRepository(dbPath string) (*SQLiteAssigneeRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_task_assignees_table.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteAssigneeRepository{db: db}, nil
+}
+
+func (r *SQLiteAssigneeRepository) Add(assignee domain.Assignee) (bool, error) {
+	var added bool
+	err := inTx(r.db, "assign task", func(tx *sql.Tx) error {
+		result, err := tx.Exec(`INSERT OR IGNORE INTO task_assignees (task_id, user_id, assigned_by, created_at) VALUES (?, ?, ?, ?)`,
+			assignee.TaskID, assignee.UserID, assignee.AssignedBy, time.Now().UnixMilli())
+		if err != nil {
+			return &domain.DatabaseError{Operation: "assign task", Err: err}
+		}
+		added, err = changedRows(result)
+		if err != nil {
+			return err
+		}
+		return touchTaskIfChanged(tx, result, assignee.TaskID)
+	})
+	return added, err
+}
+
+func (r *SQLiteAssigneeRepository) Remove(taskID, userID int) (bool, error) {
+	var removed bool
+	err := inTx(r.db, "unassign task", func(tx *sql.Tx) error {
+		result, err := tx.Exec("DELETE FROM task_assignees WHERE task_id = ? AND user_id = ?", taskID, userID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "unassign task", Err: err}
+		}
+		removed, err = changedRows(result)
+		if err != nil {
+			return err
+		}
+		return touchTaskIfChanged(tx, result, taskID)
+	})
+	return removed, err
+}
+
+func changedRows(result sql.Result) (bool, error) {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, &domain.DatabaseError{Operation: "rows affected", Err: err}
+	}
+	return n > 0, nil
+}
+
+// assigneesForTasks loads the assignees of the given tasks, keyed by task ID.
+func assigneesForTasks(q queryer, taskIDs []int) (map[int][]domain.Assignee, error) {
+	assignees := make(map[int][]domain.Assignee)
+	if len(taskIDs) == 0 {
+		return assignees, nil
+	}
+
+	args := make([]interface{}, len(taskIDs))
+	for i, id := range taskIDs {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(taskIDs)), ", ")
+
+	rows, err := q.Query(`SELECT a.task_id, a.user_id, COALESCE(u.email, ''), a.assigned_by, a.created_at
+		FROM task_assignees a LEFT JOIN users u ON u.id = a.user_id
+		WHERE a.task_id IN (`+placeholders+`)
+		ORDER BY a.created_at, a.user_id`, args...)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "load task assignees", Err: err}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var assignee domain.Assignee
+		var assignedAt int64
+		if err := rows.Scan(&assignee.TaskID, &assignee.UserID, &assignee.Email, &assignee.AssignedBy, &assignedAt); err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan task assignee", Err: err}
+		}
+		assignee.AssignedAt = time.UnixMilli(assignedAt).UTC()
+		assignees[assignee.TaskID] = append(assignees[assignee.TaskID], assignee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate task assignees", Err: err}
+	}
+	return assignees, nil
+}
+
+// attachAssignees fills in Assignees on each task.
+func attachAssignees(q queryer, tasks []domain.Task) error {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	assignees, err := assigneesForTasks(q, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		tasks[i].Assignees = assignees[tasks[i].ID]
+	}
+	return nil
+}
+
+func (r *SQLiteAssigneeRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteAttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAttachmentRepository(dbPath string) (*SQLiteAttachmentRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_attachments_table.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteAttachmentRepository{db: db}, nil
+}
+
+const attachmentColumns = "id, task_id, blob_key, filename, content_type, size, uploaded_by, created_at"
+
+func scanAttachment(row rowScanner) (domain.Attachment, error) {
+	var attachment domain.Attachment
+	var createdAt int64
+
+	err := row.Scan(&attachment.ID, &attachment.TaskID, &attachment.BlobKey, &attachment.Filename, &attachment.ContentType,
+		&attachment.Size, &attachment.UploadedBy, &createdAt)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+
+	attachment.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return attachment, nil
+}
+
+func (r *SQLiteAttachmentRepository) Create(attachment domain.Attachment) (domain.Attachment, error) {
+	attachment.CreatedAt = time.UnixMilli(time.Now().UnixMilli()).UTC()
+
+	result, err := r.db.Exec(`INSERT INTO attachments (task_id, blob_key, filename, content_type, size, uploaded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		attachment.TaskID, attachment.BlobKey, attachment.Filename, attachment.ContentType, attachment.Size,
+		attachment.UploadedBy, attachment.CreatedAt.UnixMilli())
+	if err != nil {
+		return domain.Attachment{}, &domain.DatabaseError{Operation: "insert attachment", Err: err}
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domain.Attachment{}, &domain.DatabaseError{Operation: "get last insert id", Err: err}
+	}
+
+	attachment.ID = int(id)
+	return attachment, nil
+}
+
+func (r *SQLiteAttachmentRepository) GetByID(id int) (domain.Attachment, error) {
+	attachment, err := scanAttachment(r.db.QueryRow("SELECT "+attachmentColumns+" FROM attachments WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Attachment{}, &domain.NotFoundError{Resource: "Attachment", ID: id}
+		}
+		return domain.Attachment{}, &domain.DatabaseError{Operation: "get attachment", Err: err}
+	}
+	return attachment, nil
+}
+
+func (r *SQLiteAttachmentRepository) ListByTask(taskID int) ([]domain.Attachment, error) {
+	rows, err := r.db.Query("SELECT "+attachmentColumns+" FROM attachments WHERE task_id = ? ORDER BY created_at, id", taskID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list attachments", Err: err}
+	}
+	defer rows.Close()
+
+	attachments := []domain.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan attachment", Err: err}
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate attachments", Err: err}
+	}
+	return attachments, nil
+}
+
+func (r *SQLiteAttachmentRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM attachments WHERE id = ?", id)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "delete attachment", Err: err}
+	}
+	return requireAffectedResource(result, "Attachment", id)
+}
+
+func (r *SQLiteAttachmentRepository) BlobReferenced(key string) (bool, error) {
+	var referenced bool
+	err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM attachments WHERE blob_key = ?)", key).Scan(&referenced)
+	if err != nil {
+		return false, &domain.DatabaseError{Operation: "check blob references", Err: err}
+	}
+	return referenced, nil
+}
+
+func (r *SQLiteAttachmentRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteChecklistRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteChecklistRepository(dbPath string) (*SQLiteChecklistRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_checklist_items_table.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteChecklistRepository{db: db}, nil
+}
+
+// Add appends the item to the end of its task's checklist.
+func (r *SQLiteChecklistRepository) Add(item domain.ChecklistItem) (domain.ChecklistItem, error) {
+	err := inTx(r.db, "add checklist item", func(tx *sql.Tx) error {
+		item.CreatedAt = time.Now().UTC()
+
+		err := tx.QueryRow(`INSERT INTO checklist_items (task_id, text, done, position, created_at)
+			VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM checklist_items WHERE task_id = ?), ?)
+			RETURNING id, position`,
+			item.TaskID, item.Text, item.Done, item.TaskID, item.CreatedAt.UnixMilli()).
+			Scan(&item.ID, &item.Position)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "add checklist item", Err: err}
+		}
+
+		result, err := tx.Exec("UPDATE tasks SET version = version + 1, updated_at = ? WHERE id = ?", time.Now().UnixMilli(), item.TaskID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "touch task", Err: err}
+		}
+		return requireAffected(result, item.TaskID)
+	})
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	return item, nil
+}
+
+func (r *SQLiteChecklistRepository) Update(item domain.ChecklistItem) error {
+	return inTx(r.db, "update checklist item", func(tx *sql.Tx) error {
+		result, err := tx.Exec("UPDATE checklist_items SET text = ?, done = ? WHERE id = ? AND task_id = ?",
+			item.Text, item.Done, item.ID, item.TaskID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "update checklist item", Err: err}
+		}
+		if err := requireAffectedResource(result, "ChecklistItem", item.ID); err != nil {
+			return err
+		}
+		return touchTaskIfChanged(tx, result, item.TaskID)
+	})
+}
+
+func (r *SQLiteChecklistRepository) Delete(taskID, itemID int) error {
+	return inTx(r.db, "delete checklist item", func(tx *sql.Tx) error {
+		result, err := tx.Exec("DELETE FROM checklist_items WHERE id = ? AND task_id = ?", itemID, taskID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "delete checklist item", Err: err}
+		}
+		if err := requireAffectedResource(result, "ChecklistItem", itemID); err != nil {
+			return err
+		}
+		return touchTaskIfChanged(tx, result, taskID)
+	})
+}
+
+// attachChecklists fills in Checklist on each task.
+func attachChecklists(q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		args[i] = task.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tasks)), ", ")
+
+	rows, err := q.Query(`SELECT id, task_id, text, done, position, created_at FROM checklist_items
+		WHERE task_id IN (`+placeholders+`)
+		ORDER BY position, id`, args...)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "load checklist items", Err: err}
+	}
+	defer rows.Close()
+
+	items := make(map[int][]domain.ChecklistItem)
+	for rows.Next() {
+		var item domain.ChecklistItem
+		var createdAt int64
+		if err := rows.Scan(&item.ID, &item.TaskID, &item.Text, &item.Done, &item.Position, &createdAt); err != nil {
+			return &domain.DatabaseError{Operation: "scan checklist item", Err: err}
+		}
+		item.CreatedAt = time.UnixMilli(createdAt).UTC()
+		items[item.TaskID] = append(items[item.TaskID], item)
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "iterate checklist items", Err: err}
+	}
+
+	for i := range tasks {
+		tasks[i].Checklist = items[tasks[i].ID]
+	}
+	return nil
+}
+
+func (r *SQLiteChecklistRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteCommentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteCommentRepository(dbPath string) (*SQLiteCommentRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_comments_tables.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteCommentRepository{db: db}, nil
+}
+
+const commentColumns = `c.id, c.task_id, c.author_id, COALESCE(u.email, ''), c.parent_id, c.body, c.created_at, c.edited_at, c.deleted_at`
+
+const commentFrom = ` FROM comments c LEFT JOIN users u ON u.id = c.author_id`
+
+func scanComment(row rowScanner) (domain.Comment, error) {
+	var comment domain.Comment
+	var parentID, editedAt, deletedAt sql.NullInt64
+	var createdAt int64
+
+	err := row.Scan(&comment.ID, &comment.TaskID, &comment.AuthorID, &comment.AuthorEmail, &parentID, &comment.Body, &createdAt, &editedAt, &deletedAt)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	comment.ParentID = int(parentID.Int64)
+	comment.CreatedAt = time.UnixMilli(createdAt).UTC()
+	comment.EditedAt = millisTime(editedAt)
+	comment.DeletedAt = millisTime(deletedAt)
+	return comment, nil
+}
+
+func (r *SQLiteCommentRepository) Create(comment domain.Comment) (domain.Comment, error) {
+	err := inTx(r.db, "create comment", func(tx *sql.Tx) error {
+		comment.CreatedAt = time.UnixMilli(time.Now().UnixMilli()).UTC()
+
+		result, err := tx.Exec(`INSERT INTO comments (task_id, author_id, parent_id, body, created_at) VALUES (?, ?, ?, ?, ?)`,
+			comment.TaskID, comment.AuthorID, nullableID(comment.ParentID), comment.Body, comment.CreatedAt.UnixMilli())
+		if err != nil {
+			return &domain.DatabaseError{Operation: "insert comment", Err: err}
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return &domain.DatabaseError{Operation: "get last insert id", Err: err}
+		}
+		comment.ID = int(id)
+
+		if err := saveMentions(tx, comment.ID, comment.Mentions); err != nil {
+			return err
+		}
+		return touchTaskIfChanged(tx, result, comment.TaskID)
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return r.GetByID(comment.ID)
+}
+
+func (r *SQLiteCommentRepository) GetByID(id int) (domain.Comment, error) {
+	comment, err := scanComment(r.db.QueryRow("SELECT "+commentColumns+commentFrom+" WHERE c.id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Comment{}, &domain.NotFoundError{Resource: "Comment", ID: id}
+		}
+		return domain.Comment{}, &domain.DatabaseError{Operation: "get comment", Err: err}
+	}
+
+	comments := []domain.Comment{comment}
+	if err := attachMentions(r.db, comments); err != nil {
+		return domain.Comment{}, err
+	}
+	return comments[0], nil
+}
+
+func (r *SQLiteCommentRepository) ListByTask(taskID int) ([]domain.Comment, error) {
+	rows, err := r.db.Query("SELECT "+commentColumns+commentFrom+" WHERE c.task_id = ? ORDER BY c.created_at, c.id", taskID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list comments", Err: err}
+	}
+	defer rows.Close()
+
+	comments := []domain.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan comment", Err: err}
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate comments", Err: err}
+	}
+	rows.Close()
+
+	if err := attachMentions(r.db, comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *SQLiteCommentRepository) Update(comment domain.Comment) (domain.Comment, error) {
+	err := inTx(r.db, "update comment", func(tx *sql.Tx) error {
+		result, err := tx.Exec("UPDATE comments SET body = ?, edited_at = ? WHERE id = ? AND deleted_at IS NULL",
+			comment.Body, time.Now().UnixMilli(), comment.ID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "update comment", Err: err}
+		}
+		if err := requireAffectedResource(result, "Comment", comment.ID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM comment_mentions WHERE comment_id = ?", comment.ID); err != nil {
+			return &domain.DatabaseError{Operation: "clear mentions", Err: err}
+		}
+		return saveMentions(tx, comment.ID, comment.Mentions)
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return r.GetByID(comment.ID)
+}
+
+func (r *SQLiteCommentRepository) Delete(id int) error {
+	return inTx(r.db, "delete comment", func(tx *sql.Tx) error {
+		var taskID int
+		err := tx.QueryRow("UPDATE comments SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING task_id", time.Now().UnixMilli(), id).
+			Scan(&taskID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return &domain.NotFoundError{Resource: "Comment", ID: id}
+			}
+			return &domain.DatabaseError{Operation: "delete comment", Err: err}
+		}
+
+		result, err := tx.Exec("UPDATE tasks SET version = version + 1, updated_at = ? WHERE id = ?", time.Now().UnixMilli(), taskID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "touch task", Err: err}
+		}
+		return requireAffected(result, taskID)
+	})
+}
+
+func saveMentions(tx *sql.Tx, commentID int, userIDs []int) error {
+	for _, userID := range userIDs {
+		_, err := tx.Exec("INSERT OR IGNORE INTO comment_mentions (comment_id, user_id) VALUES (?, ?)", commentID, userID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "save mention", Err: err}
+		}
+	}
+	return nil
+}
+
+// attachMentions fills in Mentions on each comment.
+func attachMentions(q queryer, comments []domain.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(comments))
+	for i, comment := range comments {
+		args[i] = comment.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(comments)), ", ")
+
+	rows, err := q.Query(`SELECT comment_id, user_id FROM comment_mentions
+		WHERE comment_id IN (`+placeholders+`)
+		ORDER BY user_id`, args...)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "load mentions", Err: err}
+	}
+	defer rows.Close()
+
+	mentions := make(map[int][]int)
+	for rows.Next() {
+		var commentID, userID int
+		if err := rows.Scan(&commentID, &userID); err != nil {
+			return &domain.DatabaseError{Operation: "scan mention", Err: err}
+		}
+		mentions[commentID] = append(mentions[commentID], userID)
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "iterate mentions", Err: err}
+	}
+
+	for i := range comments {
+		comments[i].Mentions = mentions[comments[i].ID]
+	}
+	return nil
+}
+
+// attachCommentCounts fills in Comments on each task, leaving out deleted
+// comments.
+func attachCommentCounts(q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		args[i] = task.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tasks)), ", ")
+
+	rows, err := q.Query(`SELECT task_id, COUNT(*) FROM comments
+		WHERE task_id IN (`+placeholders+`) AND deleted_at IS NULL
+		GROUP BY task_id`, args...)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "count comments", Err: err}
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var taskID, count int
+		if err := rows.Scan(&taskID, &count); err != nil {
+			return &domain.DatabaseError{Operation: "scan comment count", Err: err}
+		}
+		counts[taskID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "iterate comment counts", Err: err}
+	}
+
+	for i := range tasks {
+		tasks[i].Comments = counts[tasks[i].ID]
+	}
+	return nil
+}
+
+func (r *SQLiteCommentRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
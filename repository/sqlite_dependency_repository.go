@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteDependencyRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteDependencyRepository(dbPath string) (*SQLiteDependencyRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_task_dependencies_table.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDependencyRepository{db: db}, nil
+}
+
+// Add checks for a cycle and inserts the edge in one transaction, so two
+// concurrent inserts can't close a cycle between them.
+func (r *SQLiteDependencyRepository) Add(dependency domain.Dependency) (bool, error) {
+	var added bool
+	err := inTx(r.db, "add dependency", func(tx *sql.Tx) error {
+		path, err := blockerPath(tx, dependency.BlockedByID, dependency.TaskID)
+		if err != nil {
+			return err
+		}
+		if path != nil {
+			return &domain.ValidationError{Field: "blocked_by_id", Message: "would create a cycle: " + describeCycle(append([]int{dependency.TaskID}, path...))}
+		}
+
+		result, err := tx.Exec(`INSERT OR IGNORE INTO task_dependencies (task_id, blocked_by_id, created_at) VALUES (?, ?, ?)`,
+			dependency.TaskID, dependency.BlockedByID, time.Now().UnixMilli())
+		if err != nil {
+			return &domain.DatabaseError{Operation: "add dependency", Err: err}
+		}
+		added, err = changedRows(result)
+		if err != nil {
+			return err
+		}
+		return touchTaskIfChanged(tx, result, dependency.TaskID)
+	})
+	return added, err
+}
+
+func (r *SQLiteDependencyRepository) Remove(taskID, blockedByID int) (bool, error) {
+	var removed bool
+	err := inTx(r.db, "remove dependency", func(tx *sql.Tx) error {
+		result, err := tx.Exec("DELETE FROM task_dependencies WHERE task_id = ? AND blocked_by_id = ?", taskID, blockedByID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "remove dependency", Err: err}
+		}
+		removed, err = changedRows(result)
+		if err != nil {
+			return err
+		}
+		return touchTaskIfChanged(tx, result, taskID)
+	})
+	return removed, err
+}
+
+// blockerPath searches breadth-first along "is blocked by" edges from from
+// to to and returns the shortest path, both ends included, or nil if there
+// is none.
+func blockerPath(q queryer, from, to int) ([]int, error) {
+	previous := map[int]int{from: 0}
+	frontier := []int{from}
+
+	for len(frontier) > 0 {
+		var next []int
+		for _, id := range frontier {
+			if id == to {
+				path := []int{id}
+				for id != from {
+					id = previous[id]
+					path = append([]int{id}, path...)
+				}
+				return path, nil
+			}
+
+			blockers, err := blockersOf(q, id)
+			if err != nil {
+				return nil, err
+			}
+			for _, blocker := range blockers {
+				if _, seen := previous[blocker]; !seen {
+					previous[blocker] = id
+					next = append(next, blocker)
+				}
+			}
+		}
+		frontier = next
+	}
+	return nil, nil
+}
+
+func blockersOf(q queryer, taskID int) ([]int, error) {
+	rows, err := q.Query("SELECT blocked_by_id FROM task_dependencies WHERE task_id = ? ORDER BY blocked_by_id", taskID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "load blockers", Err: err}
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan blocker", Err: err}
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate blockers", Err: err}
+	}
+	return ids, nil
+}
+
+// describeCycle renders [3 5 7 3] as "task 3 is blocked by 5, which is
+// blocked by 7, which is blocked by 3".
+func describeCycle(path []int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "task %d is blocked by %d", path[0], path[1])
+	for _, id := range path[2:] {
+		b.WriteString(", which is blocked by " + strconv.Itoa(id))
+	}
+	return b.String()
+}
+
+func (r *SQLiteDependencyRepository) Graph(taskID int) ([]domain.Dependency, error) {
+	rows, err := r.db.Query(`WITH RECURSIVE
+		upstream(id) AS (
+			SELECT ? UNION SELECT d.blocked_by_id FROM task_dependencies d JOIN upstream u ON d.task_id = u.id
+		),
+		downstream(id) AS (
+			SELECT ? UNION SELECT d.task_id FROM task_dependencies d JOIN downstream w ON d.blocked_by_id = w.id
+		)
+		SELECT task_id, blocked_by_id, created_at FROM task_dependencies
+		WHERE (task_id IN (SELECT id FROM upstream) AND blocked_by_id IN (SELECT id FROM upstream))
+			OR (task_id IN (SELECT id FROM downstream) AND blocked_by_id IN (SELECT id FROM downstream))
+		ORDER BY task_id, blocked_by_id`, taskID, taskID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "load dependency graph", Err: err}
+	}
+	defer rows.Close()
+
+	dependencies := []domain.Dependency{}
+	for rows.Next() {
+		var dependency domain.Dependency
+		var createdAt int64
+		if err := rows.Scan(&dependency.TaskID, &dependency.BlockedByID, &createdAt); err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan dependency", Err: err}
+		}
+		dependency.CreatedAt = time.UnixMilli(createdAt).UTC()
+		dependencies = append(dependencies, dependency)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate dependencies", Err: err}
+	}
+	return dependencies, nil
+}
+
+// attachDependencies fills in BlockedBy, Blocks and Blocked on each task.
+func attachDependencies(q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		args[i] = task.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tasks)), ", ")
+
+	rows, err := q.Query(`SELECT d.task_id, d.blocked_by_id, t.status FROM task_dependencies d
+		JOIN tasks t ON t.id = d.blocked_by_id
+		WHERE d.task_id IN (`+placeholders+`)
+		ORDER BY d.blocked_by_id`, args...)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "load blockers", Err: err}
+	}
+	defer rows.Close()
+
+	blockedBy := make(map[int][]int)
+	open := make(map[int]bool)
+	for rows.Next() {
+		var taskID, blockerID int
+		var status string
+		if err := rows.Scan(&taskID, &blockerID, &status); err != nil {
+			return &domain.DatabaseError{Operation: "scan blocker", Err: err}
+		}
+		blockedBy[taskID] = append(blockedBy[taskID], blockerID)
+		if status != domain.StatusCompleted {
+			open[taskID] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "iterate blockers", Err: err}
+	}
+	rows.Close()
+
+	rows, err = q.Query(`SELECT blocked_by_id, task_id FROM task_dependencies
+		WHERE blocked_by_id IN (`+placeholders+`)
+		ORDER BY task_id`, args...)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "load dependents", Err: err}
+	}
+	defer rows.Close()
+
+	blocks := make(map[int][]int)
+	for rows.Next() {
+		var blockerID, taskID int
+		if err := rows.Scan(&blockerID, &taskID); err != nil {
+			return &domain.DatabaseError{Operation: "scan dependent", Err: err}
+		}
+		blocks[blockerID] = append(blocks[blockerID], taskID)
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "iterate dependents", Err: err}
+	}
+
+	for i := range tasks {
+		id := tasks[i].ID
+		tasks[i].BlockedBy, tasks[i].Blocks, tasks[i].Blocked = blockedBy[id], blocks[id], open[id]
+	}
+	return nil
+}
+
+func (r *SQLiteDependencyRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
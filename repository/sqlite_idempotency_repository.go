@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"os"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteIdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteIdempotencyRepository(dbPath string) (*SQLiteIdempotencyRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	repo := &SQLiteIdempotencyRepository{db: db}
+
+	// The migration only uses IF NOT EXISTS, so it is safe on every start.
+	err = repo.initSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func (r *SQLiteIdempotencyRepository) initSchema() error {
+	sqlBytes, err := os.ReadFile("migrations/add_idempotency_keys_table.sql")
+	if err != nil {
+		return &domain.DatabaseError{Operation: "read migration", Err: err}
+	}
+
+	_, err = r.db.Exec(string(sqlBytes))
+	if err != nil {
+		return &domain.DatabaseError{Operation: "init schema", Err: err}
+	}
+
+	return nil
+}
+
+func (r *SQLiteIdempotencyRepository) Reserve(record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, &domain.DatabaseError{Operation: "begin reserve", Err: err}
+	}
+	defer tx.Rollback()
+
+	// Expired records and pending records whose owner gave up (crashed or
+	// timed out) no longer block the key.
+	now := time.Now().Unix()
+	_, err = tx.Exec(`DELETE FROM idempotency_keys
+		WHERE idempotency_key = ? AND user_id = ?
+		AND (expires_at <= ? OR (state = ? AND locked_until <= ?))`,
+		record.Key, record.UserID, now, domain.IdempotencyPending, now)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, &domain.DatabaseError{Operation: "delete stale idempotency key", Err: err}
+	}
+
+	result, err := tx.Exec(`INSERT INTO idempotency_keys
+		(idempotency_key, user_id, method, path, fingerprint, state, created_at, locked_until, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (idempotency_key, user_id) DO NOTHING`,
+		record.Key, record.UserID, record.Method, record.Path, record.Fingerprint, domain.IdempotencyPending,
+		record.CreatedAt.Unix(), record.LockedUntil.Unix(), record.ExpiresAt.Unix())
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, &domain.DatabaseError{Operation: "reserve idempotency key", Err: err}
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, &domain.DatabaseError{Operation: "rows affected", Err: err}
+	}
+
+	if affected == 1 {
+		if err := tx.Commit(); err != nil {
+			return domain.IdempotencyRecord{}, false, &domain.DatabaseError{Operation: "commit reserve", Err: err}
+		}
+		record.State = domain.IdempotencyPending
+		return record, true, nil
+	}
+
+	existing, err := scanIdempotencyRecord(tx.QueryRow(selectIdempotencySQL, record.Key, record.UserID))
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	return existing, false, tx.Commit()
+}
+
+const selectIdempotencySQL = `SELECT idempotency_key, user_id, method, path, fingerprint, state, status_code,
+	headers, body, created_at, locked_until, expires_at
+	FROM idempotency_keys WHERE idempotency_key = ? AND user_id = ?`
+
+func (r *SQLiteIdempotencyRepository) Get(key string, userID int) (domain.IdempotencyRecord, error) {
+	return scanIdempotencyRecord(r.db.QueryRow(selectIdempotencySQL, key, userID))
+}
+
+func scanIdempotencyRecord(row *sql.Row) (domain.IdempotencyRecord, error) {
+	var record domain.IdempotencyRecord
+	var headers string
+	var createdAt, lockedUntil, expiresAt int64
+
+	err := row.Scan(&record.Key, &record.UserID, &record.Method, &record.Path, &record.Fingerprint,
+		&record.State, &record.StatusCode, &headers, &record.Body, &createdAt, &lockedUntil, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.IdempotencyRecord{}, &domain.NotFoundError{Resource: "Idempotency key", ID: 0}
+		}
+		return domain.IdempotencyRecord{}, &domain.DatabaseError{Operation: "get idempotency key", Err: err}
+	}
+
+	record.Headers = http.Header{}
+	if err := json.Unmarshal([]byte(headers), &record.Headers); err != nil {
+		return domain.IdempotencyRecord{}, &domain.DatabaseError{Operation: "decode stored headers", Err: err}
+	}
+	record.CreatedAt = time.Unix(createdAt, 0)
+	record.LockedUntil = time.Unix(lockedUntil, 0)
+	record.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return record, nil
+}
+
+func (r *SQLiteIdempotencyRepository) Complete(record domain.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "encode headers", Err: err}
+	}
+
+	_, err = r.db.Exec(`UPDATE idempotency_keys
+		SET state = ?, fingerprint = ?, status_code = ?, headers = ?, body = ?
+		WHERE idempotency_key = ? AND user_id = ?`,
+		domain.IdempotencyCompleted, record.Fingerprint, record.StatusCode, string(headers), record.Body,
+		record.Key, record.UserID)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "complete idempotency key", Err: err}
+	}
+
+	return nil
+}
+
+func (r *SQLiteIdempotencyRepository) Release(key string, userID int) error {
+	_, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE idempotency_key = ? AND user_id = ? AND state = ?`,
+		key, userID, domain.IdempotencyPending)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "release idempotency key", Err: err}
+	}
+	return nil
+}
+
+func (r *SQLiteIdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, now.Unix())
+	if err != nil {
+		return 0, &domain.DatabaseError{Operation: "delete expired idempotency keys", Err: err}
+	}
+	return result.RowsAffected()
+}
+
+func (r *SQLiteIdempotencyRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
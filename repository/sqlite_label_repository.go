@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteLabelRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteLabelRepository(dbPath string) (*SQLiteLabelRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	repo := &SQLiteLabelRepository{db: db}
+
+	err = execMigration(db, "migrations/add_labels_tables.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// execMigration runs a migration file. Migrations that only use IF NOT
+// EXISTS are safe to run on every start.
+func execMigration(db *sql.DB, path string) error {
+	sqlBytes, err := os.ReadFile(path)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "read migration", Err: err}
+	}
+
+	_, err = db.Exec(string(sqlBytes))
+	if err != nil {
+		return &domain.DatabaseError{Operation: "init schema", Err: err}
+	}
+
+	return nil
+}
+
+const labelColumns = "id, user_id, name, color, shared, created_at"
+
+func scanLabel(row rowScanner) (domain.Label, error) {
+	var label domain.Label
+	var createdAt int64
+
+	err := row.Scan(&label.ID, &label.UserID, &label.Name, &label.Color, &label.Shared, &createdAt)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	label.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return label, nil
+}
+
+func (r *SQLiteLabelRepository) Create(label domain.Label) (domain.Label, error) {
+	label.CreatedAt = time.UnixMilli(time.Now().UnixMilli()).UTC()
+
+	result, err := r.db.Exec(`INSERT INTO labels (user_id, name, color, shared, created_at) VALUES (?, ?, ?, ?, ?)`,
+		label.UserID, label.Name, label.Color, label.Shared, label.CreatedAt.UnixMilli())
+	if err != nil {
+		return domain.Label{}, &domain.DatabaseError{Operation: "insert label", Err: err}
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domain.Label{}, &domain.DatabaseError{Operation: "get last insert id", Err: err}
+	}
+
+	label.ID = int(id)
+	return label, nil
+}
+
+func (r *SQLiteLabelRepository) GetByID(id int) (domain.Label, error) {
+	label, err := scanLabel(r.db.QueryRow("SELECT "+labelColumns+" FROM labels WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Label{}, &domain.NotFoundError{Resource: "Label", ID: id}
+		}
+		return domain.Label{}, &domain.DatabaseError{Operation: "get label", Err: err}
+	}
+
+	return label, nil
+}
+
+func (r *SQLiteLabelRepository) ListVisible(userID int) ([]domain.Label, error) {
+	rows, err := r.db.Query(`SELECT `+labelColumns+` FROM labels
+		WHERE user_id = ? OR shared = 1
+		ORDER BY user_id != ?, name COLLATE NOCASE, id`, userID, userID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list labels", Err: err}
+	}
+	defer rows.Close()
+
+	labels := []domain.Label{}
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan label", Err: err}
+		}
+		labels = append(labels, label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate labels", Err: err}
+	}
+
+	return labels, nil
+}
+
+// Update renames or recolors a label. Every task carrying it gets a new
+// version in the same transaction, so cached lists and ETags move on.
+func (r *SQLiteLabelRepository) Update(label domain.Label) (domain.Label, []int, error) {
+	var affected []int
+	err := inTx(r.db, "update label", func(tx *sql.Tx) error {
+		result, err := tx.Exec("UPDATE labels SET name = ?, color = ?, shared = ? WHERE id = ?",
+			label.Name, label.Color, label.Shared, label.ID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "update label", Err: err}
+		}
+		if err := requireAffectedResource(result, "Label", label.ID); err != nil {
+			return err
+		}
+
+		affected, err = touchLabelledTasks(tx, label.ID)
+		return err
+	})
+	if err != nil {
+		return domain.Label{}, nil, err
+	}
+
+	updated, err := r.GetByID(label.ID)
+	return updated, affected, err
+}
+
+func (r *SQLiteLabelRepository) Delete(id int) ([]int, error) {
+	var affected []int
+	err := inTx(r.db, "delete label", func(tx *sql.Tx) error {
+		var err error
+		affected, err = touchLabelledTasks(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", id); err != nil {
+			return &domain.DatabaseError{Operation: "unlink label", Err: err}
+		}
+
+		result, err := tx.Exec("DELETE FROM labels WHERE id = ?", id)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "delete label", Err: err}
+		}
+		return requireAffectedResource(result, "Label", id)
+	})
+	return affected, err
+}
+
+// Merge relabels every task carrying source with target, then deletes
+// source. Tasks that already had both keep a single target link.
+func (r *SQLiteLabelRepository) Merge(sourceID, targetID int) ([]int, error) {
+	var affected []int
+	err := inTx(r.db, "merge labels", func(tx *sql.Tx) error {
+		var err error
+		affected, err = touchLabelledTasks(tx, sourceID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`INSERT OR IGNORE INTO task_labels (task_id, label_id)
+			SELECT task_id, ? FROM task_labels WHERE label_id = ?`, targetID, sourceID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "relabel tasks", Err: err}
+		}
+
+		if _, err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", sourceID); err != nil {
+			return &domain.DatabaseError{Operation: "unlink label", Err: err}
+		}
+
+		result, err := tx.Exec("DELETE FROM labels WHERE id = ?", sourceID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "delete label", Err: err}
+		}
+		return requireAffectedResource(result, "Label", sourceID)
+	})
+	return affected, err
+}
+
+func (r *SQLiteLabelRepository) AddToTask(taskID, labelID int) error {
+	return inTx(r.db, "label task", func(tx *sql.Tx) error {
+		result, err := tx.Exec("INSERT OR IGNORE INTO task_labels (task_id, label_id) VALUES (?, ?)", taskID, labelID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "label task", Err: err}
+		}
+		return touchTaskIfChanged(tx, result, taskID)
+	})
+}
+
+func (r *SQLiteLabelRepository) RemoveFromTask(taskID, labelID int) error {
+	return inTx(r.db, "unlabel task", func(tx *sql.Tx) error {
+		result, err := tx.Exec("DELETE FROM task_labels WHERE task_id = ? AND label_id = ?", taskID, labelID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "unlabel task", Err: err}
+		}
+		return touchTaskIfChanged(tx, result, taskID)
+	})
+}
+
+// inTx runs fn in a transaction, committing if it returns nil.
+func inTx(db *sql.DB, operation string, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return &domain.DatabaseError{Operation: "begin " + operation, Err: err}
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return &domain.DatabaseError{Operation: "commit " + operation, Err: err}
+	}
+	return nil
+}
+
+// touchLabelledTasks bumps the version of every task carrying the label and
+// returns their IDs.
+func touchLabelledTasks(tx *sql.Tx, labelID int) ([]int, error) {
+	rows, err := tx.Query(`UPDATE tasks SET version = version + 1, updated_at = ?
+		WHERE id IN (SELECT task_id FROM task_labels WHERE label_id = ?)
+		RETURNING id`, time.Now().UnixMilli(), labelID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "touch labelled tasks", Err: err}
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan task id", Err: err}
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "touch labelled tasks", Err: err}
+	}
+	return ids, nil
+}
+
+// touchTaskIfChanged bumps the task's version when the link statement
+// changed a row, so adding a label twice is a no-op.
+func touchTaskIfChanged(tx *sql.Tx, result sql.Result, taskID int) error {
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return &domain.DatabaseError{Operation: "rows affected", Err: err}
+	}
+	if changed == 0 {
+		return nil
+	}
+
+	result, err = tx.Exec("UPDATE tasks SET version = version + 1, updated_at = ? WHERE id = ?", time.Now().UnixMilli(), taskID)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "touch task", Err: err}
+	}
+	return requireAffected(result, taskID)
+}
+
+// labelsForTasks loads the labels of the given tasks, keyed by task ID.
+func labelsForTasks(q queryer, taskIDs []int) (map[int][]domain.Label, error) {
+	labels := make(map[int][]domain.Label)
+	if len(taskIDs) == 0 {
+		return labels, nil
+	}
+
+	args := make([]interface{}, len(taskIDs))
+	for i, id := range taskIDs {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(taskIDs)), ", ")
+
+	rows, err := q.Query(`SELECT tl.task_id, `+qualifiedColumns("l", labelColumns)+`
+		FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+		WHERE tl.task_id IN (`+placeholders+`)
+		ORDER BY l.name COLLATE NOCASE, l.id`, args...)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "load task labels", Err: err}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int
+		label, err := scanLabel(prefixedRow{rows, []interface{}{&taskID}})
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan task label", Err: err}
+		}
+		labels[taskID] = append(labels[taskID], label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate task labels", Err: err}
+	}
+	return labels, nil
+}
+
+// attachLabels fills in Labels on each task.
+func attachLabels(q queryer, tasks []domain.Task) error {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	labels, err := labelsForTasks(q, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		tasks[i].Labels = labels[tasks[i].ID]
+	}
+	return nil
+}
+
+// prefixedRow reads extra leading columns before handing the rest to a scan
+// function written for the plain table.
+type prefixedRow struct {
+	row    rowScanner
+	prefix []interface{}
+}
+
+func (p prefixedRow) Scan(dest ...interface{}) error {
+	return p.row.Scan(append(p.prefix, dest...)...)
+}
+
+func (r *SQLiteLabelRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"database/sql"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteNotificationRepository(dbPath string) (*SQLiteNotificationRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_notifications_tables.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteNotificationRepository{db: db}, nil
+}
+
+const notificationColumns = "id, user_id, task_id, reminder_id, message, created_at, read_at"
+
+func scanNotification(row rowScanner) (domain.Notification, error) {
+	var notification domain.Notification
+	var reminderID, readAt sql.NullInt64
+	var createdAt int64
+
+	err := row.Scan(&notification.ID, &notification.UserID, &notification.TaskID, &reminderID, &notification.Message, &createdAt, &readAt)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+
+	notification.ReminderID = int(reminderID.Int64)
+	notification.CreatedAt = time.UnixMilli(createdAt).UTC()
+	notification.ReadAt = millisTime(readAt)
+	return notification, nil
+}
+
+func (r *SQLiteNotificationRepository) Create(notification domain.Notification) (domain.Notification, error) {
+	created, err := scanNotification(r.db.QueryRow(`INSERT INTO notifications (user_id, task_id, reminder_id, message, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (reminder_id) DO NOTHING
+		RETURNING `+notificationColumns,
+		notification.UserID, notification.TaskID, nullableID(notification.ReminderID), notification.Message, time.Now().UnixMilli()))
+	if err == sql.ErrNoRows {
+		// Already delivered for this reminder.
+		created, err = scanNotification(r.db.QueryRow("SELECT "+notificationColumns+" FROM notifications WHERE reminder_id = ?", notification.ReminderID))
+	}
+	if err != nil {
+		return domain.Notification{}, &domain.DatabaseError{Operation: "insert notification", Err: err}
+	}
+	return created, nil
+}
+
+func (r *SQLiteNotificationRepository) ListByUser(userID int, unreadOnly bool) ([]domain.Notification, error) {
+	query := "SELECT " + notificationColumns + " FROM notifications WHERE user_id = ?"
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+
+	rows, err := r.db.Query(query+" ORDER BY created_at DESC, id DESC LIMIT 200", userID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list notifications", Err: err}
+	}
+	defer rows.Close()
+
+	notifications := []domain.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan notification", Err: err}
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate notifications", Err: err}
+	}
+	return notifications, nil
+}
+
+// MarkRead keeps the first read time when called again.
+func (r *SQLiteNotificationRepository) MarkRead(id, userID int) (domain.Notification, error) {
+	notification, err := scanNotification(r.db.QueryRow(`UPDATE notifications SET read_at = COALESCE(read_at, ?)
+		WHERE id = ? AND user_id = ?
+		RETURNING `+notificationColumns, time.Now().UnixMilli(), id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Notification{}, &domain.NotFoundError{Resource: "Notification", ID: id}
+		}
+		return domain.Notification{}, &domain.DatabaseError{Operation: "mark notification read", Err: err}
+	}
+	return notification, nil
+}
+
+func (r *SQLiteNotificationRepository) GetSettings(userID int) (domain.NotificationSettings, error) {
+	settings := domain.NotificationSettings{UserID: userID, Timezone: "UTC"}
+
+	err := r.db.QueryRow("SELECT timezone, quiet_start, quiet_end, webhook_url FROM notification_settings WHERE user_id = ?", userID).
+		Scan(&settings.Timezone, &settings.QuietStart, &settings.QuietEnd, &settings.WebhookURL)
+	if err != nil && err != sql.ErrNoRows {
+		return domain.NotificationSettings{}, &domain.DatabaseError{Operation: "get notification settings", Err: err}
+	}
+	return settings, nil
+}
+
+func (r *SQLiteNotificationRepository) SaveSettings(settings domain.NotificationSettings) (domain.NotificationSettings, error) {
+	_, err := r.db.Exec(`INSERT INTO notification_settings (user_id, timezone, quiet_start, quiet_end, webhook_url)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET timezone = excluded.timezone, quiet_start = excluded.quiet_start,
+			quiet_end = excluded.quiet_end, webhook_url = excluded.webhook_url`,
+		settings.UserID, settings.Timezone, settings.QuietStart, settings.QuietEnd, settings.WebhookURL)
+	if err != nil {
+		return domain.NotificationSettings{}, &domain.DatabaseError{Operation: "save notification settings", Err: err}
+	}
+	return settings, nil
+}
+
+func (r *SQLiteNotificationRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
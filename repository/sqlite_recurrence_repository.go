@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteRecurrenceRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRecurrenceRepository(dbPath string) (*SQLiteRecurrenceRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_recurrences_tables.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteRecurrenceRepository{db: db}, nil
+}
+
+// recurrenceQuery selects recurrences with their latest occurrence and the
+// status of the task created for it.
+const recurrenceQuery = `SELECT r.task_id, r.rule, r.timezone, r.starts_at, r.mode, r.created_at, o.occurs_at, o.task_id, t.status
+	FROM recurrences r
+	LEFT JOIN recurrence_occurrences o ON o.template_id = r.task_id
+		AND o.occurs_at = (SELECT MAX(occurs_at) FROM recurrence_occurrences WHERE template_id = r.task_id)
+	LEFT JOIN tasks t ON t.id = o.task_id`
+
+func scanRecurrence(row rowScanner) (domain.Recurrence, error) {
+	var recurrence domain.Recurrence
+	var startsAt, createdAt int64
+	var last, lastTaskID sql.NullInt64
+	var lastStatus sql.NullString
+
+	err := row.Scan(&recurrence.TaskID, &recurrence.Rule, &recurrence.Timezone, &startsAt, &recurrence.Mode, &createdAt,
+		&last, &lastTaskID, &lastStatus)
+	if err != nil {
+		return domain.Recurrence{}, err
+	}
+
+	recurrence.StartsAt = time.UnixMilli(startsAt).UTC()
+	recurrence.CreatedAt = time.UnixMilli(createdAt).UTC()
+	recurrence.Last = millisTime(last)
+	if lastStatus.Valid {
+		recurrence.LastTaskID = int(lastTaskID.Int64)
+		recurrence.LastOpen = lastStatus.String != domain.StatusCompleted
+	}
+	return recurrence, nil
+}
+
+func (r *SQLiteRecurrenceRepository) Set(recurrence domain.Recurrence) (domain.Recurrence, error) {
+	_, err := r.db.Exec(`INSERT INTO recurrences (task_id, rule, timezone, starts_at, mode, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (task_id) DO UPDATE SET rule = excluded.rule, timezone = excluded.timezone,
+			starts_at = excluded.starts_at, mode = excluded.mode`,
+		recurrence.TaskID, recurrence.Rule, recurrence.Timezone, recurrence.StartsAt.UnixMilli(), recurrence.Mode, time.Now().UnixMilli())
+	if err != nil {
+		return domain.Recurrence{}, &domain.DatabaseError{Operation: "set recurrence", Err: err}
+	}
+	return r.Get(recurrence.TaskID)
+}
+
+func (r *SQLiteRecurrenceRepository) Get(taskID int) (domain.Recurrence, error) {
+	recurrence, err := scanRecurrence(r.db.QueryRow(recurrenceQuery+" WHERE r.task_id = ?", taskID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Recurrence{}, &domain.NotFoundError{Resource: "Recurrence", ID: taskID}
+		}
+		return domain.Recurrence{}, &domain.DatabaseError{Operation: "get recurrence", Err: err}
+	}
+	return recurrence, nil
+}
+
+func (r *SQLiteRecurrenceRepository) List() ([]domain.Recurrence, error) {
+	rows, err := r.db.Query(recurrenceQuery + " ORDER BY r.task_id")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list recurrences", Err: err}
+	}
+	defer rows.Close()
+
+	recurrences := []domain.Recurrence{}
+	for rows.Next() {
+		recurrence, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan recurrence", Err: err}
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate recurrences", Err: err}
+	}
+	return recurrences, nil
+}
+
+func (r *SQLiteRecurrenceRepository) Delete(taskID int) error {
+	result, err := r.db.Exec("DELETE FROM recurrences WHERE task_id = ?", taskID)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "delete recurrence", Err: err}
+	}
+	return requireAffectedResource(result, "Recurrence", taskID)
+}
+
+func (r *SQLiteRecurrenceRepository) Materialize(templateID int, occursAt time.Time, task domain.Task) (domain.Task, bool, error) {
+	created := false
+
+	err := inTx(r.db, "materialize occurrence", func(tx *sql.Tx) error {
+		result, err := tx.Exec(`INSERT INTO recurrence_occurrences (template_id, occurs_at) VALUES (?, ?)
+			ON CONFLICT DO NOTHING`, templateID, occursAt.UnixMilli())
+		if err != nil {
+			return &domain.DatabaseError{Operation: "record occurrence", Err: err}
+		}
+		if changed, err := changedRows(result); err != nil || !changed {
+			return err
+		}
+
+		task, err = insertTask(tx, task)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE recurrence_occurrences SET task_id = ? WHERE template_id = ? AND occurs_at = ?",
+			task.ID, templateID, occursAt.UnixMilli())
+		if err != nil {
+			return &domain.DatabaseError{Operation: "link occurrence", Err: err}
+		}
+
+		_, err = tx.Exec("INSERT INTO task_labels (task_id, label_id) SELECT ?, label_id FROM task_labels WHERE task_id = ?",
+			task.ID, templateID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "copy labels", Err: err}
+		}
+
+		_, err = tx.Exec(`INSERT INTO task_assignees (task_id, user_id, assigned_by, created_at)
+			SELECT ?, user_id, assigned_by, ? FROM task_assignees WHERE task_id = ?`,
+			task.ID, time.Now().UnixMilli(), templateID)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "copy assignees", Err: err}
+		}
+
+		created = true
+		return nil
+	})
+	if err != nil || !created {
+		return domain.Task{}, false, err
+	}
+	return task, true, nil
+}
+
+// attachOccurrences sets OccurrenceOf on tasks created from a recurring
+// template.
+func attachOccurrences(q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		args[i] = task.ID
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tasks)), ", ")
+
+	rows, err := q.Query("SELECT task_id, template_id FROM recurrence_occurrences WHERE task_id IN ("+placeholders+")", args...)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "load occurrences", Err: err}
+	}
+	defer rows.Close()
+
+	templates := make(map[int]int)
+	for rows.Next() {
+		var taskID, templateID int
+		if err := rows.Scan(&taskID, &templateID); err != nil {
+			return &domain.DatabaseError{Operation: "scan occurrence", Err: err}
+		}
+		templates[taskID] = templateID
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "iterate occurrences", Err: err}
+	}
+
+	for i := range tasks {
+		tasks[i].OccurrenceOf = templates[tasks[i].ID]
+	}
+	return nil
+}
+
+func (r *SQLiteRecurrenceRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
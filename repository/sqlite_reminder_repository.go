@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"database/sql"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteReminderRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteReminderRepository(dbPath string) (*SQLiteReminderRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_reminders_table.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteReminderRepository{db: db}, nil
+}
+
+// reminderFireAt is when a reminder is due. It follows the task's current
+// due date, so moving the due date moves relative reminders with it.
+const reminderFireAt = "COALESCE(r.remind_at, t.due_date + r.offset_minutes * 60000)"
+
+const reminderQuery = `SELECT r.id, r.task_id, r.user_id, r.remind_at, r.offset_minutes, r.channel, r.status, ` + reminderFireAt + `,
+		r.deliver_after, r.attempts, r.last_error, r.sent_at, r.created_at
+	FROM reminders r JOIN tasks t ON t.id = r.task_id`
+
+func scanReminder(row rowScanner) (domain.Reminder, error) {
+	var reminder domain.Reminder
+	var remindAt, offset, fireAt, deliverAfter, sentAt sql.NullInt64
+	var createdAt int64
+
+	err := row.Scan(&reminder.ID, &reminder.TaskID, &reminder.UserID, &remindAt, &offset, &reminder.Channel, &reminder.Status,
+		&fireAt, &deliverAfter, &reminder.Attempts, &reminder.LastError, &sentAt, &createdAt)
+	if err != nil {
+		return domain.Reminder{}, err
+	}
+
+	reminder.RemindAt = millisTime(remindAt)
+	if offset.Valid {
+		minutes := int(offset.Int64)
+		reminder.OffsetMinutes = &minutes
+	}
+	reminder.FireAt = millisTime(fireAt)
+	reminder.DeliverAfter = millisTime(deliverAfter)
+	reminder.SentAt = millisTime(sentAt)
+	reminder.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return reminder, nil
+}
+
+func scanReminderRows(rows *sql.Rows) ([]domain.Reminder, error) {
+	defer rows.Close()
+
+	reminders := []domain.Reminder{}
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan reminder", Err: err}
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate reminders", Err: err}
+	}
+	return reminders, nil
+}
+
+func (r *SQLiteReminderRepository) Create(reminder domain.Reminder) (domain.Reminder, error) {
+	var offset sql.NullInt64
+	if reminder.OffsetMinutes != nil {
+		offset = sql.NullInt64{Int64: int64(*reminder.OffsetMinutes), Valid: true}
+	}
+
+	result, err := r.db.Exec(`INSERT INTO reminders (task_id, user_id, remind_at, offset_minutes, channel, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		reminder.TaskID, reminder.UserID, nullableMillis(reminder.RemindAt), offset, reminder.Channel, domain.ReminderPending,
+		time.Now().UnixMilli())
+	if err != nil {
+		return domain.Reminder{}, &domain.DatabaseError{Operation: "insert reminder", Err: err}
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domain.Reminder{}, &domain.DatabaseError{Operation: "get last insert id", Err: err}
+	}
+	return r.GetByID(int(id))
+}
+
+func (r *SQLiteReminderRepository) GetByID(id int) (domain.Reminder, error) {
+	reminder, err := scanReminder(r.db.QueryRow(reminderQuery+" WHERE r.id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Reminder{}, &domain.NotFoundError{Resource: "Reminder", ID: id}
+		}
+		return domain.Reminder{}, &domain.DatabaseError{Operation: "get reminder", Err: err}
+	}
+	return reminder, nil
+}
+
+func (r *SQLiteReminderRepository) ListByTask(taskID, userID int) ([]domain.Reminder, error) {
+	rows, err := r.db.Query(reminderQuery+" WHERE r.task_id = ? AND r.user_id = ? ORDER BY r.id", taskID, userID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list reminders", Err: err}
+	}
+	return scanReminderRows(rows)
+}
+
+func (r *SQLiteReminderRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM reminders WHERE id = ?", id)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "delete reminder", Err: err}
+	}
+	return requireAffectedResource(result, "Reminder", id)
+}
+
+// Claim relies on SQLite running the UPDATE, subquery included, under the
+// database write lock: two instances can't select the same reminder.
+func (r *SQLiteReminderRepository) Claim(owner string, now time.Time, lease time.Duration, limit int) ([]domain.Reminder, error) {
+	nowMillis := now.UnixMilli()
+
+	rows, err := r.db.Query(`UPDATE reminders SET claimed_by = ?, claim_expires = ?
+		WHERE id IN (
+			SELECT r.id FROM reminders r JOIN tasks t ON t.id = r.task_id
+			WHERE r.status = ?
+				AND (r.claim_expires IS NULL OR r.claim_expires < ?)
+				AND `+reminderFireAt+` <= ?
+				AND COALESCE(r.deliver_after, 0) <= ?
+			ORDER BY `+reminderFireAt+`, r.id
+			LIMIT ?)
+		RETURNING id`,
+		owner, now.Add(lease).UnixMilli(), domain.ReminderPending, nowMillis, nowMillis, nowMillis, limit)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "claim reminders", Err: err}
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, &domain.DatabaseError{Operation: "scan claimed reminder", Err: err}
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate claimed reminders", Err: err}
+	}
+
+	reminders := []domain.Reminder{}
+	for _, id := range ids {
+		reminder, err := r.GetByID(id)
+		if _, ok := err.(*domain.NotFoundError); ok {
+			continue // its task was deleted meanwhile
+		}
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+	return reminders, nil
+}
+
+func (r *SQLiteReminderRepository) Postpone(id int, owner string, until time.Time, errMsg string) error {
+	attempts := 0
+	if errMsg != "" {
+		attempts = 1
+	}
+
+	_, err := r.db.Exec(`UPDATE reminders SET deliver_after = ?, attempts = attempts + ?, last_error = ?,
+			claimed_by = NULL, claim_expires = NULL
+		WHERE id = ? AND claimed_by = ?`,
+		until.UnixMilli(), attempts, errMsg, id, owner)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "postpone reminder", Err: err}
+	}
+	return nil
+}
+
+func (r *SQLiteReminderRepository) Finish(id int, owner, status, errMsg string) error {
+	var sentAt sql.NullInt64
+	if status == domain.ReminderSent {
+		sentAt = sql.NullInt64{Int64: time.Now().UnixMilli(), Valid: true}
+	}
+
+	attempts := 0
+	if status != domain.ReminderSkipped {
+		attempts = 1
+	}
+
+	_, err := r.db.Exec(`UPDATE reminders SET status = ?, sent_at = ?, attempts = attempts + ?, last_error = ?,
+			claimed_by = NULL, claim_expires = NULL
+		WHERE id = ? AND claimed_by = ?`,
+		status, sentAt, attempts, errMsg, id, owner)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "finish reminder", Err: err}
+	}
+	return nil
+}
+
+func (r *SQLiteReminderRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteSavedViewRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSavedViewRepository(dbPath string) (*SQLiteSavedViewRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	repo := &SQLiteSavedViewRepository{db: db}
+
+	// The migration only uses IF NOT EXISTS, so it is safe on every start.
+	err = repo.initSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func (r *SQLiteSavedViewRepository) initSchema() error {
+	sqlBytes, err := os.ReadFile("migrations/add_saved_views_table.sql")
+	if err != nil {
+		return &domain.DatabaseError{Operation: "read migration", Err: err}
+	}
+
+	_, err = r.db.Exec(string(sqlBytes))
+	if err != nil {
+		return &domain.DatabaseError{Operation: "init schema", Err: err}
+	}
+
+	return nil
+}
+
+const savedViewColumns = "id, user_id, name, query, sort, columns, shared, created_at, updated_at"
+
+func scanSavedView(row rowScanner) (domain.SavedView, error) {
+	var view domain.SavedView
+	var columns string
+	var createdAt, updatedAt int64
+
+	err := row.Scan(&view.ID, &view.UserID, &view.Name, &view.Query, &view.Sort, &columns, &view.Shared, &createdAt, &updatedAt)
+	if err != nil {
+		return domain.SavedView{}, err
+	}
+
+	if err := json.Unmarshal([]byte(columns), &view.Columns); err != nil {
+		return domain.SavedView{}, err
+	}
+	view.CreatedAt = time.UnixMilli(createdAt).UTC()
+	view.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+	return view, nil
+}
+
+func encodeColumns(columns []string) string {
+	if columns == nil {
+		columns = []string{}
+	}
+	data, _ := json.Marshal(columns)
+	return string(data)
+}
+
+func (r *SQLiteSavedViewRepository) Create(view domain.SavedView) (domain.SavedView, error) {
+	now := time.UnixMilli(time.Now().UnixMilli()).UTC()
+	view.CreatedAt, view.UpdatedAt = now, now
+
+	result, err := r.db.Exec(`INSERT INTO saved_views (user_id, name, query, sort, columns, shared, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		view.UserID, view.Name, view.Query, view.Sort, encodeColumns(view.Columns), view.Shared, now.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return domain.SavedView{}, &domain.DatabaseError{Operation: "insert saved view", Err: err}
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domain.SavedView{}, &domain.DatabaseError{Operation: "get last insert id", Err: err}
+	}
+
+	view.ID = int(id)
+	return view, nil
+}
+
+func (r *SQLiteSavedViewRepository) GetByID(id int) (domain.SavedView, error) {
+	row := r.db.QueryRow("SELECT "+savedViewColumns+" FROM saved_views WHERE id = ?", id)
+
+	view, err := scanSavedView(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.SavedView{}, &domain.NotFoundError{Resource: "View", ID: id}
+		}
+		return domain.SavedView{}, &domain.DatabaseError{Operation: "get saved view", Err: err}
+	}
+
+	return view, nil
+}
+
+func (r *SQLiteSavedViewRepository) ListVisible(userID int) ([]domain.SavedView, error) {
+	rows, err := r.db.Query(`SELECT `+savedViewColumns+` FROM saved_views
+		WHERE user_id = ? OR shared = 1
+		ORDER BY user_id != ?, name COLLATE NOCASE, id`, userID, userID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list saved views", Err: err}
+	}
+	defer rows.Close()
+
+	views := []domain.SavedView{}
+	for rows.Next() {
+		view, err := scanSavedView(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan saved view", Err: err}
+		}
+		views = append(views, view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate saved views", Err: err}
+	}
+
+	return views, nil
+}
+
+func (r *SQLiteSavedViewRepository) Update(view domain.SavedView) (domain.SavedView, error) {
+	view.UpdatedAt = time.UnixMilli(time.Now().UnixMilli()).UTC()
+
+	result, err := r.db.Exec(`UPDATE saved_views
+		SET name = ?, query = ?, sort = ?, columns = ?, shared = ?, updated_at = ?
+		WHERE id = ?`,
+		view.Name, view.Query, view.Sort, encodeColumns(view.Columns), view.Shared, view.UpdatedAt.UnixMilli(), view.ID)
+	if err != nil {
+		return domain.SavedView{}, &domain.DatabaseError{Operation: "update saved view", Err: err}
+	}
+
+	if err := requireAffectedResource(result, "View", view.ID); err != nil {
+		return domain.SavedView{}, err
+	}
+	return view, nil
+}
+
+func (r *SQLiteSavedViewRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM saved_views WHERE id = ?", id)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "delete saved view", Err: err}
+	}
+
+	return requireAffectedResource(result, "View", id)
+}
+
+func (r *SQLiteSavedViewRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
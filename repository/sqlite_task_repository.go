@@ -3,7 +3,10 @@ package repository
 import (
 	"database/sql"
 	"os"
+	"strings"
 	"task-manager-api/domain"
+	"task-manager-api/taskquery"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -12,6 +15,74 @@ type SQLiteTaskRepository struct {
 	db *sql.DB
 }
 
+// queryer is satisfied by both *sql.DB and *sql.Tx, so single-statement
+// helpers can run standalone or inside a batch transaction.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// taskColumns is the column list scanTask expects, in order.
+const taskColumns = "id, title, description, status, priority, version, updated_at, due_date, project_id, parent_id, auto_complete, estimate_minutes"
+
+// qualifiedColumns prefixes every column in a list such as taskColumns with
+// alias, for queries that join tables.
+func qualifiedColumns(alias, columnList string) string {
+	columns := strings.Split(columnList, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(row rowScanner) (domain.Task, error) {
+	var task domain.Task
+	var updatedAt int64
+	var dueDate, projectID, parentID sql.NullInt64
+
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority, &task.Version, &updatedAt, &dueDate, &projectID, &parentID, &task.AutoComplete, &task.EstimateMinutes)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	task.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+	if dueDate.Valid {
+		due := time.UnixMilli(dueDate.Int64).UTC()
+		task.DueDate = &due
+	}
+	task.ProjectID = int(projectID.Int64)
+	task.ParentID = int(parentID.Int64)
+	return task, nil
+}
+
+// nullableID stores an optional reference, where 0 means none, as NULL.
+func nullableID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
+
+// nullableMillis stores an optional time as unix milliseconds or NULL.
+func nullableMillis(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixMilli(), Valid: true}
+}
+
+// millisTime reads what nullableMillis stored.
+func millisTime(value sql.NullInt64) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := time.UnixMilli(value.Int64).UTC()
+	return &t
+}
+
 func NewSQLiteTaskRepository(dbPath string) (*SQLiteTaskRepository, error) {
 	dbExists := false
 
@@ -19,7 +90,7 @@ func NewSQLiteTaskRepository(dbPath string) (*SQLiteTaskRepository, error) {
 		dbExists = true
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, err
 	}
@@ -37,9 +108,122 @@ func NewSQLiteTaskRepository(dbPath string) (*SQLiteTaskRepository, error) {
 			return nil, &domain.DatabaseError{Operation: "init schema", Err: err}
 		}
 	}
+
+	// Databases created before a column existed are brought up to date on
+	// every start.
+	err = repo.migrate()
+	if err != nil {
+		return nil, err
+	}
+
 	return repo, nil
 }
 
+func (r *SQLiteTaskRepository) migrate() error {
+	err := addColumnIfMissing(r.db, "tasks", "version", "INTEGER NOT NULL DEFAULT 1")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(r.db, "tasks", "updated_at", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(r.db, "tasks", "due_date", "INTEGER")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(r.db, "tasks", "project_id", "INTEGER")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec("CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id)")
+	if err != nil {
+		return &domain.DatabaseError{Operation: "create project index", Err: err}
+	}
+
+	err = addColumnIfMissing(r.db, "tasks", "parent_id", "INTEGER")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(r.db, "tasks", "auto_complete", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+
+	err = addColumnIfMissing(r.db, "tasks", "estimate_minutes", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec("CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id)")
+	if err != nil {
+		return &domain.DatabaseError{Operation: "create parent index", Err: err}
+	}
+
+	_, err = r.db.Exec("UPDATE tasks SET updated_at = ? WHERE updated_at = 0", time.Now().UnixMilli())
+	if err != nil {
+		return &domain.DatabaseError{Operation: "backfill updated_at", Err: err}
+	}
+
+	err = r.migrateSearchIndex()
+	if err != nil {
+		return err
+	}
+
+	// Task reads join task_labels, task_assignees, checklist_items,
+	// task_dependencies, comments, users and recurrence_occurrences, so
+	// those tables must exist even if their repositories are never opened.
+	for _, path := range []string{
+		"migrations/add_labels_tables.sql",
+		"migrations/add_users_table.sql",
+		"migrations/add_task_assignees_table.sql",
+		"migrations/add_checklist_items_table.sql",
+		"migrations/add_task_dependencies_table.sql",
+		"migrations/add_comments_tables.sql",
+		"migrations/add_recurrences_tables.sql",
+		"migrations/add_task_list_changes_table.sql",
+	} {
+		if err := execMigration(r.db, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSearchIndex creates the FTS5 index and its triggers, indexing the
+// existing rows the first time.
+func (r *SQLiteTaskRepository) migrateSearchIndex() error {
+	var existing int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'tasks_fts'").Scan(&existing)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "inspect search index", Err: err}
+	}
+
+	sqlBytes, err := os.ReadFile("migrations/add_tasks_fts.sql")
+	if err != nil {
+		return &domain.DatabaseError{Operation: "read migration", Err: err}
+	}
+
+	_, err = r.db.Exec(string(sqlBytes))
+	if err != nil {
+		return &domain.DatabaseError{Operation: "create search index", Err: err}
+	}
+
+	if existing == 0 {
+		_, err = r.db.Exec("INSERT INTO tasks_fts (tasks_fts) VALUES ('rebuild')")
+		if err != nil {
+			return &domain.DatabaseError{Operation: "build search index", Err: err}
+		}
+	}
+
+	return nil
+}
+
 func (r *SQLiteTaskRepository) initSchema() error {
 	sqlBytes, err := os.ReadFile("migrations/init.sql")
 
@@ -59,21 +243,130 @@ func (r *SQLiteTaskRepository) initSchema() error {
 }
 
 func (r *SQLiteTaskRepository) GetAll() ([]domain.Task, error) {
-	query := "SELECT id, title, description, status, priority FROM tasks"
+	query := "SELECT " + taskColumns + " FROM tasks"
 
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, &domain.DatabaseError{Operation: "get all tasks", Err: err}
 	}
 
+	return r.scanTasks(rows)
+}
+
+// LastDeletedAt returns when a task was last deleted, or the zero time if
+// none ever was.
+func (r *SQLiteTaskRepository) LastDeletedAt() (time.Time, error) {
+	var deletedAt int64
+	err := r.db.QueryRow("SELECT deleted_at FROM task_list_changes WHERE id = 1").Scan(&deletedAt)
+	if err != nil {
+		return time.Time{}, &domain.DatabaseError{Operation: "get last task delete", Err: err}
+	}
+	if deletedAt == 0 {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(deletedAt).UTC(), nil
+}
+
+func (r *SQLiteTaskRepository) Find(filter *taskquery.Filter) ([]domain.Task, error) {
+	where, args := filter.SQL()
+	query := "SELECT " + taskColumns + " FROM tasks WHERE " + where
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "find tasks", Err: err}
+	}
+
+	return r.scanTasks(rows)
+}
+
+// scanTasks reads every row of a task query, closes rows and attaches each
+// task's labels and assignees.
+func (r *SQLiteTaskRepository) scanTasks(rows *sql.Rows) ([]domain.Task, error) {
+	tasks, err := scanTaskRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := attachTaskDetails(r.db, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// attachTaskDetails loads what tasks keep in other tables or derive from
+// their subtasks.
+func attachTaskDetails(q queryer, tasks []domain.Task) error {
+	if err := attachLabels(q, tasks); err != nil {
+		return err
+	}
+	if err := attachAssignees(q, tasks); err != nil {
+		return err
+	}
+	if err := attachChecklists(q, tasks); err != nil {
+		return err
+	}
+	if err := attachDependencies(q, tasks); err != nil {
+		return err
+	}
+	if err := attachCommentCounts(q, tasks); err != nil {
+		return err
+	}
+	if err := attachOccurrences(q, tasks); err != nil {
+		return err
+	}
+	return attachSubtaskCounts(q, tasks)
+}
+
+// attachSubtaskCounts counts each task's direct subtasks and how many of
+// them are completed.
+func attachSubtaskCounts(q queryer, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(tasks)+1)
+	args = append(args, domain.StatusCompleted)
+	for _, task := range tasks {
+		args = append(args, task.ID)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tasks)), ", ")
+
+	rows, err := q.Query(`SELECT parent_id, COUNT(*), SUM(status = ?) FROM tasks
+		WHERE parent_id IN (`+placeholders+`)
+		GROUP BY parent_id`, args...)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "count subtasks", Err: err}
+	}
+	defer rows.Close()
+
+	type counts struct{ total, done int }
+	byParent := make(map[int]counts)
+	for rows.Next() {
+		var parentID int
+		var c counts
+		if err := rows.Scan(&parentID, &c.total, &c.done); err != nil {
+			return &domain.DatabaseError{Operation: "scan subtask counts", Err: err}
+		}
+		byParent[parentID] = c
+	}
+	if err := rows.Err(); err != nil {
+		return &domain.DatabaseError{Operation: "iterate subtask counts", Err: err}
+	}
+
+	for i := range tasks {
+		c := byParent[tasks[i].ID]
+		tasks[i].Subtasks, tasks[i].SubtasksDone = c.total, c.done
+	}
+	return nil
+}
+
+func scanTaskRows(rows *sql.Rows) ([]domain.Task, error) {
 	defer rows.Close()
 
 	tasks := []domain.Task{}
 
 	for rows.Next() {
-		var task domain.Task
-
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, &domain.DatabaseError{Operation: "scan task row", Err: err}
 		}
@@ -81,7 +374,7 @@ func (r *SQLiteTaskRepository) GetAll() ([]domain.Task, error) {
 		tasks = append(tasks, task)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, &domain.DatabaseError{Operation: "iterate task rows", Err: err}
 	}
 
@@ -89,9 +382,18 @@ func (r *SQLiteTaskRepository) GetAll() ([]domain.Task, error) {
 }
 
 func (r *SQLiteTaskRepository) Create(task domain.Task) (domain.Task, error) {
-	insertSQL := `INSERT INTO tasks (title, description, status, priority) VALUES (?, ?, ?, ?)`
+	return insertTask(r.db, task)
+}
+
+func insertTask(q queryer, task domain.Task) (domain.Task, error) {
+	insertSQL := `INSERT INTO tasks (title, description, status, priority, version, updated_at, due_date, project_id, parent_id, auto_complete, estimate_minutes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(insertSQL, task.Title, task.Description, task.Status, task.Priority)
+	task.Version = 1
+	task.UpdatedAt = time.UnixMilli(time.Now().UnixMilli()).UTC()
+
+	result, err := q.Exec(insertSQL, task.Title, task.Description, task.Status, task.Priority, task.Version, task.UpdatedAt.UnixMilli(),
+		nullableMillis(task.DueDate), nullableID(task.ProjectID), nullableID(task.ParentID), task.AutoComplete, task.EstimateMinutes)
 	if err != nil {
 		return domain.Task{}, &domain.DatabaseError{Operation: "insert task", Err: err}
 	}
@@ -107,13 +409,11 @@ func (r *SQLiteTaskRepository) Create(task domain.Task) (domain.Task, error) {
 }
 
 func (r *SQLiteTaskRepository) GetByID(id int) (domain.Task, error) {
-	query := "SELECT * FROM tasks WHERE id = ?"
+	query := "SELECT " + taskColumns + " FROM tasks WHERE id = ?"
 
 	row := r.db.QueryRow(query, id)
 
-	var task domain.Task
-
-	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority)
+	task, err := scanTask(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -122,7 +422,11 @@ func (r *SQLiteTaskRepository) GetByID(id int) (domain.Task, error) {
 		return domain.Task{}, &domain.DatabaseError{Operation: "get task by id", Err: err}
 	}
 
-	return task, nil
+	tasks := []domain.Task{task}
+	if err := attachTaskDetails(r.db, tasks); err != nil {
+		return domain.Task{}, err
+	}
+	return tasks[0], nil
 }
 
 func (r *SQLiteTaskRepository) Update(updatedTask domain.Task) (domain.Task, error) {
@@ -132,14 +436,34 @@ func (r *SQLiteTaskRepository) Update(updatedTask domain.Task) (domain.Task, err
 		return domain.Task{}, &domain.DatabaseError{Operation: "get task by id before update", Err: err}
 	}
 
-	query := "UPDATE tasks SET title = ?, description = ?, status = ?, priority = ? WHERE id = ?"
+	return updateTask(r.db, updatedTask)
+}
 
-	_, err = r.db.Exec(query, updatedTask.Title, updatedTask.Description, updatedTask.Status, updatedTask.Priority, updatedTask.ID)
+// updateTask writes task's fields, bumping its version and updated_at, and
+// returns the task as stored.
+func updateTask(q queryer, task domain.Task) (domain.Task, error) {
+	query := `UPDATE tasks SET title = ?, description = ?, status = ?, priority = ?, due_date = ?, project_id = ?,
+		parent_id = ?, auto_complete = ?, estimate_minutes = ?, version = version + 1, updated_at = ?
+		WHERE id = ? RETURNING version, updated_at`
+
+	var updatedAt int64
+	err := q.QueryRow(query, task.Title, task.Description, task.Status, task.Priority, nullableMillis(task.DueDate), nullableID(task.ProjectID),
+		nullableID(task.ParentID), task.AutoComplete, task.EstimateMinutes, time.Now().UnixMilli(), task.ID).
+		Scan(&task.Version, &updatedAt)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Task{}, &domain.NotFoundError{Resource: "Task", ID: task.ID}
+		}
 		return domain.Task{}, &domain.DatabaseError{Operation: "update task", Err: err}
 	}
 
-	return updatedTask, nil
+	task.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+
+	tasks := []domain.Task{task}
+	if err := attachTaskDetails(q, tasks); err != nil {
+		return domain.Task{}, err
+	}
+	return tasks[0], nil
 }
 
 func (r *SQLiteTaskRepository) Delete(id int) error {
@@ -159,6 +483,93 @@ func (r *SQLiteTaskRepository) Delete(id int) error {
 	return nil
 }
 
+// ApplyBatch runs every operation inside one transaction. In atomic mode the
+// first failure rolls the whole batch back; otherwise each operation runs in
+// its own savepoint so failures are undone individually and the rest commit.
+func (r *SQLiteTaskRepository) ApplyBatch(ops []domain.TaskOperation, atomic bool) ([]domain.TaskOperationResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "begin batch", Err: err}
+	}
+	defer tx.Rollback()
+
+	results := make([]domain.TaskOperationResult, len(ops))
+
+	for i, op := range ops {
+		if !atomic {
+			if _, err := tx.Exec("SAVEPOINT batch_op"); err != nil {
+				return nil, &domain.DatabaseError{Operation: "create savepoint", Err: err}
+			}
+		}
+
+		task, opErr := applyTaskOperation(tx, op)
+		if opErr != nil {
+			results[i].Err = opErr
+
+			if atomic {
+				abortBatch(results, i)
+				return results, nil
+			}
+
+			if _, err := tx.Exec("ROLLBACK TO batch_op"); err != nil {
+				return nil, &domain.DatabaseError{Operation: "rollback savepoint", Err: err}
+			}
+		}
+
+		if !atomic {
+			if _, err := tx.Exec("RELEASE batch_op"); err != nil {
+				return nil, &domain.DatabaseError{Operation: "release savepoint", Err: err}
+			}
+		}
+
+		if opErr == nil {
+			results[i] = domain.TaskOperationResult{Task: task, Applied: true}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "commit batch", Err: err}
+	}
+
+	return results, nil
+}
+
+func applyTaskOperation(q queryer, op domain.TaskOperation) (domain.Task, error) {
+	switch op.Kind {
+	case domain.OperationCreate:
+		return insertTask(q, op.Task)
+
+	case domain.OperationUpdate:
+		return updateTask(q, op.Task)
+
+	case domain.OperationDelete:
+		result, err := q.Exec("DELETE FROM tasks WHERE id = ?", op.Task.ID)
+		if err != nil {
+			return domain.Task{}, &domain.DatabaseError{Operation: "delete task", Err: err}
+		}
+		return op.Task, requireAffected(result, op.Task.ID)
+	}
+
+	return domain.Task{}, &domain.ValidationError{Field: "op", Message: "unsupported operation " + op.Kind}
+}
+
+func requireAffected(result sql.Result, id int) error {
+	return requireAffectedResource(result, "Task", id)
+}
+
+// requireAffectedResource turns an UPDATE or DELETE that matched no rows
+// into a NotFoundError for resource.
+func requireAffectedResource(result sql.Result, resource string, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return &domain.DatabaseError{Operation: "rows affected", Err: err}
+	}
+	if affected == 0 {
+		return &domain.NotFoundError{Resource: resource, ID: id}
+	}
+	return nil
+}
+
 func (r *SQLiteTaskRepository) Close() error {
 	if r.db != nil {
 		return r.db.Close()
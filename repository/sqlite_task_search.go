@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"task-manager-api/domain"
+	"task-manager-api/search"
+	"task-manager-api/taskquery"
+)
+
+// snippetTokens is how many tokens of the description a snippet shows.
+const snippetTokens = 16
+
+// Search ranks tasks matching query with BM25, weighting title matches ten
+// times higher than description matches, and returns one page of hits along
+// with the total number of matches. Only tasks matching scope are searched.
+func (r *SQLiteTaskRepository) Search(query search.Node, scope *taskquery.Filter, limit, offset int) ([]domain.TaskSearchHit, int, error) {
+	match := query.FTS5()
+	scopeSQL, scopeArgs := scope.SQL()
+	inScope := "tasks_fts.rowid IN (SELECT id FROM tasks WHERE " + scopeSQL + ")"
+
+	var total int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM tasks_fts WHERE tasks_fts MATCH ? AND "+inScope,
+		append([]interface{}{match}, scopeArgs...)...).Scan(&total)
+	if err != nil {
+		return nil, 0, &domain.DatabaseError{Operation: "count search results", Err: err}
+	}
+	if total == 0 {
+		return []domain.TaskSearchHit{}, 0, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+qualifiedColumns("t", taskColumns)+`,
+			bm25(tasks_fts, 10.0, 1.0) AS rank,
+			highlight(tasks_fts, 0, ?, ?),
+			snippet(tasks_fts, 1, ?, ?, ?, ?)
+		FROM tasks_fts JOIN tasks t ON t.id = tasks_fts.rowid
+		WHERE tasks_fts MATCH ? AND `+inScope+`
+		ORDER BY rank, t.id
+		LIMIT ? OFFSET ?`,
+		append(append([]interface{}{
+			search.MarkStart, search.MarkEnd,
+			search.MarkStart, search.MarkEnd, search.Ellipsis, snippetTokens,
+			match}, scopeArgs...), limit, offset)...)
+	if err != nil {
+		return nil, 0, &domain.DatabaseError{Operation: "search tasks", Err: err}
+	}
+	defer rows.Close()
+
+	hits := []domain.TaskSearchHit{}
+	for rows.Next() {
+		var hit domain.TaskSearchHit
+		var rank float64
+
+		hit.Task, err = scanTask(searchRow{rows, []interface{}{&rank, &hit.TitleHighlight, &hit.Snippet}})
+		if err != nil {
+			return nil, 0, &domain.DatabaseError{Operation: "scan search row", Err: err}
+		}
+
+		// bm25() is lower for better matches; report it the other way round.
+		hit.Score = -rank
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, &domain.DatabaseError{Operation: "iterate search rows", Err: err}
+	}
+	rows.Close()
+
+	tasks := make([]domain.Task, len(hits))
+	for i, hit := range hits {
+		tasks[i] = hit.Task
+	}
+	if err := attachTaskDetails(r.db, tasks); err != nil {
+		return nil, 0, err
+	}
+	for i := range hits {
+		hits[i].Task = tasks[i]
+	}
+
+	return hits, total, nil
+}
+
+// searchRow appends extra destinations after the task columns so scanTask
+// can read rows carrying additional computed columns.
+type searchRow struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s searchRow) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
@@ -11,13 +11,7 @@ type SQLiteUserRepository struct {
 }
 
 func NewSQLiteUserRepository(dbPath string) (*SQLiteUserRepository, error) {
-	dbExists := false
-
-	if _, err := os.Stat(dbPath); err == nil {
-		dbExists = true
-	}
-
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, &domain.DatabaseError{Operation: "open", Err: err}
 	}
@@ -29,11 +23,12 @@ func NewSQLiteUserRepository(dbPath string) (*SQLiteUserRepository, error) {
 
 	repo := &SQLiteUserRepository{db: db}
 
-	if !dbExists {
-		err = repo.initSchema()
-		if err != nil {
-			return nil, err
-		}
+	// The task repository creates the database file first, so the users
+	// table can't be tied to the file being new. The migration only uses
+	// IF NOT EXISTS, so it is safe on every start.
+	err = repo.initSchema()
+	if err != nil {
+		return nil, err
 	}
 
 	return repo, nil
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWebhookRepository(dbPath string) (*SQLiteWebhookRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_webhooks_tables.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteWebhookRepository{db: db}, nil
+}
+
+const webhookColumns = "id, owner_id, url, secret, events, active, failures, disabled_at, created_at, updated_at"
+
+func scanWebhook(row rowScanner) (domain.Webhook, error) {
+	var hook domain.Webhook
+	var events string
+	var disabledAt sql.NullInt64
+	var createdAt, updatedAt int64
+
+	err := row.Scan(&hook.ID, &hook.OwnerID, &hook.URL, &hook.Secret, &events, &hook.Active, &hook.Failures,
+		&disabledAt, &createdAt, &updatedAt)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	hook.Events = strings.Split(events, ",")
+	hook.DisabledAt = millisTime(disabledAt)
+	hook.CreatedAt = time.UnixMilli(createdAt).UTC()
+	hook.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+	return hook, nil
+}
+
+func (r *SQLiteWebhookRepository) listWebhooks(where string, args ...interface{}) ([]domain.Webhook, error) {
+	rows, err := r.db.Query("SELECT "+webhookColumns+" FROM webhooks WHERE "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list webhooks", Err: err}
+	}
+	defer rows.Close()
+
+	hooks := []domain.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan webhook", Err: err}
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate webhooks", Err: err}
+	}
+	return hooks, nil
+}
+
+func (r *SQLiteWebhookRepository) Create(hook domain.Webhook) (domain.Webhook, error) {
+	now := time.Now().UnixMilli()
+
+	created, err := scanWebhook(r.db.QueryRow(`INSERT INTO webhooks (owner_id, url, secret, events, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 1, ?, ?)
+		RETURNING `+webhookColumns,
+		hook.OwnerID, hook.URL, hook.Secret, strings.Join(hook.Events, ","), now, now))
+	if err != nil {
+		return domain.Webhook{}, &domain.DatabaseError{Operation: "insert webhook", Err: err}
+	}
+	return created, nil
+}
+
+func (r *SQLiteWebhookRepository) GetByID(id int) (domain.Webhook, error) {
+	hook, err := scanWebhook(r.db.QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Webhook{}, &domain.NotFoundError{Resource: "Webhook", ID: id}
+		}
+		return domain.Webhook{}, &domain.DatabaseError{Operation: "get webhook", Err: err}
+	}
+	return hook, nil
+}
+
+func (r *SQLiteWebhookRepository) ListByOwner(ownerID int) ([]domain.Webhook, error) {
+	return r.listWebhooks("owner_id = ?", ownerID)
+}
+
+func (r *SQLiteWebhookRepository) ListActive() ([]domain.Webhook, error) {
+	return r.listWebhooks("active = 1")
+}
+
+func (r *SQLiteWebhookRepository) Update(hook domain.Webhook) (domain.Webhook, error) {
+	updated, err := scanWebhook(r.db.QueryRow(`UPDATE webhooks SET url = ?, events = ?, active = ?, failures = ?, disabled_at = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING `+webhookColumns,
+		hook.URL, strings.Join(hook.Events, ","), hook.Active, hook.Failures, nullableMillis(hook.DisabledAt),
+		time.Now().UnixMilli(), hook.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Webhook{}, &domain.NotFoundError{Resource: "Webhook", ID: hook.ID}
+		}
+		return domain.Webhook{}, &domain.DatabaseError{Operation: "update webhook", Err: err}
+	}
+	return updated, nil
+}
+
+func (r *SQLiteWebhookRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "delete webhook", Err: err}
+	}
+	return requireAffectedResource(result, "Webhook", id)
+}
+
+const deliveryColumns = `id, webhook_id, event, payload, status, attempts, next_attempt_at, response_code, last_error,
+	redelivery_of, delivered_at, created_at`
+
+func scanDelivery(row rowScanner) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var payload string
+	var nextAttemptAt, responseCode, redeliveryOf, deliveredAt sql.NullInt64
+	var createdAt int64
+
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.Event, &payload, &delivery.Status, &delivery.Attempts,
+		&nextAttemptAt, &responseCode, &delivery.LastError, &redeliveryOf, &deliveredAt, &createdAt)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery.Payload = []byte(payload)
+	delivery.NextAttemptAt = millisTime(nextAttemptAt)
+	delivery.ResponseCode = int(responseCode.Int64)
+	delivery.RedeliveryOf = int(redeliveryOf.Int64)
+	delivery.DeliveredAt = millisTime(deliveredAt)
+	delivery.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return delivery, nil
+}
+
+func (r *SQLiteWebhookRepository) Enqueue(delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	now := time.Now().UnixMilli()
+
+	queued, err := scanDelivery(r.db.QueryRow(`INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at, redelivery_of, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+deliveryColumns,
+		delivery.WebhookID, delivery.Event, string(delivery.Payload), domain.DeliveryPending, now,
+		nullableID(delivery.RedeliveryOf), now))
+	if err != nil {
+		return domain.WebhookDelivery{}, &domain.DatabaseError{Operation: "queue webhook delivery", Err: err}
+	}
+	return queued, nil
+}
+
+func (r *SQLiteWebhookRepository) GetDelivery(id int) (domain.WebhookDelivery, error) {
+	delivery, err := scanDelivery(r.db.QueryRow("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.WebhookDelivery{}, &domain.NotFoundError{Resource: "Delivery", ID: id}
+		}
+		return domain.WebhookDelivery{}, &domain.DatabaseError{Operation: "get webhook delivery", Err: err}
+	}
+
+	rows, err := r.db.Query(`SELECT id, delivery_id, response_code, error, duration_ms, attempted_at
+		FROM webhook_attempts WHERE delivery_id = ? ORDER BY id`, id)
+	if err != nil {
+		return domain.WebhookDelivery{}, &domain.DatabaseError{Operation: "list webhook attempts", Err: err}
+	}
+	defer rows.Close()
+
+	delivery.Log = []domain.WebhookAttempt{}
+	for rows.Next() {
+		var attempt domain.WebhookAttempt
+		var responseCode sql.NullInt64
+		var attemptedAt int64
+		if err := rows.Scan(&attempt.ID, &attempt.DeliveryID, &responseCode, &attempt.Error, &attempt.DurationMS, &attemptedAt); err != nil {
+			return domain.WebhookDelivery{}, &domain.DatabaseError{Operation: "scan webhook attempt", Err: err}
+		}
+		attempt.ResponseCode = int(responseCode.Int64)
+		attempt.AttemptedAt = time.UnixMilli(attemptedAt).UTC()
+		delivery.Log = append(delivery.Log, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.WebhookDelivery{}, &domain.DatabaseError{Operation: "iterate webhook attempts", Err: err}
+	}
+	return delivery, nil
+}
+
+func (r *SQLiteWebhookRepository) ListDeliveries(webhookID, limit int) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.Query("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?",
+		webhookID, limit)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list webhook deliveries", Err: err}
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan webhook delivery", Err: err}
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate webhook deliveries", Err: err}
+	}
+	return deliveries, nil
+}
+
+// Claim relies on SQLite running the UPDATE, subquery included, under the
+// database write lock: two instances can't select the same delivery.
+func (r *SQLiteWebhookRepository) Claim(owner string, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	nowMillis := now.UnixMilli()
+
+	rows, err := r.db.Query(`UPDATE webhook_deliveries SET claimed_by = ?, claim_expires = ?
+		WHERE id IN (
+			SELECT d.id FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = ? AND w.active = 1
+				AND d.next_attempt_at <= ?
+				AND (d.claim_expires IS NULL OR d.claim_expires < ?)
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT ?)
+		RETURNING `+deliveryColumns,
+		owner, now.Add(lease).UnixMilli(), domain.DeliveryPending, nowMillis, nowMillis, limit)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "claim webhook deliveries", Err: err}
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan claimed delivery", Err: err}
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate claimed deliveries", Err: err}
+	}
+	return deliveries, nil
+}
+
+func (r *SQLiteWebhookRepository) RecordAttempt(owner string, attempt domain.WebhookAttempt, status string, retryAt *time.Time) error {
+	return inTx(r.db, "record webhook attempt", func(tx *sql.Tx) error {
+		attemptedAt := attempt.AttemptedAt.UnixMilli()
+
+		var deliveredAt sql.NullInt64
+		if status == domain.DeliverySucceeded {
+			deliveredAt = sql.NullInt64{Int64: attemptedAt, Valid: true}
+		}
+
+		result, err := tx.Exec(`UPDATE webhook_deliveries SET status = ?, attempts = attempts + 1, next_attempt_at = ?,
+				response_code = ?, last_error = ?, delivered_at = ?, claimed_by = NULL, claim_expires = NULL
+			WHERE id = ? AND claimed_by = ?`,
+			status, nullableMillis(retryAt), nullableID(attempt.ResponseCode), attempt.Error, deliveredAt,
+			attempt.DeliveryID, owner)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "update webhook delivery", Err: err}
+		}
+		if changed, err := changedRows(result); err != nil || !changed {
+			return err // the claim expired and another instance took over
+		}
+
+		_, err = tx.Exec(`INSERT INTO webhook_attempts (delivery_id, response_code, error, duration_ms, attempted_at)
+			VALUES (?, ?, ?, ?, ?)`,
+			attempt.DeliveryID, nullableID(attempt.ResponseCode), attempt.Error, attempt.DurationMS, attemptedAt)
+		if err != nil {
+			return &domain.DatabaseError{Operation: "insert webhook attempt", Err: err}
+		}
+		return nil
+	})
+}
+
+func (r *SQLiteWebhookRepository) TrackHealth(id int, ok bool, disableAfter int) (domain.Webhook, error) {
+	now := time.Now().UnixMilli()
+
+	query := `UPDATE webhooks SET failures = 0 WHERE id = ? RETURNING ` + webhookColumns
+	args := []interface{}{id}
+	if !ok {
+		query = `UPDATE webhooks SET failures = failures + 1,
+				active = CASE WHEN failures + 1 >= ? THEN 0 ELSE active END,
+				disabled_at = CASE WHEN failures + 1 >= ? AND active = 1 THEN ? ELSE disabled_at END,
+				updated_at = CASE WHEN failures + 1 >= ? AND active = 1 THEN ? ELSE updated_at END
+			WHERE id = ?
+			RETURNING ` + webhookColumns
+		args = []interface{}{disableAfter, disableAfter, now, disableAfter, now, id}
+	}
+
+	hook, err := scanWebhook(r.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Webhook{}, &domain.NotFoundError{Resource: "Webhook", ID: id}
+		}
+		return domain.Webhook{}, &domain.DatabaseError{Operation: "track webhook health", Err: err}
+	}
+	return hook, nil
+}
+
+func (r *SQLiteWebhookRepository) PruneDeliveries(before time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM webhook_deliveries WHERE status <> ? AND created_at < ?",
+		domain.DeliveryPending, before.UnixMilli())
+	if err != nil {
+		return 0, &domain.DatabaseError{Operation: "prune webhook deliveries", Err: err}
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, &domain.DatabaseError{Operation: "rows affected", Err: err}
+	}
+	return int(n), nil
+}
+
+func (r *SQLiteWebhookRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"database/sql"
+	"task-manager-api/domain"
+	"time"
+)
+
+type SQLiteWorkspaceRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteWorkspaceRepository(dbPath string) (*SQLiteWorkspaceRepository, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "open", Err: err}
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "ping", Err: err}
+	}
+
+	err = execMigration(db, "migrations/add_workspaces_tables.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteWorkspaceRepository{db: db}, nil
+}
+
+func nowMillis() time.Time {
+	return time.UnixMilli(time.Now().UnixMilli()).UTC()
+}
+
+func (r *SQLiteWorkspaceRepository) CreateWorkspace(workspace domain.Workspace, ownerID int) (domain.Workspace, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Workspace{}, &domain.DatabaseError{Operation: "begin create workspace", Err: err}
+	}
+	defer tx.Rollback()
+
+	workspace.CreatedBy = ownerID
+	workspace.CreatedAt = nowMillis()
+
+	result, err := tx.Exec("INSERT INTO workspaces (name, created_by, created_at) VALUES (?, ?, ?)",
+		workspace.Name, workspace.CreatedBy, workspace.CreatedAt.UnixMilli())
+	if err != nil {
+		return domain.Workspace{}, &domain.DatabaseError{Operation: "insert workspace", Err: err}
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domain.Workspace{}, &domain.DatabaseError{Operation: "get last insert id", Err: err}
+	}
+	workspace.ID = int(id)
+
+	_, err = tx.Exec("INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?)",
+		workspace.ID, ownerID, domain.RoleOwner, workspace.CreatedAt.UnixMilli())
+	if err != nil {
+		return domain.Workspace{}, &domain.DatabaseError{Operation: "insert workspace owner", Err: err}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Workspace{}, &domain.DatabaseError{Operation: "commit create workspace", Err: err}
+	}
+	return workspace, nil
+}
+
+const workspaceColumns = "id, name, created_by, created_at"
+
+func scanWorkspace(row rowScanner) (domain.Workspace, error) {
+	var workspace domain.Workspace
+	var createdAt int64
+
+	if err := row.Scan(&workspace.ID, &workspace.Name, &workspace.CreatedBy, &createdAt); err != nil {
+		return domain.Workspace{}, err
+	}
+
+	workspace.CreatedAt = time.UnixMilli(createdAt).UTC()
+	return workspace, nil
+}
+
+func (r *SQLiteWorkspaceRepository) GetWorkspace(id int) (domain.Workspace, error) {
+	workspace, err := scanWorkspace(r.db.QueryRow("SELECT "+workspaceColumns+" FROM workspaces WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Workspace{}, &domain.NotFoundError{Resource: "Workspace", ID: id}
+		}
+		return domain.Workspace{}, &domain.DatabaseError{Operation: "get workspace", Err: err}
+	}
+	return workspace, nil
+}
+
+func (r *SQLiteWorkspaceRepository) ListWorkspaces(userID int) ([]domain.Workspace, error) {
+	rows, err := r.db.Query(`SELECT `+qualifiedColumns("w", workspaceColumns)+`
+		FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = ?
+		ORDER BY w.name COLLATE NOCASE, w.id`, userID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list workspaces", Err: err}
+	}
+	defer rows.Close()
+
+	workspaces := []domain.Workspace{}
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan workspace", Err: err}
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate workspaces", Err: err}
+	}
+	return workspaces, nil
+}
+
+func (r *SQLiteWorkspaceRepository) MemberRole(workspaceID, userID int) (string, error) {
+	var role string
+	err := r.db.QueryRow("SELECT role FROM workspace_members WHERE workspace_id = ? AND user_id = ?", workspaceID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", &domain.DatabaseError{Operation: "get member role", Err: err}
+	}
+	return role, nil
+}
+
+func (r *SQLiteWorkspaceRepository) ListMembers(workspaceID int) ([]domain.WorkspaceMember, error) {
+	rows, err := r.db.Query(`SELECT m.workspace_id, m.user_id, COALESCE(u.email, ''), m.role, m.created_at
+		FROM workspace_members m LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = ?
+		ORDER BY m.created_at, m.user_id`, workspaceID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list members", Err: err}
+	}
+	defer rows.Close()
+
+	members := []domain.WorkspaceMember{}
+	for rows.Next() {
+		var member domain.WorkspaceMember
+		var createdAt int64
+		if err := rows.Scan(&member.WorkspaceID, &member.UserID, &member.Email, &member.Role, &createdAt); err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan member", Err: err}
+		}
+		member.CreatedAt = time.UnixMilli(createdAt).UTC()
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate members", Err: err}
+	}
+	return members, nil
+}
+
+func (r *SQLiteWorkspaceRepository) SetMember(member domain.WorkspaceMember) error {
+	_, err := r.db.Exec(`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = excluded.role`,
+		member.WorkspaceID, member.UserID, member.Role, time.Now().UnixMilli())
+	if err != nil {
+		return &domain.DatabaseError{Operation: "set member", Err: err}
+	}
+	return nil
+}
+
+func (r *SQLiteWorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	result, err := r.db.Exec("DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?", workspaceID, userID)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "remove member", Err: err}
+	}
+	return requireAffectedResource(result, "Member", userID)
+}
+
+func (r *SQLiteWorkspaceRepository) CountOwners(workspaceID int) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM workspace_members WHERE workspace_id = ? AND role = ?", workspaceID, domain.RoleOwner).Scan(&count)
+	if err != nil {
+		return 0, &domain.DatabaseError{Operation: "count owners", Err: err}
+	}
+	return count, nil
+}
+
+const projectColumns = "id, workspace_id, name, description, archived_at, created_at, updated_at"
+
+func scanProject(row rowScanner) (domain.Project, error) {
+	var project domain.Project
+	var archivedAt sql.NullInt64
+	var createdAt, updatedAt int64
+
+	err := row.Scan(&project.ID, &project.WorkspaceID, &project.Name, &project.Description, &archivedAt, &createdAt, &updatedAt)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	if archivedAt.Valid {
+		archived := time.UnixMilli(archivedAt.Int64).UTC()
+		project.ArchivedAt = &archived
+	}
+	project.CreatedAt = time.UnixMilli(createdAt).UTC()
+	project.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+	return project, nil
+}
+
+func (r *SQLiteWorkspaceRepository) CreateProject(project domain.Project) (domain.Project, error) {
+	now := nowMillis()
+	project.CreatedAt, project.UpdatedAt = now, now
+
+	result, err := r.db.Exec(`INSERT INTO projects (workspace_id, name, description, archived_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		project.WorkspaceID, project.Name, project.Description, nullableMillis(project.ArchivedAt), now.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return domain.Project{}, &domain.DatabaseError{Operation: "insert project", Err: err}
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return domain.Project{}, &domain.DatabaseError{Operation: "get last insert id", Err: err}
+	}
+
+	project.ID = int(id)
+	return project, nil
+}
+
+func (r *SQLiteWorkspaceRepository) GetProject(id int) (domain.Project, error) {
+	project, err := scanProject(r.db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Project{}, &domain.NotFoundError{Resource: "Project", ID: id}
+		}
+		return domain.Project{}, &domain.DatabaseError{Operation: "get project", Err: err}
+	}
+	return project, nil
+}
+
+func (r *SQLiteWorkspaceRepository) ListProjects(workspaceID int, includeArchived bool) ([]domain.Project, error) {
+	query := "SELECT " + projectColumns + " FROM projects WHERE workspace_id = ?"
+	if !includeArchived {
+		query += " AND archived_at IS NULL"
+	}
+	query += " ORDER BY name COLLATE NOCASE, id"
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list projects", Err: err}
+	}
+	defer rows.Close()
+
+	projects := []domain.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan project", Err: err}
+		}
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate projects", Err: err}
+	}
+	return projects, nil
+}
+
+func (r *SQLiteWorkspaceRepository) UpdateProject(project domain.Project) (domain.Project, error) {
+	project.UpdatedAt = nowMillis()
+
+	result, err := r.db.Exec(`UPDATE projects SET name = ?, description = ?, archived_at = ?, updated_at = ? WHERE id = ?`,
+		project.Name, project.Description, nullableMillis(project.ArchivedAt), project.UpdatedAt.UnixMilli(), project.ID)
+	if err != nil {
+		return domain.Project{}, &domain.DatabaseError{Operation: "update project", Err: err}
+	}
+	if err := requireAffectedResource(result, "Project", project.ID); err != nil {
+		return domain.Project{}, err
+	}
+	return project, nil
+}
+
+func (r *SQLiteWorkspaceRepository) DeleteProject(id int) error {
+	result, err := r.db.Exec("DELETE FROM projects WHERE id = ?", id)
+	if err != nil {
+		return &domain.DatabaseError{Operation: "delete project", Err: err}
+	}
+	return requireAffectedResource(result, "Project", id)
+}
+
+func (r *SQLiteWorkspaceRepository) CountProjectTasks(id int) (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM tasks WHERE project_id = ?", id).Scan(&count); err != nil {
+		return 0, &domain.DatabaseError{Operation: "count project tasks", Err: err}
+	}
+	return count, nil
+}
+
+func (r *SQLiteWorkspaceRepository) ProjectIDsForUser(userID int) ([]int, error) {
+	rows, err := r.db.Query(`SELECT p.id FROM projects p
+		JOIN workspace_members m ON m.workspace_id = p.workspace_id
+		WHERE m.user_id = ?`, userID)
+	if err != nil {
+		return nil, &domain.DatabaseError{Operation: "list user projects", Err: err}
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, &domain.DatabaseError{Operation: "scan project id", Err: err}
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &domain.DatabaseError{Operation: "iterate user projects", Err: err}
+	}
+	return ids, nil
+}
+
+func (r *SQLiteWorkspaceRepository) Close() error {
+	if r.db != nil {
+		return r.db.Close()
+	}
+	return nil
+}
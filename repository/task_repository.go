@@ -1,21 +1,35 @@
 package repository
 
 import (
+	"sort"
 	"task-manager-api/domain"
+	"task-manager-api/search"
+	"task-manager-api/taskquery"
+	"time"
 )
 
 type TaskRepository interface {
 	GetAll() ([]domain.Task, error)
+	// Find returns the tasks matching filter.
+	Find(filter *taskquery.Filter) ([]domain.Task, error)
 	Create(task domain.Task) (domain.Task, error)
 	GetByID(id int) (domain.Task, error)
 	Update(task domain.Task) (domain.Task, error)
 	Delete(id int) error
+	// LastDeletedAt returns when a task was last deleted, by any means, or
+	// the zero time if none ever was.
+	LastDeletedAt() (time.Time, error)
+	ApplyBatch(ops []domain.TaskOperation, atomic bool) ([]domain.TaskOperationResult, error)
+	// Search returns one page of tasks matching query and scope, best match
+	// first, and the total number of matches.
+	Search(query search.Node, scope *taskquery.Filter, limit, offset int) ([]domain.TaskSearchHit, int, error)
 	Close() error
 }
 
 type InMemoryTaskRepository struct {
-	tasks  []domain.Task
-	nextID int
+	tasks       []domain.Task
+	nextID      int
+	lastDeleted time.Time
 }
 
 func NewInMemoryTaskRepository() *InMemoryTaskRepository {
@@ -31,6 +45,8 @@ func NewInMemoryTaskRepository() *InMemoryTaskRepository {
 
 func (r *InMemoryTaskRepository) Create(task domain.Task) (domain.Task, error) {
 	task.ID = r.nextID
+	task.Version = 1
+	task.UpdatedAt = time.Now().UTC()
 	r.nextID++
 	r.tasks = append(r.tasks, task)
 	return task, nil
@@ -40,6 +56,16 @@ func (r *InMemoryTaskRepository) GetAll() ([]domain.Task, error) {
 	return r.tasks, nil
 }
 
+func (r *InMemoryTaskRepository) Find(filter *taskquery.Filter) ([]domain.Task, error) {
+	tasks := []domain.Task{}
+	for _, task := range r.tasks {
+		if filter.Match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
 func (r *InMemoryTaskRepository) GetByID(id int) (domain.Task, error) {
 	for _, task := range r.tasks {
 		if task.ID == id {
@@ -53,6 +79,8 @@ func (r *InMemoryTaskRepository) GetByID(id int) (domain.Task, error) {
 func (r *InMemoryTaskRepository) Update(updatedTask domain.Task) (domain.Task, error) {
 	for i, task := range r.tasks {
 		if task.ID == updatedTask.ID {
+			updatedTask.Version = task.Version + 1
+			updatedTask.UpdatedAt = time.Now().UTC()
 			r.tasks[i] = updatedTask
 			return updatedTask, nil
 		}
@@ -65,6 +93,7 @@ func (r *InMemoryTaskRepository) Delete(id int) error {
 	for i, task := range r.tasks {
 		if task.ID == id {
 			r.tasks = append(r.tasks[:i], r.tasks[i+1:]...)
+			r.lastDeleted = time.Now().UTC()
 			return nil
 		}
 	}
@@ -72,6 +101,105 @@ func (r *InMemoryTaskRepository) Delete(id int) error {
 	return &domain.NotFoundError{Resource: "Task", ID: id}
 }
 
+func (r *InMemoryTaskRepository) LastDeletedAt() (time.Time, error) {
+	return r.lastDeleted, nil
+}
+
+// ApplyBatch applies operations in order. In atomic mode a failure restores
+// the snapshot taken before the batch started.
+func (r *InMemoryTaskRepository) ApplyBatch(ops []domain.TaskOperation, atomic bool) ([]domain.TaskOperationResult, error) {
+	snapshot := append([]domain.Task(nil), r.tasks...)
+	snapshotNextID := r.nextID
+
+	results := make([]domain.TaskOperationResult, len(ops))
+
+	for i, op := range ops {
+		var task domain.Task
+		var err error
+
+		switch op.Kind {
+		case domain.OperationCreate:
+			task, err = r.Create(op.Task)
+		case domain.OperationUpdate:
+			task, err = r.Update(op.Task)
+		case domain.OperationDelete:
+			task, err = op.Task, r.Delete(op.Task.ID)
+		default:
+			err = &domain.ValidationError{Field: "op", Message: "unsupported operation " + op.Kind}
+		}
+
+		if err != nil {
+			results[i].Err = err
+			if atomic {
+				r.tasks = snapshot
+				r.nextID = snapshotNextID
+				abortBatch(results, i)
+				return results, nil
+			}
+			continue
+		}
+
+		results[i] = domain.TaskOperationResult{Task: task, Applied: true}
+	}
+
+	return results, nil
+}
+
+// abortBatch marks every operation except the failed one as rolled back.
+func abortBatch(results []domain.TaskOperationResult, failed int) {
+	for i := range results {
+		if i == failed {
+			continue
+		}
+		results[i] = domain.TaskOperationResult{Err: &domain.BatchAbortedError{FailedIndex: failed}}
+	}
+}
+
+// Search evaluates query against every task. Without an index to rank by,
+// the score is simply the number of matched terms, title matches counting
+// ten times as much as description matches, like the SQLite weights.
+func (r *InMemoryTaskRepository) Search(query search.Node, scope *taskquery.Filter, limit, offset int) ([]domain.TaskSearchHit, int, error) {
+	terms := search.Terms(query)
+
+	hits := []domain.TaskSearchHit{}
+	for _, task := range r.tasks {
+		doc := search.NewDocument(map[string]string{
+			"title":       task.Title,
+			"description": task.Description,
+		})
+		if !query.Match(doc) || !scope.Match(task) {
+			continue
+		}
+
+		var score float64
+		for _, term := range terms {
+			if term.Field != "description" {
+				score += 10 * float64(len(term.Positions(doc["title"])))
+			}
+			if term.Field != "title" {
+				score += float64(len(term.Positions(doc["description"])))
+			}
+		}
+
+		hits = append(hits, domain.TaskSearchHit{
+			Task:           task,
+			Score:          score,
+			TitleHighlight: search.Highlight(task.Title, terms, "title"),
+			Snippet:        search.Snippet(task.Description, terms, "description", snippetTokens),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return []domain.TaskSearchHit{}, total, nil
+	}
+	return hits[offset:min(total, offset+limit)], total, nil
+}
+
 func (r *InMemoryTaskRepository) Close() error {
 	return nil
 }
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"task-manager-api/domain"
+	"time"
+)
+
+type WebhookRepository interface {
+	Create(hook domain.Webhook) (domain.Webhook, error)
+	GetByID(id int) (domain.Webhook, error)
+	ListByOwner(ownerID int) ([]domain.Webhook, error)
+	// ListActive lists the webhooks that aren't disabled.
+	ListActive() ([]domain.Webhook, error)
+	Update(hook domain.Webhook) (domain.Webhook, error)
+	Delete(id int) error
+
+	Enqueue(delivery domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	// GetDelivery returns a delivery with its attempt log.
+	GetDelivery(id int) (domain.WebhookDelivery, error)
+	// ListDeliveries lists a webhook's deliveries, newest first, without
+	// their logs.
+	ListDeliveries(webhookID, limit int) ([]domain.WebhookDelivery, error)
+	// Claim leases up to limit pending deliveries to active webhooks that
+	// are due at now to owner until now+lease and returns them. A delivery
+	// claimed by one owner is not returned to another until the lease
+	// expires.
+	Claim(owner string, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	// RecordAttempt logs an attempt on a delivery claimed by owner, moves
+	// the delivery to status and releases the claim. A delivery left
+	// pending is tried again at retryAt.
+	RecordAttempt(owner string, attempt domain.WebhookAttempt, status string, retryAt *time.Time) error
+	// TrackHealth resets a webhook's consecutive failures after a success,
+	// or counts one more after a failure and disables the webhook once
+	// there are disableAfter of them.
+	TrackHealth(id int, ok bool, disableAfter int) (domain.Webhook, error)
+	// PruneDeliveries deletes finished deliveries created before before.
+	PruneDeliveries(before time.Time) (int, error)
+	Close() error
+}
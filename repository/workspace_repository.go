@@ -0,0 +1,31 @@
+package repository
+
+import "task-manager-api/domain"
+
+// WorkspaceRepository stores workspaces, their members and their projects.
+type WorkspaceRepository interface {
+	// CreateWorkspace creates a workspace with ownerID as its first owner.
+	CreateWorkspace(workspace domain.Workspace, ownerID int) (domain.Workspace, error)
+	GetWorkspace(id int) (domain.Workspace, error)
+	ListWorkspaces(userID int) ([]domain.Workspace, error)
+
+	// MemberRole returns the user's role in the workspace, or "" if they
+	// are not a member.
+	MemberRole(workspaceID, userID int) (string, error)
+	ListMembers(workspaceID int) ([]domain.WorkspaceMember, error)
+	// SetMember adds a member or changes their role.
+	SetMember(member domain.WorkspaceMember) error
+	RemoveMember(workspaceID, userID int) error
+	CountOwners(workspaceID int) (int, error)
+
+	CreateProject(project domain.Project) (domain.Project, error)
+	GetProject(id int) (domain.Project, error)
+	ListProjects(workspaceID int, includeArchived bool) ([]domain.Project, error)
+	UpdateProject(project domain.Project) (domain.Project, error)
+	DeleteProject(id int) error
+	CountProjectTasks(id int) (int, error)
+	// ProjectIDsForUser lists every project in the user's workspaces.
+	ProjectIDsForUser(userID int) ([]int, error)
+
+	Close() error
+}
@@ -0,0 +1,248 @@
+// Package resp is a minimal client for the Redis serialization protocol
+// (RESP2). It covers what the cache needs: sending commands as arrays of bulk
+// strings and reading simple, error, integer, bulk and array replies over a
+// small pool of connections.
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Error is an error reply sent by the server, such as "ERR unknown command".
+// The connection is still usable after one.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type Options struct {
+	Password    string
+	DB          int
+	DialTimeout time.Duration
+	IOTimeout   time.Duration
+	PoolSize    int
+}
+
+type Client struct {
+	addr    string
+	options Options
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func NewClient(addr string, options Options) *Client {
+	if options.DialTimeout == 0 {
+		options.DialTimeout = time.Second
+	}
+	if options.IOTimeout == 0 {
+		options.IOTimeout = time.Second
+	}
+	if options.PoolSize == 0 {
+		options.PoolSize = 8
+	}
+	return &Client{addr: addr, options: options}
+}
+
+// Do sends one command and returns its reply: a string for simple strings,
+// int64 for integers, []byte (or nil) for bulk strings and []interface{} for
+// arrays. Error replies are returned as *Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, c.options.IOTimeout, args)
+
+	var replyErr *Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The stream may be half-read; never reuse it.
+		cn.Close()
+		return nil, err
+	}
+
+	c.put(cn)
+	return reply, err
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errors.New("resp: client closed")
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	return c.dial(ctx)
+}
+
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || len(c.idle) >= c.options.PoolSize {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: c.options.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{
+		Conn:   netConn,
+		reader: bufio.NewReader(netConn),
+		writer: bufio.NewWriter(netConn),
+	}
+
+	if c.options.Password != "" {
+		if _, err := cn.do(ctx, c.options.IOTimeout, []string{"AUTH", c.options.Password}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("resp: auth: %w", err)
+		}
+	}
+	if c.options.DB != 0 {
+		if _, err := cn.do(ctx, c.options.IOTimeout, []string{"SELECT", strconv.Itoa(c.options.DB)}); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("resp: select db: %w", err)
+		}
+	}
+
+	return cn, nil
+}
+
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []string) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	cn.SetDeadline(deadline)
+
+	if err := writeCommand(cn.writer, args); err != nil {
+		return nil, err
+	}
+	if err := cn.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(cn.reader)
+}
+
+func writeCommand(w *bufio.Writer, args []string) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(w, "$%d\r\n", len(arg))
+		w.WriteString(arg)
+		w.WriteString("\r\n")
+	}
+	// bufio.Writer keeps the first error, so checking once is enough.
+	_, err := w.Write(nil)
+	return err
+}
+
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("resp: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, &Error{Message: line[1:]}
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("resp: bad bulk length %q", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("resp: bad array length %q", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			item, err := readReply(r)
+			var replyErr *Error
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			if replyErr != nil {
+				item = replyErr
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("resp: unknown reply type %q", line[0])
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("resp: malformed line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
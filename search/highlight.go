@@ -0,0 +1,129 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Matches in highlighted text are wrapped in these control characters rather
+// than markup, so the text can be HTML-escaped safely before MarkHTML turns
+// them into <mark> tags. FTS5's highlight() and snippet() use them too.
+const (
+	MarkStart = "\x02"
+	MarkEnd   = "\x03"
+	Ellipsis  = "…"
+)
+
+// MarkHTML escapes text for HTML and renders highlight markers as <mark>.
+func MarkHTML(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = strings.ReplaceAll(escaped, MarkStart, "<mark>")
+	return strings.ReplaceAll(escaped, MarkEnd, "</mark>")
+}
+
+type span struct {
+	start, end int // byte offsets into the text
+}
+
+func tokenSpans(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		if isSeparator(r) {
+			if start >= 0 {
+				spans = append(spans, span{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+	return spans
+}
+
+// matchedTokens marks which tokens of field are covered by any of terms.
+func matchedTokens(tokens []string, terms []*Term, field string) []bool {
+	matched := make([]bool, len(tokens))
+	for _, term := range terms {
+		if term.Field != "" && term.Field != field {
+			continue
+		}
+		for _, pos := range term.Positions(tokens) {
+			for i := range term.Words {
+				matched[pos+i] = true
+			}
+		}
+	}
+	return matched
+}
+
+// Highlight wraps every token of text matched by terms in markers.
+func Highlight(text string, terms []*Term, field string) string {
+	return highlightRange(text, terms, field, 0, -1)
+}
+
+// Snippet returns up to maxTokens tokens of text around the first match,
+// highlighted, with an ellipsis where text was cut.
+func Snippet(text string, terms []*Term, field string, maxTokens int) string {
+	spans := tokenSpans(text)
+	if len(spans) <= maxTokens {
+		return Highlight(text, terms, field)
+	}
+
+	matched := matchedTokens(Tokenize(text), terms, field)
+	first := 0
+	for i, hit := range matched {
+		if hit {
+			first = i
+			break
+		}
+	}
+
+	// Start a little before the first match so it has some context.
+	from := max(0, first-maxTokens/4)
+	to := min(len(spans), from+maxTokens)
+	from = max(0, to-maxTokens)
+
+	return highlightRange(text, terms, field, from, to)
+}
+
+// highlightRange highlights tokens [from, to) of text; to < 0 means all.
+func highlightRange(text string, terms []*Term, field string, from, to int) string {
+	spans := tokenSpans(text)
+	if to < 0 {
+		to = len(spans)
+	}
+	matched := matchedTokens(Tokenize(text), terms, field)
+
+	start, end := 0, len(text)
+	if from > 0 {
+		start = spans[from].start
+	}
+	if to < len(spans) {
+		end = spans[to-1].end
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(Ellipsis)
+	}
+	cursor := start
+	for i := from; i < to; i++ {
+		if !matched[i] {
+			continue
+		}
+		b.WriteString(text[cursor:spans[i].start])
+		b.WriteString(MarkStart)
+		b.WriteString(text[spans[i].start:spans[i].end])
+		b.WriteString(MarkEnd)
+		cursor = spans[i].end
+	}
+	b.WriteString(text[cursor:end])
+	if end < len(text) {
+		b.WriteString(Ellipsis)
+	}
+	return b.String()
+}
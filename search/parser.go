@@ -0,0 +1,232 @@
+package search
+
+import (
+	"slices"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	term *Term
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	case tokenOpen:
+		return "("
+	case tokenClose:
+		return ")"
+	}
+	return `"` + strings.Join(t.term.Words, " ") + `"`
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpen})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenClose})
+			i++
+		case c == '"':
+			term, next, err := lexPhrase(input, i, "")
+			if err != nil {
+				return nil, err
+			}
+			tokens = appendTerm(tokens, term)
+			i = next
+		default:
+			end := i
+			for end < len(input) && !strings.ContainsRune(" \t\n\r()\"", rune(input[end])) {
+				end++
+			}
+			word := input[i:end]
+			i = end
+
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokenAnd})
+				continue
+			case "OR":
+				tokens = append(tokens, token{kind: tokenOr})
+				continue
+			case "NOT":
+				tokens = append(tokens, token{kind: tokenNot})
+				continue
+			}
+
+			field, rest, scoped := strings.Cut(word, ":")
+			if !scoped || !slices.Contains(Fields, strings.ToLower(field)) {
+				field, rest = "", word
+			}
+			field = strings.ToLower(field)
+
+			// title:"some phrase"
+			if field != "" && rest == "" && i < len(input) && input[i] == '"' {
+				term, next, err := lexPhrase(input, i, field)
+				if err != nil {
+					return nil, err
+				}
+				tokens = appendTerm(tokens, term)
+				i = next
+				continue
+			}
+
+			prefix := strings.HasSuffix(rest, "*")
+			tokens = appendTerm(tokens, &Term{
+				Field:  field,
+				Words:  Tokenize(strings.TrimSuffix(rest, "*")),
+				Prefix: prefix,
+			})
+		}
+	}
+
+	return tokens, nil
+}
+
+// lexPhrase reads a quoted phrase starting at input[start] == '"', plus an
+// optional trailing '*' for a prefix phrase.
+func lexPhrase(input string, start int, field string) (*Term, int, error) {
+	end := strings.IndexByte(input[start+1:], '"')
+	if end < 0 {
+		return nil, 0, invalid("has an unterminated quote")
+	}
+	end += start + 1
+
+	term := &Term{Field: field, Words: Tokenize(input[start+1 : end])}
+	next := end + 1
+	if next < len(input) && input[next] == '*' {
+		term.Prefix = true
+		next++
+	}
+	return term, next, nil
+}
+
+// appendTerm drops terms made only of punctuation, which match nothing.
+func appendTerm(tokens []token, term *Term) []token {
+	if len(term.Words) == 0 {
+		return tokens
+	}
+	return append(tokens, token{kind: tokenTerm, term: term})
+}
+
+// parser is a recursive descent parser with FTS5's precedence:
+// NOT binds tighter than AND, which binds tighter than OR.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) accept(kind tokenKind) bool {
+	if !p.done() && p.peek().kind == kind {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenOr) {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for !p.done() {
+		// Adjacent terms are an implicit AND.
+		explicit := p.accept(tokenAnd)
+		if !explicit && p.peek().kind != tokenTerm && p.peek().kind != tokenOpen {
+			break
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenNot) {
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Not{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	if p.done() {
+		return nil, invalid("ends with an operator")
+	}
+
+	next := p.peek()
+	switch next.kind {
+	case tokenTerm:
+		p.pos++
+		return next.term, nil
+	case tokenOpen:
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(tokenClose) {
+			return nil, invalid("has an unclosed parenthesis")
+		}
+		return node, nil
+	case tokenNot:
+		return nil, invalid("NOT must follow a term, as in \"a NOT b\"")
+	}
+	return nil, invalid("unexpected " + next.describe())
+}
@@ -0,0 +1,178 @@
+// Package search parses the task search syntax accepted by
+// GET /tasks/search?q= and evaluates it either as an SQLite FTS5 MATCH
+// expression or directly against text for repositories without FTS.
+//
+// The syntax is deliberately small:
+//
+//	deploy script          both words, anywhere (implicit AND)
+//	"release notes"        exact phrase
+//	deplo*                 prefix match
+//	a OR b, a AND b        boolean operators (upper case)
+//	a NOT b                a but not b
+//	(a OR b) c             grouping
+//	title:deploy           restrict a term or phrase to one field
+//
+// User input is never spliced into the FTS5 expression: every term is
+// re-quoted, so FTS5 operators or column names inside a term are literal.
+package search
+
+import (
+	"strings"
+	"task-manager-api/domain"
+	"unicode"
+)
+
+// Fields are the columns a term may be scoped to.
+var Fields = []string{"title", "description"}
+
+const maxQueryLength = 500
+
+// Node is a parsed query.
+type Node interface {
+	// FTS5 renders the node as an FTS5 MATCH expression.
+	FTS5() string
+	// Match reports whether a document with the given field texts matches.
+	Match(doc Document) bool
+}
+
+// Document holds the tokenized text of each field.
+type Document map[string][]string
+
+// NewDocument tokenizes field texts for Match.
+func NewDocument(fields map[string]string) Document {
+	doc := make(Document, len(fields))
+	for name, text := range fields {
+		doc[name] = Tokenize(text)
+	}
+	return doc
+}
+
+// Term is a word, prefix or phrase, optionally scoped to a field.
+type Term struct {
+	Field  string   // "" matches any field
+	Words  []string // lower-cased tokens; more than one is a phrase
+	Prefix bool     // the last word matches as a prefix
+}
+
+type And struct{ Left, Right Node }
+type Or struct{ Left, Right Node }
+
+// Not matches Left unless Right also matches. FTS5 has no unary NOT.
+type Not struct{ Left, Right Node }
+
+func (t *Term) FTS5() string {
+	quoted := `"` + strings.Join(t.Words, " ") + `"`
+	if t.Prefix {
+		quoted += "*"
+	}
+	if t.Field != "" {
+		return t.Field + " : " + quoted
+	}
+	return quoted
+}
+
+func (n *And) FTS5() string { return "(" + n.Left.FTS5() + " AND " + n.Right.FTS5() + ")" }
+func (n *Or) FTS5() string  { return "(" + n.Left.FTS5() + " OR " + n.Right.FTS5() + ")" }
+func (n *Not) FTS5() string { return "(" + n.Left.FTS5() + " NOT " + n.Right.FTS5() + ")" }
+
+func (t *Term) Match(doc Document) bool {
+	if t.Field != "" {
+		return t.matchTokens(doc[t.Field])
+	}
+	for _, tokens := range doc {
+		if t.matchTokens(tokens) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *And) Match(doc Document) bool { return n.Left.Match(doc) && n.Right.Match(doc) }
+func (n *Or) Match(doc Document) bool  { return n.Left.Match(doc) || n.Right.Match(doc) }
+func (n *Not) Match(doc Document) bool { return n.Left.Match(doc) && !n.Right.Match(doc) }
+
+// matchTokens reports whether the term's words appear consecutively.
+func (t *Term) matchTokens(tokens []string) bool {
+	return len(t.Positions(tokens)) > 0
+}
+
+// Positions returns the index of every token where the term starts.
+func (t *Term) Positions(tokens []string) []int {
+	var positions []int
+	for start := 0; start+len(t.Words) <= len(tokens); start++ {
+		if t.matchesAt(tokens, start) {
+			positions = append(positions, start)
+		}
+	}
+	return positions
+}
+
+func (t *Term) matchesAt(tokens []string, start int) bool {
+	for i, word := range t.Words {
+		token := tokens[start+i]
+		last := i == len(t.Words)-1
+		if last && t.Prefix {
+			if !strings.HasPrefix(token, word) {
+				return false
+			}
+		} else if token != word {
+			return false
+		}
+	}
+	return true
+}
+
+// Terms lists every term in the query that is not under a NOT, i.e. the ones
+// worth highlighting.
+func Terms(node Node) []*Term {
+	switch n := node.(type) {
+	case *Term:
+		return []*Term{n}
+	case *And:
+		return append(Terms(n.Left), Terms(n.Right)...)
+	case *Or:
+		return append(Terms(n.Left), Terms(n.Right)...)
+	case *Not:
+		return Terms(n.Left)
+	}
+	return nil
+}
+
+// Tokenize splits text into lower-case words the way FTS5's unicode61
+// tokenizer does: letters and digits form words, everything else separates.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// Parse parses a search query. Errors are *domain.ValidationError on "q".
+func Parse(query string) (Node, error) {
+	if len(query) > maxQueryLength {
+		return nil, invalid("must be at most 500 characters")
+	}
+
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, invalid("is required")
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, invalid("unexpected " + p.peek().describe())
+	}
+	return node, nil
+}
+
+func invalid(message string) error {
+	return &domain.ValidationError{Field: "q", Message: message}
+}
@@ -0,0 +1,485 @@
+package taskquery
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+// Env is what a query is evaluated relative to.
+type Env struct {
+	Now    time.Time // "today" is Now's UTC date
+	UserID int       // who "me" is; 0 for anonymous callers
+}
+
+// Filter is a compiled query. SQL and Match agree on every task, so a
+// repository may use whichever suits its storage.
+type Filter struct {
+	clauses []clause
+}
+
+type clause struct {
+	sql     string
+	args    []interface{}
+	match   func(task domain.Task) bool
+	negated bool
+}
+
+// fieldDef compiles conditions on one field. ops lists the operators it
+// accepts; OpEqual is always first.
+type fieldDef struct {
+	ops     []Operator
+	compile func(cond Condition, env Env) (clause, error)
+}
+
+var fields = map[string]fieldDef{
+	"status":      {ops: []Operator{OpEqual}, compile: compileStatus},
+	"priority":    {ops: []Operator{OpEqual}, compile: compilePriority},
+	"due":         {ops: dateOperators, compile: compileDue},
+	"updated":     {ops: dateOperators, compile: compileUpdated},
+	"title":       {ops: []Operator{OpEqual}, compile: compileText("title")},
+	"description": {ops: []Operator{OpEqual}, compile: compileText("description")},
+	"label":       {ops: []Operator{OpEqual}, compile: compileLabel},
+	"tag":         {ops: []Operator{OpEqual}, compile: compileLabel},
+	"project":     {ops: []Operator{OpEqual}, compile: compileProject},
+	"assignee":    {ops: []Operator{OpEqual}, compile: compileAssignee},
+	"parent":      {ops: []Operator{OpEqual}, compile: compileParent},
+}
+
+var dateOperators = []Operator{OpEqual, OpLess, OpLessEqual, OpGreater, OpGreaterEqual}
+
+// New parses and compiles query.
+func New(query string, env Env) (*Filter, error) {
+	parsed, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(parsed, env)
+}
+
+// Compile checks every condition's field, operator and value.
+func Compile(query *Query, env Env) (*Filter, error) {
+	filter := &Filter{}
+
+	for _, cond := range query.Conditions {
+		var c clause
+		var err error
+
+		if cond.Field == "" {
+			c, err = compileText("")(cond, env)
+		} else {
+			def, ok := fields[cond.Field]
+			if !ok {
+				return nil, tokenError(cond.Token, cond.Column, "uses unknown field \""+cond.Field+"\"; expected one of "+strings.Join(FieldNames(), ", "))
+			}
+			if !slices.Contains(def.ops, cond.Op) {
+				return nil, tokenError(cond.Token, cond.Column, "uses \""+string(cond.Op)+"\", but "+cond.Field+" only supports \":\"")
+			}
+			c, err = def.compile(cond, env)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		c.negated = cond.Negated
+		filter.clauses = append(filter.clauses, c)
+	}
+
+	return filter, nil
+}
+
+// FieldNames lists the fields a condition may name, sorted.
+func FieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SQL renders the filter as a condition on the tasks table, with ? for each
+// argument. An empty filter renders as "1 = 1".
+func (f *Filter) SQL() (string, []interface{}) {
+	if len(f.clauses) == 0 {
+		return "1 = 1", nil
+	}
+
+	parts := make([]string, len(f.clauses))
+	var args []interface{}
+	for i, c := range f.clauses {
+		parts[i] = "(" + c.sql + ")"
+		if c.negated {
+			parts[i] = "NOT " + parts[i]
+		}
+		args = append(args, c.args...)
+	}
+	return strings.Join(parts, " AND "), args
+}
+
+// Match reports whether task satisfies every condition.
+func (f *Filter) Match(task domain.Task) bool {
+	for _, c := range f.clauses {
+		if c.match(task) == c.negated {
+			return false
+		}
+	}
+	return true
+}
+
+var statusValues = map[string]string{
+	"pending":     domain.StatusPending,
+	"in_progress": domain.StatusInProgress,
+	"completed":   domain.StatusCompleted,
+}
+
+var priorityValues = map[string]string{
+	"low":    domain.PriorityLow,
+	"medium": domain.PriorityMedium,
+	"high":   domain.PriorityHigh,
+}
+
+func compileStatus(cond Condition, env Env) (clause, error) {
+	return compileEnum(cond, "status", statusValues, func(task domain.Task) string { return task.Status })
+}
+
+func compilePriority(cond Condition, env Env) (clause, error) {
+	return compileEnum(cond, "priority", priorityValues, func(task domain.Task) string { return task.Priority })
+}
+
+// compileEnum matches a column against a comma-separated list of values,
+// written in lower case with "_" or "-" for spaces.
+func compileEnum(cond Condition, column string, values map[string]string, get func(domain.Task) string) (clause, error) {
+	var wanted []string
+	for _, raw := range strings.Split(cond.Value, ",") {
+		key := strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(raw)))
+		value, ok := values[key]
+		if !ok {
+			return clause{}, tokenError(cond.Token, cond.Column, "has unknown "+column+" \""+raw+"\"; expected one of "+strings.Join(sortedKeys(values), ", "))
+		}
+		wanted = append(wanted, value)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(wanted)), ", ")
+	args := make([]interface{}, len(wanted))
+	for i, value := range wanted {
+		args[i] = value
+	}
+
+	return clause{
+		sql:   column + " IN (" + placeholders + ")",
+		args:  args,
+		match: func(task domain.Task) bool { return slices.Contains(wanted, get(task)) },
+	}, nil
+}
+
+// compileLabel matches tasks carrying any of a comma-separated list of label
+// names, compared case-insensitively. Repeat the condition to require all.
+func compileLabel(cond Condition, env Env) (clause, error) {
+	var names []string
+	for _, name := range strings.Split(cond.Value, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return clause{}, tokenError(cond.Token, cond.Column, "must name a label")
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+
+	return clause{
+		sql: `id IN (SELECT tl.task_id FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+			WHERE lower(l.name) IN (` + placeholders + `))`,
+		args: args,
+		match: func(task domain.Task) bool {
+			for _, label := range task.Labels {
+				if slices.Contains(names, strings.ToLower(label.Name)) {
+					return true
+				}
+			}
+			return false
+		},
+	}, nil
+}
+
+// compileProject matches tasks in any of a comma-separated list of project
+// IDs; "none" stands for tasks outside every project.
+func compileProject(cond Condition, env Env) (clause, error) {
+	var ids []int
+	var parts []string
+	var args []interface{}
+	none := false
+	for _, raw := range strings.Split(cond.Value, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if strings.EqualFold(raw, "none") {
+			none = true
+			continue
+		}
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			return clause{}, tokenError(cond.Token, cond.Column, "has invalid project \""+raw+"\"; use a project ID or none")
+		}
+		ids = append(ids, id)
+		args = append(args, id)
+	}
+
+	if len(ids) > 0 {
+		parts = append(parts, "project_id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+")")
+	}
+	if none {
+		parts = append(parts, "project_id IS NULL")
+	}
+	if len(parts) == 0 {
+		// A scope with no readable projects matches nothing.
+		parts = append(parts, "0 = 1")
+	}
+
+	return clause{
+		sql:  strings.Join(parts, " OR "),
+		args: args,
+		match: func(task domain.Task) bool {
+			if task.ProjectID == 0 {
+				return none
+			}
+			return slices.Contains(ids, task.ProjectID)
+		},
+	}, nil
+}
+
+// compileAssignee matches tasks assigned to any of a comma-separated list of
+// user IDs, "me" for the caller, or "none" for unassigned tasks.
+func compileAssignee(cond Condition, env Env) (clause, error) {
+	var ids []int
+	var args []interface{}
+	none := false
+	for _, raw := range strings.Split(cond.Value, ",") {
+		raw = strings.TrimSpace(raw)
+		switch {
+		case raw == "":
+			continue
+		case strings.EqualFold(raw, "none"):
+			none = true
+			continue
+		case strings.EqualFold(raw, "me"):
+			if env.UserID == 0 {
+				return clause{}, tokenError(cond.Token, cond.Column, "uses \"me\", which needs a signed-in user")
+			}
+			ids = append(ids, env.UserID)
+		default:
+			id, err := strconv.Atoi(raw)
+			if err != nil || id <= 0 {
+				return clause{}, tokenError(cond.Token, cond.Column, "has invalid assignee \""+raw+"\"; use a user ID, me or none")
+			}
+			ids = append(ids, id)
+		}
+		args = append(args, ids[len(ids)-1])
+	}
+	if len(ids) == 0 && !none {
+		return clause{}, tokenError(cond.Token, cond.Column, "must name an assignee")
+	}
+
+	var parts []string
+	if len(ids) > 0 {
+		parts = append(parts, "id IN (SELECT task_id FROM task_assignees WHERE user_id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")+"))")
+	}
+	if none {
+		parts = append(parts, "id NOT IN (SELECT task_id FROM task_assignees)")
+	}
+
+	return clause{
+		sql:  strings.Join(parts, " OR "),
+		args: args,
+		match: func(task domain.Task) bool {
+			if len(task.Assignees) == 0 {
+				return none
+			}
+			for _, assignee := range task.Assignees {
+				if slices.Contains(ids, assignee.UserID) {
+					return true
+				}
+			}
+			return false
+		},
+	}, nil
+}
+
+// compileParent matches the direct subtasks of a task ID, or top-level tasks
+// for "none".
+func compileParent(cond Condition, env Env) (clause, error) {
+	raw := strings.TrimSpace(cond.Value)
+	if strings.EqualFold(raw, "none") {
+		return clause{
+			sql:   "parent_id IS NULL",
+			match: func(task domain.Task) bool { return task.ParentID == 0 },
+		}, nil
+	}
+
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return clause{}, tokenError(cond.Token, cond.Column, "has invalid parent \""+raw+"\"; use a task ID or none")
+	}
+	return clause{
+		sql:   "parent_id = ?",
+		args:  []interface{}{id},
+		match: func(task domain.Task) bool { return task.ParentID == id },
+	}, nil
+}
+
+// ProjectScope returns a condition limiting a query to the given projects,
+// plus tasks outside any project when includeNone is set. Callers append it
+// to a parsed query to apply access rules.
+func ProjectScope(projectIDs []int, includeNone bool) Condition {
+	values := make([]string, 0, len(projectIDs)+1)
+	if includeNone {
+		values = append(values, "none")
+	}
+	for _, id := range projectIDs {
+		values = append(values, strconv.Itoa(id))
+	}
+	return Condition{Field: "project", Op: OpEqual, Value: strings.Join(values, ","), Token: "project:", Column: 1}
+}
+
+func compileDue(cond Condition, env Env) (clause, error) {
+	if strings.EqualFold(cond.Value, "none") {
+		if cond.Op != OpEqual {
+			return clause{}, tokenError(cond.Token, cond.Column, "can only use \"none\" with \":\"")
+		}
+		return clause{
+			sql:   "due_date IS NULL",
+			match: func(task domain.Task) bool { return task.DueDate == nil },
+		}, nil
+	}
+	return compileDate(cond, env, "due_date", func(task domain.Task) *time.Time { return task.DueDate })
+}
+
+func compileUpdated(cond Condition, env Env) (clause, error) {
+	return compileDate(cond, env, "updated_at", func(task domain.Task) *time.Time { return &task.UpdatedAt })
+}
+
+// compileDate compares a millisecond timestamp column against a whole UTC
+// day. Tasks without a date never satisfy a comparison, so negating one
+// selects them.
+func compileDate(cond Condition, env Env, column string, get func(domain.Task) *time.Time) (clause, error) {
+	day, err := parseDay(cond.Value, env)
+	if err != nil {
+		return clause{}, tokenError(cond.Token, cond.Column, "has an invalid date; use YYYY-MM-DD, today, tomorrow or yesterday")
+	}
+	start, end := day, day.AddDate(0, 0, 1)
+
+	var sqlOp string
+	var bounds []time.Time
+	switch cond.Op {
+	case OpEqual:
+		sqlOp, bounds = column+" >= ? AND "+column+" < ?", []time.Time{start, end}
+	case OpLess:
+		sqlOp, bounds = column+" < ?", []time.Time{start}
+	case OpLessEqual:
+		sqlOp, bounds = column+" < ?", []time.Time{end}
+	case OpGreater:
+		sqlOp, bounds = column+" >= ?", []time.Time{end}
+	case OpGreaterEqual:
+		sqlOp, bounds = column+" >= ?", []time.Time{start}
+	}
+
+	args := make([]interface{}, len(bounds))
+	for i, bound := range bounds {
+		args[i] = bound.UnixMilli()
+	}
+
+	return clause{
+		sql:  column + " IS NOT NULL AND " + sqlOp,
+		args: args,
+		match: func(task domain.Task) bool {
+			t := get(task)
+			if t == nil {
+				return false
+			}
+			switch cond.Op {
+			case OpEqual:
+				return !t.Before(start) && t.Before(end)
+			case OpLess:
+				return t.Before(start)
+			case OpLessEqual:
+				return t.Before(end)
+			case OpGreater:
+				return !t.Before(end)
+			default:
+				return !t.Before(start)
+			}
+		},
+	}, nil
+}
+
+func parseDay(value string, env Env) (time.Time, error) {
+	now := env.Now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// compileText matches a case-insensitive substring of column, or of the
+// title or description when column is "". SQLite's LIKE only folds ASCII
+// case, so non-ASCII text matches case-sensitively there.
+func compileText(column string) func(cond Condition, env Env) (clause, error) {
+	return func(cond Condition, env Env) (clause, error) {
+		needle := strings.ToLower(cond.Value)
+		pattern := "%" + escapeLike(needle) + "%"
+
+		if column == "" {
+			return clause{
+				sql:  `title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\'`,
+				args: []interface{}{pattern, pattern},
+				match: func(task domain.Task) bool {
+					return containsFold(task.Title, needle) || containsFold(task.Description, needle)
+				},
+			}, nil
+		}
+
+		return clause{
+			sql:  column + ` LIKE ? ESCAPE '\'`,
+			args: []interface{}{pattern},
+			match: func(task domain.Task) bool {
+				if column == "title" {
+					return containsFold(task.Title, needle)
+				}
+				return containsFold(task.Description, needle)
+			},
+		}, nil
+	}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func containsFold(s, lowerNeedle string) bool {
+	return strings.Contains(strings.ToLower(s), lowerNeedle)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
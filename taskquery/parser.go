@@ -0,0 +1,184 @@
+// Package taskquery parses the filter syntax accepted by GET /tasks?query=
+// and compiles it to a parameterized SQL condition or to a predicate over
+// domain.Task.
+//
+// A query is a list of conditions that must all hold:
+//
+//	status:in_progress          field equals a value
+//	priority:high,medium        any of several values
+//	due<2026-11-01              comparison on dates: < <= > >=
+//	due:none                    tasks without a due date
+//	-status:completed           leading "-" negates a condition
+//	label:backend,frontend      tasks with either label (tag: also works)
+//	project:3,none              tasks in project 3 or in no project
+//	assignee:me                 tasks assigned to the caller (also user IDs, none)
+//	parent:12, parent:none      subtasks of task 12, or top-level tasks
+//	login, "login bug"          free text in the title or description
+//	title:"login bug"           free text in one field
+//
+// Dates are YYYY-MM-DD or today, tomorrow and yesterday, in UTC.
+package taskquery
+
+import (
+	"fmt"
+	"strings"
+	"task-manager-api/domain"
+	"unicode/utf8"
+)
+
+type Operator string
+
+const (
+	OpEqual        Operator = ":"
+	OpLess         Operator = "<"
+	OpLessEqual    Operator = "<="
+	OpGreater      Operator = ">"
+	OpGreaterEqual Operator = ">="
+)
+
+const maxQueryLength = 1000
+
+// Query is a parsed query; every condition must hold.
+type Query struct {
+	Conditions []Condition
+}
+
+// Condition is one whitespace-separated term of the query.
+type Condition struct {
+	Field   string // "" for free text
+	Op      Operator
+	Value   string
+	Negated bool
+	Token   string // the term as written, for error messages
+	Column  int    // 1-based column where the term starts
+}
+
+// Parse parses query into an AST. Only syntax is checked here; unknown
+// fields and bad values are reported by Compile.
+func Parse(query string) (*Query, error) {
+	if len(query) > maxQueryLength {
+		return nil, &domain.ValidationError{Field: "query", Message: fmt.Sprintf("must be at most %d characters", maxQueryLength)}
+	}
+
+	p := &parser{input: query}
+	parsed := &Query{}
+	for {
+		p.skipSpace()
+		if p.done() {
+			return parsed, nil
+		}
+		cond, err := p.condition()
+		if err != nil {
+			return nil, err
+		}
+		parsed.Conditions = append(parsed.Conditions, cond)
+	}
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) condition() (Condition, error) {
+	start := p.pos
+	cond := Condition{Op: OpEqual}
+
+	if p.input[p.pos] == '-' {
+		cond.Negated = true
+		p.pos++
+		if p.done() || isSpace(p.input[p.pos]) {
+			return cond, p.errorAt(start, p.pos, "must be followed by a condition")
+		}
+	}
+
+	// field followed by an operator, e.g. "due<"
+	nameEnd := p.pos
+	for nameEnd < len(p.input) && isNameByte(p.input[nameEnd]) {
+		nameEnd++
+	}
+	if op, ok := operatorAt(p.input, nameEnd); ok && nameEnd > p.pos {
+		cond.Field = strings.ToLower(p.input[p.pos:nameEnd])
+		cond.Op = op
+		p.pos = nameEnd + len(op)
+		if p.done() || isSpace(p.input[p.pos]) {
+			return cond, p.errorAt(start, p.pos, "must be followed by a value")
+		}
+	}
+
+	value, err := p.value(start)
+	if err != nil {
+		return cond, err
+	}
+
+	cond.Value = value
+	cond.Token = p.input[start:p.pos]
+	cond.Column = column(p.input, start)
+	return cond, nil
+}
+
+// value reads a quoted phrase or a bare word.
+func (p *parser) value(start int) (string, error) {
+	if p.input[p.pos] == '"' {
+		end := strings.IndexByte(p.input[p.pos+1:], '"')
+		if end < 0 {
+			return "", p.errorAt(start, len(p.input), "has an unterminated quote")
+		}
+		value := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		if strings.TrimSpace(value) == "" {
+			return "", p.errorAt(start, p.pos, "has an empty phrase")
+		}
+		return value, nil
+	}
+
+	valueStart := p.pos
+	for !p.done() && !isSpace(p.input[p.pos]) && p.input[p.pos] != '"' {
+		p.pos++
+	}
+	return p.input[valueStart:p.pos], nil
+}
+
+func (p *parser) errorAt(start, end int, message string) error {
+	return tokenError(p.input[start:end], column(p.input, start), message)
+}
+
+// tokenError reports a problem with one term of the query, quoting it and
+// giving its column so the client can point at it.
+func tokenError(token string, column int, message string) error {
+	return &domain.ValidationError{
+		Field:   "query",
+		Message: fmt.Sprintf("%q at column %d %s", token, column, message),
+	}
+}
+
+func operatorAt(input string, pos int) (Operator, bool) {
+	for _, op := range []Operator{OpLessEqual, OpGreaterEqual, OpEqual, OpLess, OpGreater} {
+		if strings.HasPrefix(input[pos:], string(op)) {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func column(input string, pos int) int {
+	return utf8.RuneCountInString(input[:pos]) + 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
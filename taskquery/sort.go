@@ -0,0 +1,108 @@
+package taskquery
+
+import (
+	"sort"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+)
+
+// Sort orders tasks by one or more keys, parsed from a spec such as
+// "priority,-due": a comma-separated list of fields, "-" for descending.
+type Sort struct {
+	keys []sortKey
+}
+
+type sortKey struct {
+	less       func(a, b domain.Task) bool
+	descending bool
+}
+
+var sortFields = map[string]func(a, b domain.Task) bool{
+	"id":       func(a, b domain.Task) bool { return a.ID < b.ID },
+	"title":    func(a, b domain.Task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) },
+	"status":   func(a, b domain.Task) bool { return statusRank[a.Status] < statusRank[b.Status] },
+	"priority": func(a, b domain.Task) bool { return priorityRank[a.Priority] < priorityRank[b.Priority] },
+	"due":      func(a, b domain.Task) bool { return dueBefore(a.DueDate, b.DueDate) },
+	"updated":  func(a, b domain.Task) bool { return a.UpdatedAt.Before(b.UpdatedAt) },
+}
+
+// Statuses sort in workflow order and priorities from low to high, rather
+// than alphabetically.
+var statusRank = map[string]int{
+	domain.StatusPending:    1,
+	domain.StatusInProgress: 2,
+	domain.StatusCompleted:  3,
+}
+
+var priorityRank = map[string]int{
+	domain.PriorityLow:    1,
+	domain.PriorityMedium: 2,
+	domain.PriorityHigh:   3,
+}
+
+// dueBefore sorts tasks without a due date after those with one.
+func dueBefore(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	return a.Before(*b)
+}
+
+// ParseSort parses a sort spec. An empty spec sorts by id.
+func ParseSort(spec string) (Sort, error) {
+	var s Sort
+	for _, part := range strings.Split(spec, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+
+		descending := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		less, ok := sortFields[name]
+		if !ok {
+			return Sort{}, &domain.ValidationError{
+				Field:   "sort",
+				Message: "unknown sort field \"" + name + "\"; expected one of " + strings.Join(SortFieldNames(), ", "),
+			}
+		}
+		s.keys = append(s.keys, sortKey{less: less, descending: descending})
+	}
+
+	if len(s.keys) == 0 {
+		s.keys = []sortKey{{less: sortFields["id"]}}
+	}
+	return s, nil
+}
+
+// SortFieldNames lists the fields a sort spec may name, sorted.
+func SortFieldNames() []string {
+	names := make([]string, 0, len(sortFields))
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply sorts tasks in place. Ties on every key keep id order.
+func (s Sort) Apply(tasks []domain.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		for _, key := range s.keys {
+			x, y := a, b
+			if key.descending {
+				x, y = b, a
+			}
+			if key.less(x, y) {
+				return true
+			}
+			if key.less(y, x) {
+				return false
+			}
+		}
+		return a.ID < b.ID
+	})
+}
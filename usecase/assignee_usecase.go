@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+)
+
+// AssigneeUsecase assigns users to tasks and publishes an event for every
+// assignment made or removed.
+type AssigneeUsecase struct {
+	assignees repository.AssigneeRepository
+	tasks     repository.TaskRepository
+	users     repository.UserRepository
+	cache     Cache
+	access    *TaskAccess
+	events    *EventBus
+}
+
+func NewAssigneeUsecase(assignees repository.AssigneeRepository, tasks repository.TaskRepository, users repository.UserRepository, cache Cache, access *TaskAccess, events *EventBus) *AssigneeUsecase {
+	return &AssigneeUsecase{assignees: assignees, tasks: tasks, users: users, cache: cache, access: access, events: events}
+}
+
+// AddAssignees assigns users to a task and returns the task as it now is.
+// Users already assigned are left as they were. Every user must exist and,
+// for project tasks, belong to the project's workspace.
+func (u *AssigneeUsecase) AddAssignees(ctx context.Context, actorID, taskID int, req dto.AddAssigneesDTO) (dto.TaskResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	task, err := u.writableTask(ctx, taskID)
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	for _, userID := range req.UserIDs {
+		if err := u.checkAssignable(task, userID); err != nil {
+			return dto.TaskResponseDTO{}, err
+		}
+	}
+
+	var added []int
+	for _, userID := range req.UserIDs {
+		ok, err := u.assignees.Add(domain.Assignee{TaskID: taskID, UserID: userID, AssignedBy: actorID})
+		if err != nil {
+			return dto.TaskResponseDTO{}, err
+		}
+		if ok {
+			added = append(added, userID)
+		}
+	}
+	invalidateTasks(u.cache, taskID)
+
+	for _, userID := range added {
+		u.events.Publish(ctx, Event{Type: EventTaskAssigned, TaskID: taskID, ActorID: actorID, Data: AssignmentData{AssigneeID: userID}})
+	}
+
+	task, err = u.tasks.GetByID(taskID)
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	return toTaskResponse(task), nil
+}
+
+func (u *AssigneeUsecase) RemoveAssignee(ctx context.Context, actorID, taskID, userID int) error {
+	if _, err := u.writableTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	removed, err := u.assignees.Remove(taskID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return &domain.NotFoundError{Resource: "Assignee", ID: userID}
+	}
+	invalidateTasks(u.cache, taskID)
+
+	u.events.Publish(ctx, Event{Type: EventTaskUnassigned, TaskID: taskID, ActorID: actorID, Data: AssignmentData{AssigneeID: userID}})
+	return nil
+}
+
+// writableTask returns the task if the caller may change it; assigning
+// follows the same rules as editing.
+func (u *AssigneeUsecase) writableTask(ctx context.Context, taskID int) (domain.Task, error) {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.access.canWriteTask(ctx, task); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+func (u *AssigneeUsecase) checkAssignable(task domain.Task, userID int) error {
+	if _, err := u.users.GetByID(userID); err != nil {
+		if _, ok := err.(*domain.NotFoundError); ok {
+			return &domain.ValidationError{Field: "user_ids", Message: fmt.Sprintf("user %d does not exist", userID)}
+		}
+		return err
+	}
+
+	ok, err := u.access.userCanRead(userID, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &domain.ValidationError{Field: "user_ids", Message: fmt.Sprintf("user %d is not a member of the task's workspace", userID)}
+	}
+	return nil
+}
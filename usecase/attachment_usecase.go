@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"task-manager-api/blobstore"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"time"
+	"unicode/utf8"
+)
+
+// blobGracePeriod is how long a blob is kept after it was last written or
+// deduplicated into, even if no attachment refers to it yet. It covers an
+// upload between storing its blob and recording the attachment, including
+// uploads on other instances.
+const blobGracePeriod = time.Hour
+
+// AttachmentConfig holds the upload limits a deployment may tune.
+type AttachmentConfig struct {
+	// MaxBytes is the largest file accepted.
+	MaxBytes int64
+	// AllowedTypes lists the media types accepted, such as "image/png".
+	// The type is sniffed from the content, not taken from the client.
+	AllowedTypes []string
+}
+
+// AttachmentUsecase stores files on tasks. Metadata goes to the repository
+// and the bytes to a content-addressed blob store, so identical uploads
+// share storage. Blobs no attachment refers to are removed by
+// CollectGarbage.
+type AttachmentUsecase struct {
+	attachments repository.AttachmentRepository
+	tasks       repository.TaskRepository
+	store       blobstore.BlobStore
+	access      *TaskAccess
+	config      AttachmentConfig
+
+	// gcMu keeps the collector from checking a blob while an upload on this
+	// instance records an attachment to it. Uploads hold the read lock only
+	// while recording, never while streaming; the collector takes the write
+	// lock one blob at a time. Uploads still streaming are covered by
+	// blobGracePeriod instead.
+	gcMu     sync.RWMutex
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewAttachmentUsecase(attachments repository.AttachmentRepository, tasks repository.TaskRepository, store blobstore.BlobStore, access *TaskAccess, config AttachmentConfig) *AttachmentUsecase {
+	return &AttachmentUsecase{
+		attachments: attachments,
+		tasks:       tasks,
+		store:       store,
+		access:      access,
+		config:      config,
+		stop:        make(chan struct{}),
+	}
+}
+
+// MaxBytes is the largest file Upload accepts.
+func (u *AttachmentUsecase) MaxBytes() int64 {
+	return u.config.MaxBytes
+}
+
+// AttachmentContent is an open attachment ready to be served.
+type AttachmentContent struct {
+	Attachment dto.AttachmentDTO
+	Content    io.ReadSeekCloser
+}
+
+func (u *AttachmentUsecase) ListAttachments(ctx context.Context, taskID int) ([]dto.AttachmentDTO, error) {
+	if _, err := u.readableTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := u.attachments.ListByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.AttachmentDTO{}
+	for _, attachment := range attachments {
+		result = append(result, toAttachmentDTO(attachment))
+	}
+	return result, nil
+}
+
+// Upload streams content into the blob store and attaches it to the task.
+// It fails with a PayloadTooLargeError once content passes MaxBytes.
+func (u *AttachmentUsecase) Upload(ctx context.Context, userID, taskID int, filename string, content io.Reader) (dto.AttachmentDTO, error) {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return dto.AttachmentDTO{}, err
+	}
+	if err := u.access.canWriteTask(ctx, task); err != nil {
+		return dto.AttachmentDTO{}, err
+	}
+
+	filename, err = cleanFilename(filename)
+	if err != nil {
+		return dto.AttachmentDTO{}, err
+	}
+
+	reader := bufio.NewReaderSize(content, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return dto.AttachmentDTO{}, err
+	}
+	if len(head) == 0 {
+		return dto.AttachmentDTO{}, &domain.ValidationError{Field: "file", Message: "file is empty"}
+	}
+
+	contentType := http.DetectContentType(head)
+	if !u.allowed(contentType) {
+		return dto.AttachmentDTO{}, &domain.ValidationError{Field: "file", Message: fmt.Sprintf("files of type %s are not accepted", mediaType(contentType))}
+	}
+
+	blob, err := u.store.Put(ctx, newLimitedReader(reader, u.config.MaxBytes))
+	if err != nil {
+		return dto.AttachmentDTO{}, err
+	}
+
+	u.gcMu.RLock()
+	defer u.gcMu.RUnlock()
+
+	attachment, err := u.attachments.Create(domain.Attachment{
+		TaskID:      taskID,
+		BlobKey:     blob.Key,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        blob.Size,
+		UploadedBy:  userID,
+	})
+	if err != nil {
+		return dto.AttachmentDTO{}, err
+	}
+	return toAttachmentDTO(attachment), nil
+}
+
+// Open returns an attachment's content. The caller closes it.
+func (u *AttachmentUsecase) Open(ctx context.Context, taskID, attachmentID int) (AttachmentContent, error) {
+	if _, err := u.readableTask(ctx, taskID); err != nil {
+		return AttachmentContent{}, err
+	}
+
+	attachment, err := u.taskAttachment(taskID, attachmentID)
+	if err != nil {
+		return AttachmentContent{}, err
+	}
+
+	content, _, err := u.store.Open(ctx, attachment.BlobKey)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			log.Printf("attachment %d: blob %s is missing", attachment.ID, attachment.BlobKey)
+			return AttachmentContent{}, &domain.NotFoundError{Resource: "Attachment", ID: attachmentID}
+		}
+		return AttachmentContent{}, err
+	}
+	return AttachmentContent{Attachment: toAttachmentDTO(attachment), Content: content}, nil
+}
+
+// DeleteAttachment removes the attachment. Its blob stays until the next
+// collection, and only if nothing else refers to it.
+func (u *AttachmentUsecase) DeleteAttachment(ctx context.Context, taskID, attachmentID int) error {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+	if err := u.access.canWriteTask(ctx, task); err != nil {
+		return err
+	}
+
+	if _, err := u.taskAttachment(taskID, attachmentID); err != nil {
+		return err
+	}
+	return u.attachments.Delete(attachmentID)
+}
+
+// CollectGarbage deletes blobs no attachment refers to that are older than
+// blobGracePeriod, and returns how many it removed.
+func (u *AttachmentUsecase) CollectGarbage(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-blobGracePeriod)
+	var keys []string
+
+	err := u.store.Walk(ctx, func(blob blobstore.Blob) error {
+		if blob.ModTime.Before(cutoff) {
+			keys = append(keys, blob.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, key := range keys {
+		deleted, err := u.collect(ctx, key, cutoff)
+		if err != nil {
+			return removed, err
+		}
+		if deleted {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// collect deletes the blob unless it is referenced or was written after
+// cutoff. The walk's ModTime is checked again here, since an upload may have
+// deduplicated into the blob since.
+func (u *AttachmentUsecase) collect(ctx context.Context, key string, cutoff time.Time) (bool, error) {
+	u.gcMu.Lock()
+	defer u.gcMu.Unlock()
+
+	content, blob, err := u.store.Open(ctx, key)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	content.Close()
+	if !blob.ModTime.Before(cutoff) {
+		return false, nil
+	}
+
+	referenced, err := u.attachments.BlobReferenced(key)
+	if err != nil || referenced {
+		return false, err
+	}
+	if err := u.store.Delete(ctx, key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// StartGC collects unreferenced blobs every interval until Close is called.
+func (u *AttachmentUsecase) StartGC(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-ticker.C:
+				removed, err := u.CollectGarbage(context.Background())
+				if err != nil {
+					log.Printf("attachment gc: %v", err)
+				}
+				if removed > 0 {
+					log.Printf("attachment gc: removed %d unreferenced blobs", removed)
+				}
+			}
+		}
+	}()
+}
+
+func (u *AttachmentUsecase) Close() {
+	u.stopOnce.Do(func() { close(u.stop) })
+}
+
+func (u *AttachmentUsecase) readableTask(ctx context.Context, taskID int) (domain.Task, error) {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.access.canReadTask(ctx, task.ID, task.ProjectID); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+func (u *AttachmentUsecase) taskAttachment(taskID, attachmentID int) (domain.Attachment, error) {
+	attachment, err := u.attachments.GetByID(attachmentID)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if attachment.TaskID != taskID {
+		return domain.Attachment{}, &domain.NotFoundError{Resource: "Attachment", ID: attachmentID}
+	}
+	return attachment, nil
+}
+
+func (u *AttachmentUsecase) allowed(contentType string) bool {
+	for _, allowed := range u.config.AllowedTypes {
+		if strings.EqualFold(mediaType(contentType), allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaType drops parameters such as "; charset=utf-8".
+func mediaType(contentType string) string {
+	if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+		return parsed
+	}
+	return contentType
+}
+
+// cleanFilename keeps only the base name the client sent, which is used
+// for display and Content-Disposition, never as a path.
+func cleanFilename(name string) (string, error) {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "", &domain.ValidationError{Field: "file", Message: "file name is required"}
+	}
+	if !utf8.ValidString(name) || len(name) > 255 {
+		return "", &domain.ValidationError{Field: "file", Message: "file name must be valid UTF-8 of at most 255 bytes"}
+	}
+	return name, nil
+}
+
+// limitedReader fails with a PayloadTooLargeError, rather than stopping
+// quietly like io.LimitReader, once more than remaining bytes are read.
+type limitedReader struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+func newLimitedReader(r io.Reader, limit int64) *limitedReader {
+	return &limitedReader{r: r, limit: limit, remaining: limit}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		return 0, &domain.PayloadTooLargeError{Limit: l.limit}
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func toAttachmentDTO(attachment domain.Attachment) dto.AttachmentDTO {
+	return dto.AttachmentDTO{
+		ID:          attachment.ID,
+		TaskID:      attachment.TaskID,
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Checksum:    attachment.BlobKey,
+		UploadedBy:  attachment.UploadedBy,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
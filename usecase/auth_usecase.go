@@ -2,10 +2,10 @@ package usecase
 
 import (
 	"fmt"
-	"strings"
 	"task-manager-api/domain"
 	"task-manager-api/dto"
 	"task-manager-api/repository"
+	"task-manager-api/validation"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -25,18 +25,8 @@ func NewAuthUsecase(userRepo repository.UserRepository, jwtSecret string) *AuthU
 }
 
 func (u *AuthUsecase) Register(req dto.RegisterUserDTO) (dto.UserResponseDTO, error) {
-	if !strings.Contains(req.Email, "@") {
-		return dto.UserResponseDTO{}, &domain.ValidationError{
-			Field:   "email",
-			Message: "invalid email format",
-		}
-	}
-
-	if len(req.Password) < 8 {
-		return dto.UserResponseDTO{}, &domain.ValidationError{
-			Field:   "password",
-			Message: "password must be at least 8 characters long",
-		}
+	if err := validation.Validate(req); err != nil {
+		return dto.UserResponseDTO{}, err
 	}
 
 	_, err := u.userRepo.GetByEmail(req.Email)
@@ -75,6 +65,10 @@ func (u *AuthUsecase) Register(req dto.RegisterUserDTO) (dto.UserResponseDTO, er
 }
 
 func (u *AuthUsecase) Login(req dto.LoginUserDTO) (dto.LoginResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.LoginResponseDTO{}, err
+	}
+
 	user, err := u.userRepo.GetByEmail(req.Email)
 	if err != nil {
 		return dto.LoginResponseDTO{}, &domain.AuthenticationError{
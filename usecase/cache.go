@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"task-manager-api/dto"
+	"time"
+)
+
+func init() {
+	RegisterCacheType(dto.TaskResponseDTO{})
+	RegisterCacheType([]dto.TaskResponseDTO{})
+	RegisterCacheType(TaskList{})
+	RegisterCacheType([]int{})
+}
+
+// RegisterCacheType makes values of the same type as value storable in a
+// RedisCache and in CacheService snapshots. Both gob-encode values, which
+// needs every concrete type stored behind an interface{} registered up front.
+func RegisterCacheType(value interface{}) {
+	gob.Register(value)
+}
+
+// Cache is the cache the usecases and handlers depend on. CacheService keeps
+// entries in process memory; RedisCache shares them between API replicas.
+//
+// Entries can carry tags naming the data they were derived from, such as
+// "tasks" or "task:42". InvalidateTag drops every entry with that tag, so a
+// mutation does not need to know which keys were built from what it changed.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
+	SetWithTags(key string, value interface{}, tags ...string)
+	Delete(key string)
+	InvalidateTag(tag string) int
+	Clear()
+	GetOrLoad(ctx context.Context, key string, loader CacheLoader, tags ...string) (interface{}, error)
+	GetStats() CacheStats
+}
+
+const tagTasks = "tasks"
+
+// taskTag tags entries derived from a single task.
+func taskTag(id int) string {
+	return fmt.Sprintf("task:%d", id)
+}
+
+// memberTag tags entries derived from a user's workspace memberships, such as
+// the projects whose tasks they can see.
+func memberTag(userID int) string {
+	return fmt.Sprintf("member:%d", userID)
+}
+
+// CacheLoader produces the value for a key that is missing from the cache.
+type CacheLoader func(ctx context.Context) (interface{}, error)
+
+// CacheStats describes a Cache. Size and limit fields are zero for backends
+// that do not track them.
+type CacheStats struct {
+	Backend      string `json:"backend"`
+	Available    bool   `json:"available"`
+	TotalEntries int    `json:"total_entries"`
+	TTL          int    `json:"ttl_seconds"`
+	MaxEntries   int    `json:"max_entries"`
+	Bytes        int64  `json:"bytes"`
+	MaxBytes     int64  `json:"max_bytes"`
+	Hits         uint64 `json:"hits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Expirations  uint64 `json:"expirations"`
+	StaleHits    uint64 `json:"stale_hits"`
+	Loads        uint64 `json:"loads"`
+	Errors       uint64 `json:"errors"`
+}
+
+// cacheLoad is a loader call shared by every GetOrLoad waiting on the same key.
+type cacheLoad struct {
+	tags        []string
+	done        chan struct{}
+	value       interface{}
+	err         error
+	invalidated bool // the key was deleted mid-load, so the result is not cached
+}
@@ -1,82 +1,431 @@
 package usecase
 
 import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
 	"sync"
+	"task-manager-api/domain"
 	"time"
 )
 
+// CacheConfig bounds a CacheService. A zero MaxEntries or MaxBytes means that
+// limit is not enforced; when either is exceeded the least recently used
+// entries are evicted.
+//
+// StaleWhileRevalidate and NegativeTTL only affect GetOrLoad: an expired value
+// is still served for StaleWhileRevalidate while it is refreshed in the
+// background, and a loader's NotFoundError is remembered for NegativeTTL.
+type CacheConfig struct {
+	TTL                  time.Duration
+	MaxEntries           int
+	MaxBytes             int64
+	StaleWhileRevalidate time.Duration
+	NegativeTTL          time.Duration
+}
+
+// CacheService is the in-process Cache.
 type CacheService struct {
-	mu    sync.RWMutex
-	cache map[string]*cacheEntry
-	ttl   time.Duration
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+	config  CacheConfig
+	bytes   int64
+	tags    map[string]map[string]struct{} // tag -> keys
+	loads   map[string]*cacheLoad
+
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
+	staleHits   uint64
+	loadCount   uint64
+
+	stop         chan struct{}
+	stopOnce     sync.Once
+	snapshotDone chan struct{} // closed after the final snapshot, if enabled
 }
 
 type cacheEntry struct {
-	value     interface{}
-	createdAt time.Time
+	key        string
+	value      interface{}
+	err        error // set for negative entries
+	tags       []string
+	size       int64
+	expiresAt  time.Time
+	staleUntil time.Time
 }
 
 func NewCacheService(ttl time.Duration) *CacheService {
+	return NewCacheServiceWithConfig(CacheConfig{TTL: ttl})
+}
+
+func NewCacheServiceWithConfig(config CacheConfig) *CacheService {
 	return &CacheService{
-		mu:    sync.RWMutex{},
-		cache: make(map[string]*cacheEntry),
-		ttl:   ttl,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		tags:    make(map[string]map[string]struct{}),
+		loads:   make(map[string]*cacheLoad),
+		config:  config,
+		stop:    make(chan struct{}),
 	}
 }
 
 func (c *CacheService) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	entry, exists := c.cache[key]
+	elem, exists := c.entries[key]
 	if !exists {
+		c.misses++
 		return nil, false
 	}
 
-	if entry.createdAt.Add(c.ttl).Before(time.Now()) {
+	entry := elem.Value.(*cacheEntry)
+	now := time.Now()
+	if entry.expired(now) || entry.err != nil {
+		if entry.dead(now) {
+			c.removeElement(elem)
+			c.expirations++
+		}
+		c.misses++
 		return nil, false
 	}
 
+	c.lru.MoveToFront(elem)
+	c.hits++
 	return entry.value, true
 }
 
 func (c *CacheService) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.config.TTL)
+}
+
+// SetWithTTL stores value under key for ttl instead of the service default.
+func (c *CacheService) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	entry := c.newEntry(key, value, ttl, nil)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	entry := &cacheEntry{
-		value:     value,
-		createdAt: time.Now(),
+	c.insert(entry)
+}
+
+// SetWithTags stores value under key for the default TTL, tagged so that
+// InvalidateTag on any of tags removes it.
+func (c *CacheService) SetWithTags(key string, value interface{}, tags ...string) {
+	entry := c.newEntry(key, value, c.config.TTL, tags)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.insert(entry)
+}
+
+func (c *CacheService) newEntry(key string, value interface{}, ttl time.Duration, tags []string) *cacheEntry {
+	expiresAt := time.Now().Add(ttl)
+	return &cacheEntry{
+		key:        key,
+		value:      value,
+		tags:       tags,
+		size:       estimateSize(key, value),
+		expiresAt:  expiresAt,
+		staleUntil: expiresAt.Add(c.config.StaleWhileRevalidate),
 	}
+}
 
-	c.cache[key] = entry
+func (c *CacheService) newNegativeEntry(key string, err error, tags []string) *cacheEntry {
+	expiresAt := time.Now().Add(c.config.NegativeTTL)
+	return &cacheEntry{
+		key:        key,
+		err:        err,
+		tags:       tags,
+		size:       int64(len(key)),
+		expiresAt:  expiresAt,
+		staleUntil: expiresAt,
+	}
+}
+
+// insert stores entry, evicting as needed. c.mu must be held.
+func (c *CacheService) insert(entry *cacheEntry) {
+	key := entry.key
+	if elem, exists := c.entries[key]; exists {
+		c.removeElement(elem)
+	}
+
+	// A value larger than the whole budget would evict everything and still
+	// not fit, so it is not cached at all.
+	if c.config.MaxBytes > 0 && entry.size > c.config.MaxBytes {
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	c.bytes += entry.size
+	for _, tag := range entry.tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+	c.evictOverflow()
 }
 
 func (c *CacheService) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	delete(c.cache, key)
+	if elem, exists := c.entries[key]; exists {
+		c.removeElement(elem)
+	}
+	if load, loading := c.loads[key]; loading {
+		load.invalidated = true
+	}
+}
+
+// InvalidateTag removes every entry tagged with tag and keeps loads already
+// running for such entries from caching their result. It returns the number
+// of entries removed.
+func (c *CacheService) InvalidateTag(tag string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key := range c.tags[tag] {
+		if elem, exists := c.entries[key]; exists {
+			c.removeElement(elem)
+			removed++
+		}
+	}
+	delete(c.tags, tag)
+
+	for _, load := range c.loads {
+		if slices.Contains(load.tags, tag) {
+			load.invalidated = true
+		}
+	}
+
+	return removed
 }
 
 func (c *CacheService) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.cache = make(map[string]*cacheEntry)
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+	c.tags = make(map[string]map[string]struct{})
+	for _, load := range c.loads {
+		load.invalidated = true
+	}
 }
 
-type CacheStats struct {
-	TotalEntries int `json:"total_entries"`
-	TTL          int `json:"ttl_seconds"`
+// GetOrLoad returns the cached value for key, calling loader on a miss.
+// Concurrent callers missing the same key share a single loader call. An
+// expired value within the StaleWhileRevalidate window is returned at once
+// while one background load refreshes it. A NotFoundError from loader is
+// cached for NegativeTTL and returned to later callers without loading.
+//
+// The loader runs detached from ctx so that one caller giving up does not
+// fail the load for everyone else waiting on it.
+func (c *CacheService) GetOrLoad(ctx context.Context, key string, loader CacheLoader, tags ...string) (interface{}, error) {
+	c.mu.Lock()
+
+	if elem, exists := c.entries[key]; exists {
+		entry := elem.Value.(*cacheEntry)
+		now := time.Now()
+
+		if !entry.expired(now) {
+			c.lru.MoveToFront(elem)
+			c.hits++
+			c.mu.Unlock()
+			return entry.value, entry.err
+		}
+
+		if !entry.dead(now) {
+			c.lru.MoveToFront(elem)
+			c.hits++
+			c.staleHits++
+			c.startLoad(ctx, key, loader, tags)
+			c.mu.Unlock()
+			return entry.value, nil
+		}
+	}
+
+	c.misses++
+	load := c.startLoad(ctx, key, loader, tags)
+	c.mu.Unlock()
+
+	select {
+	case <-load.done:
+		return load.value, load.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startLoad returns the in-flight load for key, starting one if there is none.
+// c.mu must be held.
+func (c *CacheService) startLoad(ctx context.Context, key string, loader CacheLoader, tags []string) *cacheLoad {
+	if load, loading := c.loads[key]; loading {
+		return load
+	}
+
+	load := &cacheLoad{tags: tags, done: make(chan struct{})}
+	c.loads[key] = load
+	c.loadCount++
+
+	go func() {
+		load.value, load.err = runLoader(context.WithoutCancel(ctx), key, loader)
+
+		var entry *cacheEntry
+		var notFound *domain.NotFoundError
+		switch {
+		case load.err == nil:
+			entry = c.newEntry(key, load.value, c.config.TTL, tags)
+		case errors.As(load.err, &notFound) && c.config.NegativeTTL > 0:
+			entry = c.newNegativeEntry(key, load.err, tags)
+		}
+
+		// Storing under the same lock that clears c.loads means a Delete
+		// either sees the load and invalidates it, or runs after the store.
+		c.mu.Lock()
+		if entry != nil && !load.invalidated {
+			c.insert(entry)
+		}
+		delete(c.loads, key)
+		c.mu.Unlock()
+
+		close(load.done)
+	}()
+
+	return load
+}
+
+// runLoader calls loader, turning a panic into an error. Loaders run on
+// their own goroutine, where the server's recovery middleware can't catch it.
+func runLoader(ctx context.Context, key string, loader CacheLoader) (value interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Panic recovered loading cache key %s: %v", key, recovered)
+			err = fmt.Errorf("loading %s: panic: %v", key, recovered)
+		}
+	}()
+	return loader(ctx)
+}
+
+// StartJanitor purges expired entries every interval until Close is called.
+func (c *CacheService) StartJanitor(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.purgeExpired()
+			}
+		}
+	}()
+}
+
+// Close stops the background goroutines, waiting for the final snapshot when
+// snapshots are enabled.
+func (c *CacheService) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+	if c.snapshotDone != nil {
+		<-c.snapshotDone
+	}
+}
+
+func (c *CacheService) purgeExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if elem.Value.(*cacheEntry).dead(now) {
+			c.removeElement(elem)
+			c.expirations++
+		}
+		elem = prev
+	}
+}
+
+func (c *CacheService) evictOverflow() {
+	for c.overLimit() {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			return
+		}
+		c.removeElement(oldest)
+		c.evictions++
+	}
+}
+
+func (c *CacheService) overLimit() bool {
+	if c.config.MaxEntries > 0 && c.lru.Len() > c.config.MaxEntries {
+		return true
+	}
+	return c.config.MaxBytes > 0 && c.bytes > c.config.MaxBytes
+}
+
+func (c *CacheService) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], entry.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !now.Before(e.expiresAt)
+}
+
+// dead reports whether the entry is past its stale window and can be dropped.
+func (e *cacheEntry) dead(now time.Time) bool {
+	return !now.Before(e.staleUntil)
+}
+
+// estimateSize approximates the memory an entry holds by the length of its
+// JSON encoding, which is what the cached DTOs are eventually sent as.
+func estimateSize(key string, value interface{}) int64 {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("cache: could not size %q: %v", key, err)
+		return int64(len(key))
+	}
+	return int64(len(key) + len(encoded))
 }
 
 func (c *CacheService) GetStats() CacheStats {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	return CacheStats{
-		TotalEntries: len(c.cache),
-		TTL:          int(c.ttl.Seconds()),
+		Backend:      "memory",
+		Available:    true,
+		TotalEntries: c.lru.Len(),
+		TTL:          int(c.config.TTL.Seconds()),
+		MaxEntries:   c.config.MaxEntries,
+		Bytes:        c.bytes,
+		MaxBytes:     c.config.MaxBytes,
+		Hits:         c.hits,
+		Misses:       c.misses,
+		Evictions:    c.evictions,
+		Expirations:  c.expirations,
+		StaleHits:    c.staleHits,
+		Loads:        c.loadCount,
 	}
 }
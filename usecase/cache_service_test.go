@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheServiceGetOrLoadRecoversLoaderPanics(t *testing.T) {
+	cache := NewCacheService(time.Minute)
+	defer cache.Close()
+
+	_, err := cache.GetOrLoad(context.Background(), "boom", func(ctx context.Context) (interface{}, error) {
+		panic("loader bug")
+	})
+	if err == nil || !strings.Contains(err.Error(), "loader bug") {
+		t.Fatalf("got %v, want the panic as an error", err)
+	}
+
+	// The failed load is not cached, and the key can be loaded again.
+	value, err := cache.GetOrLoad(context.Background(), "boom", func(ctx context.Context) (interface{}, error) {
+		return "ok", nil
+	})
+	if err != nil || value != "ok" {
+		t.Fatalf("got %v, %v after the panic, want ok", value, err)
+	}
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const snapshotVersion = 1
+
+// cacheSnapshot is the file written by SaveSnapshot. Each entry is encoded
+// separately so one value of an unregistered type only loses that entry.
+type cacheSnapshot struct {
+	Version int
+	SavedAt time.Time
+	Entries [][]byte // gob-encoded snapshotEntry, least recently used first
+}
+
+type snapshotEntry struct {
+	Key        string
+	Value      interface{}
+	Tags       []string
+	ExpiresAt  time.Time
+	StaleUntil time.Time
+}
+
+// WriteSnapshot writes every live entry to w. Negative entries are skipped;
+// they are cheap to rebuild and only live for seconds.
+func (c *CacheService) WriteSnapshot(w io.Writer) (int, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	var live []snapshotEntry
+	for elem := c.lru.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*cacheEntry)
+		if entry.err != nil || entry.dead(now) {
+			continue
+		}
+		live = append(live, snapshotEntry{
+			Key:        entry.key,
+			Value:      entry.value,
+			Tags:       entry.tags,
+			ExpiresAt:  entry.expiresAt,
+			StaleUntil: entry.staleUntil,
+		})
+	}
+	c.mu.Unlock()
+
+	snapshot := cacheSnapshot{Version: snapshotVersion, SavedAt: now}
+	for _, entry := range live {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+			log.Printf("cache: leaving %q out of snapshot: %v", entry.Key, err)
+			continue
+		}
+		snapshot.Entries = append(snapshot.Entries, buf.Bytes())
+	}
+
+	if err := gob.NewEncoder(w).Encode(snapshot); err != nil {
+		return 0, err
+	}
+	return len(snapshot.Entries), nil
+}
+
+// ReadSnapshot loads entries written by WriteSnapshot. Entries keep their
+// original expiry times, so anything that expired while the process was down
+// is dropped rather than given a fresh TTL.
+func (c *CacheService) ReadSnapshot(r io.Reader) (int, error) {
+	var snapshot cacheSnapshot
+	if err := gob.NewDecoder(r).Decode(&snapshot); err != nil {
+		return 0, err
+	}
+	if snapshot.Version != snapshotVersion {
+		return 0, fmt.Errorf("unsupported cache snapshot version %d", snapshot.Version)
+	}
+
+	now := time.Now()
+	var entries []*cacheEntry
+	for _, data := range snapshot.Entries {
+		var stored snapshotEntry
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); err != nil {
+			log.Printf("cache: skipping unreadable snapshot entry: %v", err)
+			continue
+		}
+
+		entry := &cacheEntry{
+			key:        stored.Key,
+			value:      stored.Value,
+			tags:       stored.Tags,
+			size:       estimateSize(stored.Key, stored.Value),
+			expiresAt:  stored.ExpiresAt,
+			staleUntil: stored.StaleUntil,
+		}
+		if entry.dead(now) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Inserting least recently used first rebuilds the original LRU order.
+	for _, entry := range entries {
+		c.insert(entry)
+	}
+	return len(entries), nil
+}
+
+// SaveSnapshot writes a snapshot to path, replacing it atomically so a crash
+// mid-write never leaves a truncated file behind.
+func (c *CacheService) SaveSnapshot(path string) (int, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	count, err := c.WriteSnapshot(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return count, os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot restores a snapshot saved at path. A missing file is not an
+// error; the cache simply starts cold.
+func (c *CacheService) LoadSnapshot(path string) (int, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return c.ReadSnapshot(file)
+}
+
+// StartSnapshots saves a snapshot to path every interval, and once more when
+// Close is called.
+func (c *CacheService) StartSnapshots(path string, interval time.Duration) {
+	c.snapshotDone = make(chan struct{})
+
+	go func() {
+		defer close(c.snapshotDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.stop:
+				c.saveSnapshotLogged(path)
+				return
+			case <-ticker.C:
+				c.saveSnapshotLogged(path)
+			}
+		}
+	}()
+}
+
+func (c *CacheService) saveSnapshotLogged(path string) {
+	if _, err := c.SaveSnapshot(path); err != nil {
+		log.Printf("cache: snapshot to %s failed: %v", path, err)
+	}
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// CacheWarmer preloads one hot cache entry before the server starts taking
+// traffic.
+type CacheWarmer struct {
+	Name string
+	Load func(ctx context.Context) error
+}
+
+// WarmCache runs every warmer concurrently and waits for them, or for ctx to
+// end. A failing warmer is logged and skipped; the entry is then loaded on
+// first use as usual.
+func WarmCache(ctx context.Context, warmers []CacheWarmer) {
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for _, warmer := range warmers {
+		wg.Add(1)
+		go func(warmer CacheWarmer) {
+			defer wg.Done()
+			if err := warmer.Load(ctx); err != nil {
+				log.Printf("cache warm-up %s failed: %v", warmer.Name, err)
+			}
+		}(warmer)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Printf("Cache warmed with %d entries in %v", len(warmers), time.Since(start))
+	case <-ctx.Done():
+		log.Printf("Cache warm-up stopped after %v: %v", time.Since(start), ctx.Err())
+	}
+}
+
+// CacheWarmers lists the task entries worth loading at startup.
+func (u *TaskUsecase) CacheWarmers() []CacheWarmer {
+	return []CacheWarmer{
+		{
+			Name: "task_list",
+			Load: func(ctx context.Context) error {
+				_, err := u.ListTasks(ctx)
+				return err
+			},
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+)
+
+// ChecklistUsecase edits the checklist inside a task. Every change returns
+// the task as it now is, since its progress moves with the checklist.
+type ChecklistUsecase struct {
+	checklists repository.ChecklistRepository
+	tasks      repository.TaskRepository
+	cache      Cache
+	access     *TaskAccess
+	events     *EventBus
+}
+
+func NewChecklistUsecase(checklists repository.ChecklistRepository, tasks repository.TaskRepository, cache Cache, access *TaskAccess, events *EventBus) *ChecklistUsecase {
+	return &ChecklistUsecase{checklists: checklists, tasks: tasks, cache: cache, access: access, events: events}
+}
+
+func (u *ChecklistUsecase) AddItem(ctx context.Context, taskID int, req dto.ChecklistItemRequestDTO) (dto.TaskResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	if err := u.checkWritable(ctx, taskID); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	if _, err := u.checklists.Add(domain.ChecklistItem{TaskID: taskID, Text: req.Text, Done: req.Done}); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	return u.changed(ctx, taskID)
+}
+
+func (u *ChecklistUsecase) UpdateItem(ctx context.Context, taskID, itemID int, req dto.ChecklistItemRequestDTO) (dto.TaskResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	if err := u.checkWritable(ctx, taskID); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	if err := u.checklists.Update(domain.ChecklistItem{ID: itemID, TaskID: taskID, Text: req.Text, Done: req.Done}); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	return u.changed(ctx, taskID)
+}
+
+func (u *ChecklistUsecase) DeleteItem(ctx context.Context, taskID, itemID int) (dto.TaskResponseDTO, error) {
+	if err := u.checkWritable(ctx, taskID); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	if err := u.checklists.Delete(taskID, itemID); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	return u.changed(ctx, taskID)
+}
+
+func (u *ChecklistUsecase) checkWritable(ctx context.Context, taskID int) error {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+	return u.access.canWriteTask(ctx, task)
+}
+
+// changed runs the auto-complete roll-up from the task and reloads it.
+func (u *ChecklistUsecase) changed(ctx context.Context, taskID int) (dto.TaskResponseDTO, error) {
+	invalidateTasks(u.cache, taskID)
+	finishParents(ctx, u.tasks, u.cache, u.events, taskID)
+
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	return toTaskResponse(task), nil
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// instanceOwner names this process in the claims it takes on queued work,
+// such as due reminders and webhook deliveries.
+func instanceOwner() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+)
+
+// CommentUsecase manages discussion on tasks. Anyone who can see a task may
+// read and post comments on it; only a comment's author, or an admin of the
+// task's workspace, may edit or delete it.
+type CommentUsecase struct {
+	comments repository.CommentRepository
+	tasks    repository.TaskRepository
+	users    repository.UserRepository
+	cache    Cache
+	access   *TaskAccess
+	events   *EventBus
+}
+
+func NewCommentUsecase(comments repository.CommentRepository, tasks repository.TaskRepository, users repository.UserRepository, cache Cache, access *TaskAccess, events *EventBus) *CommentUsecase {
+	return &CommentUsecase{comments: comments, tasks: tasks, users: users, cache: cache, access: access, events: events}
+}
+
+// ListComments returns a task's comments as threads, oldest first. Deleted
+// comments stay only as placeholders for replies that are still there.
+func (u *CommentUsecase) ListComments(ctx context.Context, taskID int) ([]dto.CommentDTO, error) {
+	if _, err := u.readableTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	comments, err := u.comments.ListByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Comments come oldest first, so every parent precedes its replies.
+	threads := []dto.CommentDTO{}
+	index := make(map[int]int)
+	for _, comment := range comments {
+		if comment.ParentID == 0 {
+			index[comment.ID] = len(threads)
+			threads = append(threads, toCommentDTO(comment))
+			continue
+		}
+		if i, ok := index[comment.ParentID]; ok && !comment.Deleted() {
+			threads[i].Replies = append(threads[i].Replies, toCommentDTO(comment))
+		}
+	}
+
+	visible := []dto.CommentDTO{}
+	for _, thread := range threads {
+		if !thread.Deleted || len(thread.Replies) > 0 {
+			visible = append(visible, thread)
+		}
+	}
+	return visible, nil
+}
+
+func (u *CommentUsecase) CreateComment(ctx context.Context, authorID, taskID int, req dto.CreateCommentDTO) (dto.CommentDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.CommentDTO{}, err
+	}
+
+	task, err := u.readableTask(ctx, taskID)
+	if err != nil {
+		return dto.CommentDTO{}, err
+	}
+
+	if req.ParentID != 0 {
+		if err := u.checkReplyable(taskID, req.ParentID); err != nil {
+			return dto.CommentDTO{}, err
+		}
+	}
+
+	mentions, err := u.resolveMentions(task, req.Body)
+	if err != nil {
+		return dto.CommentDTO{}, err
+	}
+
+	comment, err := u.comments.Create(domain.Comment{
+		TaskID:   taskID,
+		AuthorID: authorID,
+		ParentID: req.ParentID,
+		Body:     req.Body,
+		Mentions: mentions,
+	})
+	if err != nil {
+		return dto.CommentDTO{}, err
+	}
+	invalidateTasks(u.cache, taskID)
+
+	u.events.Publish(ctx, Event{
+		Type:    EventCommentCreated,
+		TaskID:  taskID,
+		ActorID: authorID,
+		Data:    CommentData{CommentID: comment.ID, ParentID: comment.ParentID, Mentions: comment.Mentions},
+	})
+
+	return toCommentDTO(comment), nil
+}
+
+func (u *CommentUsecase) UpdateComment(ctx context.Context, userID, taskID, commentID int, req dto.UpdateCommentDTO) (dto.CommentDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.CommentDTO{}, err
+	}
+
+	task, comment, err := u.changeableComment(ctx, userID, taskID, commentID)
+	if err != nil {
+		return dto.CommentDTO{}, err
+	}
+
+	comment.Body = req.Body
+	comment.Mentions, err = u.resolveMentions(task, req.Body)
+	if err != nil {
+		return dto.CommentDTO{}, err
+	}
+
+	comment, err = u.comments.Update(comment)
+	if err != nil {
+		return dto.CommentDTO{}, err
+	}
+	return toCommentDTO(comment), nil
+}
+
+func (u *CommentUsecase) DeleteComment(ctx context.Context, userID, taskID, commentID int) error {
+	if _, _, err := u.changeableComment(ctx, userID, taskID, commentID); err != nil {
+		return err
+	}
+
+	if err := u.comments.Delete(commentID); err != nil {
+		return err
+	}
+	invalidateTasks(u.cache, taskID)
+	return nil
+}
+
+func (u *CommentUsecase) readableTask(ctx context.Context, taskID int) (domain.Task, error) {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.access.canReadTask(ctx, task.ID, task.ProjectID); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+// checkReplyable keeps threads one level deep: replies go to live top-level
+// comments on the same task.
+func (u *CommentUsecase) checkReplyable(taskID, parentID int) error {
+	parent, err := u.comments.GetByID(parentID)
+	if _, ok := err.(*domain.NotFoundError); ok || (err == nil && (parent.TaskID != taskID || parent.Deleted())) {
+		return &domain.ValidationError{Field: "parent_id", Message: fmt.Sprintf("comment %d does not exist on this task", parentID)}
+	}
+	if err != nil {
+		return err
+	}
+	if parent.ParentID != 0 {
+		return &domain.ValidationError{Field: "parent_id", Message: fmt.Sprintf("comment %d is a reply; reply to comment %d instead", parentID, parent.ParentID)}
+	}
+	return nil
+}
+
+// changeableComment loads a live comment on the task and checks the caller
+// wrote it or administers the task's workspace.
+func (u *CommentUsecase) changeableComment(ctx context.Context, userID, taskID, commentID int) (domain.Task, domain.Comment, error) {
+	task, err := u.readableTask(ctx, taskID)
+	if err != nil {
+		return domain.Task{}, domain.Comment{}, err
+	}
+
+	comment, err := u.comments.GetByID(commentID)
+	if err != nil {
+		return domain.Task{}, domain.Comment{}, err
+	}
+	if comment.TaskID != taskID || comment.Deleted() {
+		return domain.Task{}, domain.Comment{}, &domain.NotFoundError{Resource: "Comment", ID: commentID}
+	}
+
+	if comment.AuthorID == userID {
+		return task, comment, nil
+	}
+	if task.ProjectID != 0 {
+		if _, err := u.access.authorizeProject(ctx, task.ProjectID, domain.RoleAdmin); err == nil {
+			return task, comment, nil
+		}
+	}
+	return domain.Task{}, domain.Comment{}, &domain.ForbiddenError{Message: "only the comment's author or a workspace admin can change it"}
+}
+
+// mentionPattern matches "@" followed by an email address, such as
+// "@ana@example.com". The address must start the text or follow a
+// character that can't be part of one.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9._%+@-])@([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// resolveMentions returns the IDs of the users @mentioned in body who can
+// see the task. Addresses that match no such user stay plain text.
+func (u *CommentUsecase) resolveMentions(task domain.Task, body string) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		user, err := u.users.GetByEmail(match[1])
+		if _, ok := err.(*domain.NotFoundError); ok {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if seen[user.ID] {
+			continue
+		}
+
+		ok, err := u.access.userCanRead(user.ID, task.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			seen[user.ID] = true
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+func toCommentDTO(comment domain.Comment) dto.CommentDTO {
+	result := dto.CommentDTO{
+		ID:          comment.ID,
+		TaskID:      comment.TaskID,
+		AuthorID:    comment.AuthorID,
+		AuthorEmail: comment.AuthorEmail,
+		ParentID:    comment.ParentID,
+		Body:        comment.Body,
+		Mentions:    comment.Mentions,
+		CreatedAt:   comment.CreatedAt,
+		EditedAt:    comment.EditedAt,
+	}
+	if comment.Deleted() {
+		result.Body, result.Mentions, result.Deleted = "", nil, true
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+)
+
+// DependencyUsecase manages "blocked by" links between tasks. Adding one
+// needs edit rights on the blocked task and sight of the blocker.
+type DependencyUsecase struct {
+	dependencies repository.DependencyRepository
+	tasks        repository.TaskRepository
+	cache        Cache
+	access       *TaskAccess
+	events       *EventBus
+}
+
+func NewDependencyUsecase(dependencies repository.DependencyRepository, tasks repository.TaskRepository, cache Cache, access *TaskAccess, events *EventBus) *DependencyUsecase {
+	return &DependencyUsecase{dependencies: dependencies, tasks: tasks, cache: cache, access: access, events: events}
+}
+
+// AddDependency marks a task as blocked by another and returns the task as
+// it now is. Adding a dependency that already exists changes nothing.
+func (u *DependencyUsecase) AddDependency(ctx context.Context, taskID int, req dto.AddDependencyDTO) (dto.TaskResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	if req.BlockedByID == taskID {
+		return dto.TaskResponseDTO{}, &domain.ValidationError{Field: "blocked_by_id", Message: "a task cannot block itself"}
+	}
+
+	if err := u.checkWritable(ctx, taskID); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	blocker, err := u.tasks.GetByID(req.BlockedByID)
+	if err == nil {
+		err = u.access.canReadTask(ctx, blocker.ID, blocker.ProjectID)
+	}
+	if _, ok := err.(*domain.NotFoundError); ok {
+		return dto.TaskResponseDTO{}, &domain.ValidationError{Field: "blocked_by_id", Message: fmt.Sprintf("task %d does not exist", req.BlockedByID)}
+	}
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	added, err := u.dependencies.Add(domain.Dependency{TaskID: taskID, BlockedByID: req.BlockedByID})
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	if added {
+		invalidateTasks(u.cache, taskID, req.BlockedByID)
+	}
+
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	if added {
+		publishTask(ctx, u.events, EventTaskUpdated, task)
+	}
+	return toTaskResponse(task), nil
+}
+
+func (u *DependencyUsecase) RemoveDependency(ctx context.Context, taskID, blockedByID int) error {
+	if err := u.checkWritable(ctx, taskID); err != nil {
+		return err
+	}
+
+	removed, err := u.dependencies.Remove(taskID, blockedByID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return &domain.NotFoundError{Resource: "Dependency", ID: blockedByID}
+	}
+	invalidateTasks(u.cache, taskID, blockedByID)
+
+	// The task's blocked flag and version changed with it. Losing its last
+	// open blocker may also let an auto-completing parent finish.
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+	publishTask(ctx, u.events, EventTaskUpdated, task)
+	finishParents(ctx, u.tasks, u.cache, u.events, task.ParentID)
+	return nil
+}
+
+// Graph returns the dependency DAG around a task, with a topological order
+// and the critical path by estimate.
+func (u *DependencyUsecase) Graph(ctx context.Context, taskID int) (dto.DependencyGraphDTO, error) {
+	root, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return dto.DependencyGraphDTO{}, err
+	}
+	if err := u.access.canReadTask(ctx, root.ID, root.ProjectID); err != nil {
+		return dto.DependencyGraphDTO{}, err
+	}
+
+	dependencies, err := u.dependencies.Graph(taskID)
+	if err != nil {
+		return dto.DependencyGraphDTO{}, err
+	}
+
+	tasks := map[int]domain.Task{root.ID: root}
+	ids := []int{root.ID}
+	for _, dependency := range dependencies {
+		for _, id := range []int{dependency.TaskID, dependency.BlockedByID} {
+			if _, seen := tasks[id]; seen {
+				continue
+			}
+			task, err := u.readableTask(ctx, id)
+			if err != nil {
+				return dto.DependencyGraphDTO{}, err
+			}
+			tasks[id] = task
+			if task.ID != 0 {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	graph := dto.DependencyGraphDTO{TaskID: taskID, Nodes: []dto.DependencyNodeDTO{}, Edges: []dto.DependencyEdgeDTO{}}
+	var edges []domain.Dependency
+	for _, dependency := range dependencies {
+		if tasks[dependency.TaskID].ID == 0 || tasks[dependency.BlockedByID].ID == 0 {
+			continue
+		}
+		edges = append(edges, dependency)
+		graph.Edges = append(graph.Edges, dto.DependencyEdgeDTO{TaskID: dependency.TaskID, BlockedByID: dependency.BlockedByID})
+	}
+
+	graph.Order = topologicalOrder(ids, edges)
+
+	weight := make(map[int]int, len(ids))
+	for _, id := range graph.Order {
+		task := tasks[id]
+		if task.Status != domain.StatusCompleted {
+			weight[id] = task.EstimateMinutes
+		}
+		graph.Nodes = append(graph.Nodes, dto.DependencyNodeDTO{
+			ID:              task.ID,
+			Title:           task.Title,
+			Status:          task.Status,
+			EstimateMinutes: task.EstimateMinutes,
+			Blocked:         task.Blocked,
+		})
+	}
+	graph.CriticalPath, graph.CriticalPathMinutes = criticalPath(graph.Order, edges, weight)
+
+	return graph, nil
+}
+
+// readableTask loads a task for the graph, returning a zero Task for one
+// the caller can't see or that was deleted meanwhile.
+func (u *DependencyUsecase) readableTask(ctx context.Context, id int) (domain.Task, error) {
+	task, err := u.tasks.GetByID(id)
+	if err == nil {
+		err = u.access.canReadTask(ctx, task.ID, task.ProjectID)
+	}
+	if _, ok := err.(*domain.NotFoundError); ok {
+		return domain.Task{}, nil
+	}
+	return task, err
+}
+
+func (u *DependencyUsecase) checkWritable(ctx context.Context, taskID int) error {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+	return u.access.canWriteTask(ctx, task)
+}
+
+// checkUnblocked refuses to complete a task while any task it is blocked by
+// is still open.
+func checkUnblocked(task domain.Task) error {
+	if !task.Blocked {
+		return nil
+	}
+
+	ids := make([]string, len(task.BlockedBy))
+	for i, id := range task.BlockedBy {
+		ids[i] = strconv.Itoa(id)
+	}
+	return &domain.ValidationError{
+		Field:   "status",
+		Message: "task is blocked; complete the tasks it is blocked by first (" + strings.Join(ids, ", ") + ")",
+	}
+}
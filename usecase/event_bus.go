@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"task-manager-api/dto"
+	"time"
+)
+
+// Event types published on the EventBus.
+const (
+	EventTaskCreated    = "task.created"
+	EventTaskUpdated    = "task.updated"
+	EventTaskDeleted    = "task.deleted"
+	EventTaskAssigned   = "task.assigned"
+	EventTaskUnassigned = "task.unassigned"
+	EventCommentCreated = "comment.created"
+)
+
+// Event is something that happened to a task, published once the change is
+// committed. Data holds the type-specific details, such as AssignmentData.
+type Event struct {
+	Type    string      `json:"type"`
+	TaskID  int         `json:"task_id"`
+	ActorID int         `json:"actor_id,omitempty"` // 0 for anonymous callers
+	At      time.Time   `json:"at"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// TaskData is the Data of task.created, task.updated and task.deleted
+// events: the task as saved, or as it was before task.deleted.
+type TaskData struct {
+	Task dto.TaskResponseDTO `json:"task"`
+}
+
+// AssignmentData is the Data of task.assigned and task.unassigned events.
+type AssignmentData struct {
+	AssigneeID int `json:"assignee_id"`
+}
+
+// CommentData is the Data of comment.created events. Mentions lists the
+// users @mentioned in the comment.
+type CommentData struct {
+	CommentID int   `json:"comment_id"`
+	ParentID  int   `json:"parent_id,omitempty"`
+	Mentions  []int `json:"mentions,omitempty"`
+}
+
+type EventHandler func(ctx context.Context, event Event)
+
+// EventBus delivers events to subscribers in-process. Handlers run
+// synchronously on the publishing goroutine, in subscription order, so they
+// must be quick; anything slow (network calls, email) should be handed off
+// to a goroutine or queue. A panicking handler is logged and skipped.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]EventHandler
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[string][]EventHandler)}
+}
+
+// Subscribe registers h for events of eventType, or for every event when
+// eventType is "*".
+func (b *EventBus) Subscribe(eventType string, h EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], h)
+}
+
+// Publish delivers event to its subscribers. A nil bus drops it.
+func (b *EventBus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+
+	b.mu.RLock()
+	handlers := append(append([]EventHandler(nil), b.handlers[event.Type]...), b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		deliver(ctx, h, event)
+	}
+}
+
+func deliver(ctx context.Context, h EventHandler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event handler for %s panicked: %v", event.Type, r)
+		}
+	}()
+	h(ctx, event)
+}
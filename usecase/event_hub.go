@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"task-manager-api/domain"
+	"time"
+)
+
+const (
+	// eventReplaySize is how many recent events the hub keeps for clients
+	// resuming with Last-Event-ID.
+	eventReplaySize = 1000
+	// eventStreamBuffer is how far a stream may fall behind before the hub
+	// drops it.
+	eventStreamBuffer = 64
+	maxStreamsPerUser = 5
+)
+
+// StreamEvent is a task event as sent to event streams. ID is
+// "<hub epoch>-<sequence>", so IDs from before a restart are recognised.
+type StreamEvent struct {
+	ID        string
+	Type      string
+	ProjectID int
+	Data      []byte // the Event as JSON
+
+	seq uint64
+}
+
+// EventHub fans task events out to event streams. Publishing never waits
+// for a stream: one that falls eventStreamBuffer events behind is dropped,
+// and its client reconnects and resumes from the replay buffer.
+type EventHub struct {
+	access *TaskAccess
+	epoch  string
+
+	mu      sync.Mutex
+	seq     uint64
+	replay  []StreamEvent // the last eventReplaySize events, oldest first
+	streams map[*EventStream]struct{}
+	perUser map[int]int
+	closed  bool
+}
+
+func NewEventHub(access *TaskAccess) *EventHub {
+	return &EventHub{
+		access:  access,
+		epoch:   strconv.FormatInt(time.Now().UnixMilli(), 36),
+		streams: make(map[*EventStream]struct{}),
+		perUser: make(map[int]int),
+	}
+}
+
+// EventStream is one client's subscription to the hub.
+type EventStream struct {
+	hub    *EventHub
+	userID int
+	events chan StreamEvent
+	done   chan struct{}
+}
+
+// Events delivers the events published after the stream opened, in order.
+func (s *EventStream) Events() <-chan StreamEvent {
+	return s.events
+}
+
+// Done is closed when the hub drops the stream, because it fell behind or
+// the hub was closed.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Visible reports whether the stream's user may see the event's task now.
+// The check is made here, on the stream's goroutine, not when publishing,
+// against the user's cached readable projects.
+func (s *EventStream) Visible(ctx context.Context, event StreamEvent) bool {
+	if event.ProjectID == 0 {
+		return true
+	}
+
+	projects, err := s.hub.access.projectsReadableBy(ctx, s.userID)
+	if err != nil {
+		log.Printf("event stream for user %d: %v", s.userID, err)
+		return false
+	}
+	return slices.Contains(projects, event.ProjectID)
+}
+
+// Close unsubscribes the stream.
+func (s *EventStream) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe opens a stream for userID. Given the ID of the last event the
+// client saw, it also returns the events since then, to send first. When
+// some of those have already left the replay buffer, or the ID is from
+// before a restart, complete is false and the client must reload instead.
+func (h *EventHub) Subscribe(userID int, lastEventID string) (stream *EventStream, missed []StreamEvent, complete bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.perUser[userID] >= maxStreamsPerUser {
+		return nil, nil, false, &domain.TooManyRequestsError{
+			Message: fmt.Sprintf("at most %d event streams may be open at once", maxStreamsPerUser),
+		}
+	}
+
+	stream = &EventStream{
+		hub:    h,
+		userID: userID,
+		events: make(chan StreamEvent, eventStreamBuffer),
+		done:   make(chan struct{}),
+	}
+	if h.closed {
+		close(stream.done)
+		return stream, nil, true, nil
+	}
+	h.streams[stream] = struct{}{}
+	h.perUser[userID]++
+
+	if lastEventID == "" {
+		return stream, nil, true, nil
+	}
+	missed, complete = h.since(lastEventID)
+	return stream, missed, complete, nil
+}
+
+// since returns the buffered events after lastEventID.
+func (h *EventHub) since(lastEventID string) ([]StreamEvent, bool) {
+	epoch, seqText, _ := strings.Cut(lastEventID, "-")
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if epoch != h.epoch || err != nil || seq > h.seq {
+		return nil, false
+	}
+	if seq == h.seq {
+		return nil, true
+	}
+	if len(h.replay) == 0 || h.replay[0].seq > seq+1 {
+		return nil, false
+	}
+
+	first := int(seq + 1 - h.replay[0].seq)
+	return append([]StreamEvent(nil), h.replay[first:]...), true
+}
+
+// Publish sends a task event to every stream. It is an EventHandler for
+// task.created, task.updated and task.deleted.
+func (h *EventHub) Publish(ctx context.Context, event Event) {
+	data, ok := event.Data.(TaskData)
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("event hub: %s on task %d: %v", event.Type, event.TaskID, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.seq++
+	streamEvent := StreamEvent{
+		ID:        fmt.Sprintf("%s-%d", h.epoch, h.seq),
+		Type:      event.Type,
+		ProjectID: data.Task.ProjectID,
+		Data:      payload,
+		seq:       h.seq,
+	}
+
+	h.replay = append(h.replay, streamEvent)
+	if len(h.replay) > eventReplaySize {
+		h.replay = h.replay[len(h.replay)-eventReplaySize:]
+	}
+
+	for stream := range h.streams {
+		select {
+		case stream.events <- streamEvent:
+		default:
+			log.Printf("event hub: dropping a stream for user %d that fell %d events behind", stream.userID, eventStreamBuffer)
+			h.remove(stream)
+		}
+	}
+}
+
+// Close ends every stream. Call it before shutting the server down, which
+// would otherwise wait for them.
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for stream := range h.streams {
+		h.remove(stream)
+	}
+}
+
+// remove unsubscribes a stream and closes its Done channel. The caller holds
+// h.mu.
+func (h *EventHub) remove(stream *EventStream) {
+	if _, ok := h.streams[stream]; !ok {
+		return
+	}
+	delete(h.streams, stream)
+	h.perUser[stream.userID]--
+	if h.perUser[stream.userID] == 0 {
+		delete(h.perUser, stream.userID)
+	}
+	close(stream.done)
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"testing"
+)
+
+func publishTaskEvent(hub *EventHub, eventType string, taskID int) {
+	hub.Publish(context.Background(), Event{
+		Type:   eventType,
+		TaskID: taskID,
+		Data:   TaskData{Task: dto.TaskResponseDTO{ID: taskID}},
+	})
+}
+
+func TestEventHubResumesFromLastEventID(t *testing.T) {
+	hub := NewEventHub(NewTaskAccess(nil, nil))
+	for id := 1; id <= 3; id++ {
+		publishTaskEvent(hub, EventTaskCreated, id)
+	}
+
+	first, _, _, err := hub.Subscribe(1, "")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer first.Close()
+	publishTaskEvent(hub, EventTaskUpdated, 1)
+	seen := <-first.Events()
+	if seen.Type != EventTaskUpdated {
+		t.Fatalf("got %s, want %s", seen.Type, EventTaskUpdated)
+	}
+
+	publishTaskEvent(hub, EventTaskDeleted, 2)
+	publishTaskEvent(hub, EventTaskDeleted, 3)
+
+	resumed, missed, complete, err := hub.Subscribe(1, seen.ID)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	defer resumed.Close()
+	if !complete || len(missed) != 2 {
+		t.Fatalf("got %d missed events (complete %v), want 2", len(missed), complete)
+	}
+	for _, event := range missed {
+		if event.Type != EventTaskDeleted || !strings.Contains(string(event.Data), `"task.deleted"`) {
+			t.Errorf("unexpected missed event %s: %s", event.ID, event.Data)
+		}
+	}
+
+	// An ID from another run of the server can't be resumed.
+	stale, missed, complete, err := hub.Subscribe(1, "zzz-2")
+	if err != nil {
+		t.Fatalf("stale resume: %v", err)
+	}
+	defer stale.Close()
+	if complete || len(missed) != 0 {
+		t.Errorf("stale ID: got %d missed events (complete %v), want a reset", len(missed), complete)
+	}
+}
+
+func TestEventHubForgetsEventsBeyondReplayBuffer(t *testing.T) {
+	hub := NewEventHub(NewTaskAccess(nil, nil))
+	publishTaskEvent(hub, EventTaskCreated, 1)
+	oldest := hub.replay[0].ID
+	for i := 0; i < eventReplaySize; i++ {
+		publishTaskEvent(hub, EventTaskUpdated, 1)
+	}
+
+	stream, missed, complete, err := hub.Subscribe(1, oldest)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer stream.Close()
+	if !complete || len(missed) != eventReplaySize {
+		t.Errorf("got %d missed events (complete %v), want %d", len(missed), complete, eventReplaySize)
+	}
+
+	publishTaskEvent(hub, EventTaskUpdated, 1)
+	gone, missed, complete, err := hub.Subscribe(1, oldest)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer gone.Close()
+	if complete || len(missed) != 0 {
+		t.Errorf("got %d missed events (complete %v), want a reset", len(missed), complete)
+	}
+}
+
+func TestEventHubDropsSlowStreams(t *testing.T) {
+	hub := NewEventHub(NewTaskAccess(nil, nil))
+	slow, _, _, err := hub.Subscribe(1, "")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer slow.Close()
+
+	for i := 0; i <= eventStreamBuffer; i++ {
+		publishTaskEvent(hub, EventTaskUpdated, 1)
+	}
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("stream that fell behind was not dropped")
+	}
+	if len(hub.streams) != 0 || hub.perUser[1] != 0 {
+		t.Errorf("dropped stream is still counted: %d streams, %d for user 1", len(hub.streams), hub.perUser[1])
+	}
+}
+
+func TestEventHubLimitsStreamsPerUser(t *testing.T) {
+	hub := NewEventHub(NewTaskAccess(nil, nil))
+	var streams []*EventStream
+	for i := 0; i < maxStreamsPerUser; i++ {
+		stream, _, _, err := hub.Subscribe(1, "")
+		if err != nil {
+			t.Fatalf("stream %d: %v", i+1, err)
+		}
+		streams = append(streams, stream)
+	}
+
+	_, _, _, err := hub.Subscribe(1, "")
+	var tooMany *domain.TooManyRequestsError
+	if !errors.As(err, &tooMany) {
+		t.Fatalf("got %v, want TooManyRequestsError", err)
+	}
+	if _, _, _, err := hub.Subscribe(2, ""); err != nil {
+		t.Errorf("another user's stream: %v", err)
+	}
+
+	streams[0].Close()
+	if _, _, _, err := hub.Subscribe(1, ""); err != nil {
+		t.Errorf("after closing a stream: %v", err)
+	}
+
+	hub.Close()
+	for _, stream := range streams {
+		select {
+		case <-stream.Done():
+		default:
+			t.Error("stream still open after the hub closed")
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+)
+
+const defaultLabelColor = "#6b7280"
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type LabelUsecase struct {
+	labels repository.LabelRepository
+	tasks  repository.TaskRepository
+	cache  Cache
+	access *TaskAccess
+}
+
+func NewLabelUsecase(labels repository.LabelRepository, tasks repository.TaskRepository, cache Cache, access *TaskAccess) *LabelUsecase {
+	return &LabelUsecase{labels: labels, tasks: tasks, cache: cache, access: access}
+}
+
+func (u *LabelUsecase) ListLabels(ctx context.Context, userID int) ([]dto.LabelResponseDTO, error) {
+	labels, err := u.labels.ListVisible(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.LabelResponseDTO, len(labels))
+	for i, label := range labels {
+		responses[i] = toLabelResponse(label)
+	}
+	return responses, nil
+}
+
+func (u *LabelUsecase) CreateLabel(ctx context.Context, userID int, req dto.LabelDTO) (dto.LabelResponseDTO, error) {
+	label, err := u.validate(userID, 0, req)
+	if err != nil {
+		return dto.LabelResponseDTO{}, err
+	}
+	label.UserID = userID
+
+	created, err := u.labels.Create(label)
+	if err != nil {
+		return dto.LabelResponseDTO{}, err
+	}
+	return toLabelResponse(created), nil
+}
+
+// UpdateLabel renames or recolors a label; every task carrying it shows the
+// change at once.
+func (u *LabelUsecase) UpdateLabel(ctx context.Context, userID, id int, req dto.LabelDTO) (dto.LabelResponseDTO, error) {
+	existing, err := u.ownedLabel(userID, id)
+	if err != nil {
+		return dto.LabelResponseDTO{}, err
+	}
+
+	label, err := u.validate(userID, id, req)
+	if err != nil {
+		return dto.LabelResponseDTO{}, err
+	}
+	label.ID = existing.ID
+	label.UserID = existing.UserID
+
+	updated, affected, err := u.labels.Update(label)
+	if err != nil {
+		return dto.LabelResponseDTO{}, err
+	}
+	invalidateTasks(u.cache, affected...)
+
+	return toLabelResponse(updated), nil
+}
+
+func (u *LabelUsecase) DeleteLabel(ctx context.Context, userID, id int) error {
+	if _, err := u.ownedLabel(userID, id); err != nil {
+		return err
+	}
+
+	affected, err := u.labels.Delete(id)
+	if err != nil {
+		return err
+	}
+	invalidateTasks(u.cache, affected...)
+	return nil
+}
+
+// MergeLabel moves every task labelled id onto req.Into and deletes id. The
+// user must own id; Into may be any label they can see.
+func (u *LabelUsecase) MergeLabel(ctx context.Context, userID, id int, req dto.MergeLabelDTO) (dto.LabelMergeResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.LabelMergeResponseDTO{}, err
+	}
+	if req.Into == id {
+		return dto.LabelMergeResponseDTO{}, &domain.ValidationError{Field: "into", Message: "cannot merge a label into itself"}
+	}
+
+	if _, err := u.ownedLabel(userID, id); err != nil {
+		return dto.LabelMergeResponseDTO{}, err
+	}
+	target, err := u.visibleLabel(userID, req.Into)
+	if err != nil {
+		return dto.LabelMergeResponseDTO{}, err
+	}
+
+	affected, err := u.labels.Merge(id, target.ID)
+	if err != nil {
+		return dto.LabelMergeResponseDTO{}, err
+	}
+	invalidateTasks(u.cache, affected...)
+
+	return dto.LabelMergeResponseDTO{
+		Label:        toLabelResponse(target),
+		TasksUpdated: len(affected),
+	}, nil
+}
+
+// AddTaskLabels applies labels the user can see to a task and returns the
+// task as it now is.
+func (u *LabelUsecase) AddTaskLabels(ctx context.Context, userID, taskID int, req dto.AddTaskLabelsDTO) (dto.TaskResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	if err := u.writableTask(ctx, taskID); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	for _, labelID := range req.LabelIDs {
+		if _, err := u.visibleLabel(userID, labelID); err != nil {
+			return dto.TaskResponseDTO{}, err
+		}
+	}
+
+	for _, labelID := range req.LabelIDs {
+		if err := u.labels.AddToTask(taskID, labelID); err != nil {
+			return dto.TaskResponseDTO{}, err
+		}
+	}
+	invalidateTasks(u.cache, taskID)
+
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	return toTaskResponse(task), nil
+}
+
+func (u *LabelUsecase) RemoveTaskLabel(ctx context.Context, userID, taskID, labelID int) error {
+	if err := u.writableTask(ctx, taskID); err != nil {
+		return err
+	}
+	if _, err := u.visibleLabel(userID, labelID); err != nil {
+		return err
+	}
+
+	if err := u.labels.RemoveFromTask(taskID, labelID); err != nil {
+		return err
+	}
+	invalidateTasks(u.cache, taskID)
+	return nil
+}
+
+// writableTask checks the caller may change the task's labels, which follows
+// the same rules as changing the task itself.
+func (u *LabelUsecase) writableTask(ctx context.Context, taskID int) error {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+	return u.access.canWriteTask(ctx, task)
+}
+
+func (u *LabelUsecase) visibleLabel(userID, id int) (domain.Label, error) {
+	label, err := u.labels.GetByID(id)
+	if err != nil {
+		return domain.Label{}, err
+	}
+	if label.UserID != userID && !label.Shared {
+		return domain.Label{}, &domain.NotFoundError{Resource: "Label", ID: id}
+	}
+	return label, nil
+}
+
+func (u *LabelUsecase) ownedLabel(userID, id int) (domain.Label, error) {
+	label, err := u.visibleLabel(userID, id)
+	if err != nil {
+		return domain.Label{}, err
+	}
+	if label.UserID != userID {
+		return domain.Label{}, &domain.ForbiddenError{Message: "only the owner can change a shared label"}
+	}
+	return label, nil
+}
+
+// validate checks req and turns it into a label. Names must be unique among
+// the user's own labels; exceptID is the label being updated.
+func (u *LabelUsecase) validate(userID, exceptID int, req dto.LabelDTO) (domain.Label, error) {
+	if err := validation.Validate(req); err != nil {
+		return domain.Label{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return domain.Label{}, &domain.ValidationError{Field: "name", Message: "is required"}
+	}
+	// Commas separate names in label filters.
+	if strings.Contains(name, ",") {
+		return domain.Label{}, &domain.ValidationError{Field: "name", Message: "must not contain commas"}
+	}
+
+	color := req.Color
+	if color == "" {
+		color = defaultLabelColor
+	}
+	if !labelColorPattern.MatchString(color) {
+		return domain.Label{}, &domain.ValidationError{Field: "color", Message: "must be a hex color such as #1e90ff"}
+	}
+
+	labels, err := u.labels.ListVisible(userID)
+	if err != nil {
+		return domain.Label{}, err
+	}
+	for _, label := range labels {
+		if label.UserID == userID && label.ID != exceptID && strings.EqualFold(label.Name, name) {
+			return domain.Label{}, &domain.ValidationError{
+				Field:   "name",
+				Message: "you already have a label with this name; merge the labels instead",
+			}
+		}
+	}
+
+	return domain.Label{Name: name, Color: strings.ToLower(color), Shared: req.Shared}, nil
+}
+
+func toLabelResponse(label domain.Label) dto.LabelResponseDTO {
+	return dto.LabelResponseDTO{
+		ID:        label.ID,
+		Name:      label.Name,
+		Color:     label.Color,
+		Shared:    label.Shared,
+		OwnerID:   label.UserID,
+		CreatedAt: label.CreatedAt,
+	}
+}
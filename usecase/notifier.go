@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/repository"
+	"time"
+)
+
+// ReminderNotice is what a Notifier delivers. ID identifies the reminder;
+// a notifier may see the same ID again when a delivery is retried, so it
+// should pass it on for the receiver to de-duplicate.
+type ReminderNotice struct {
+	ID         int        `json:"reminder_id"`
+	TaskID     int        `json:"task_id"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	UserID     int        `json:"user_id"`
+	Email      string     `json:"-"`
+	WebhookURL string     `json:"-"`
+}
+
+// Notifier delivers reminders over one channel. InAppNotifier,
+// WebhookNotifier and SMTPNotifier are the implementations.
+type Notifier interface {
+	Notify(ctx context.Context, notice ReminderNotice) error
+}
+
+// InAppNotifier stores the reminder as a notification the user reads
+// through the API. Repeated deliveries of one reminder store it once.
+type InAppNotifier struct {
+	notifications repository.NotificationRepository
+}
+
+func NewInAppNotifier(notifications repository.NotificationRepository) *InAppNotifier {
+	return &InAppNotifier{notifications: notifications}
+}
+
+func (n *InAppNotifier) Notify(ctx context.Context, notice ReminderNotice) error {
+	_, err := n.notifications.Create(domain.Notification{
+		UserID:     notice.UserID,
+		TaskID:     notice.TaskID,
+		ReminderID: notice.ID,
+		Message:    notice.Message,
+	})
+	return err
+}
+
+// WebhookNotifier POSTs the notice as JSON to the URL in the user's
+// settings. The Idempotency-Key header repeats across retries of one
+// reminder.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{client: newOutboundClient(timeout)}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notice ReminderNotice) error {
+	if notice.WebhookURL == "" {
+		return fmt.Errorf("no webhook URL in the user's notification settings")
+	}
+
+	body, err := json.Marshal(notice)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notice.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("reminder-%d", notice.ID))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier emails the user through an SMTP relay that accepts mail
+// without authentication, such as a local MTA. The Message-ID repeats
+// across retries of one reminder.
+type SMTPNotifier struct {
+	addr string
+	from string
+}
+
+func NewSMTPNotifier(addr, from string) *SMTPNotifier {
+	return &SMTPNotifier{addr: addr, from: from}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notice ReminderNotice) error {
+	if notice.Email == "" {
+		return fmt.Errorf("user %d has no email address", notice.UserID)
+	}
+
+	domainPart := n.from[strings.LastIndex(n.from, "@")+1:]
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", notice.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "Reminder: "+headerSafe(notice.Title)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <reminder-%d@%s>\r\n", notice.ID, domainPart)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(notice.Message, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	// net/smtp takes no context; run it aside so a hung relay can't hold
+	// the scheduler past ctx.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.addr, nil, n.from, []string{notice.Email}, []byte(msg.String()))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// headerSafe drops line breaks, which would start a new header.
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
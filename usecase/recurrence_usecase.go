@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/recurrence"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+	"time"
+)
+
+// maxPreviewOccurrences bounds PreviewOccurrences.
+const maxPreviewOccurrences = 100
+
+// RecurrenceUsecase turns tasks into recurring templates and creates a copy
+// of the template for each occurrence. Creation is idempotent: every
+// occurrence is recorded with the task made for it, so restarts and
+// overlapping runs never create one twice.
+type RecurrenceUsecase struct {
+	recurrences repository.RecurrenceRepository
+	tasks       repository.TaskRepository
+	cache       Cache
+	access      *TaskAccess
+	events      *EventBus
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewRecurrenceUsecase(recurrences repository.RecurrenceRepository, tasks repository.TaskRepository, cache Cache, access *TaskAccess, events *EventBus) *RecurrenceUsecase {
+	return &RecurrenceUsecase{
+		recurrences: recurrences,
+		tasks:       tasks,
+		cache:       cache,
+		access:      access,
+		events:      events,
+		stop:        make(chan struct{}),
+	}
+}
+
+func (u *RecurrenceUsecase) GetRecurrence(ctx context.Context, taskID int) (dto.RecurrenceDTO, error) {
+	if _, err := u.readableTask(ctx, taskID); err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+
+	rec, err := u.recurrences.Get(taskID)
+	if err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+	return toRecurrenceDTO(rec, time.Now()), nil
+}
+
+// SetRecurrence makes the task recurring, or changes how it recurs, and
+// creates any occurrence that is already due.
+func (u *RecurrenceUsecase) SetRecurrence(ctx context.Context, taskID int, req dto.SetRecurrenceDTO) (dto.RecurrenceDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+	if err := u.access.canWriteTask(ctx, task); err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+	if task.OccurrenceOf != 0 {
+		return dto.RecurrenceDTO{}, &domain.ValidationError{
+			Field:   "rule",
+			Message: fmt.Sprintf("task %d is an occurrence of task %d; change that task's recurrence instead", task.ID, task.OccurrenceOf),
+		}
+	}
+
+	rule, err := recurrence.Parse(req.Rule)
+	if err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+
+	startsAt := time.Now().Truncate(time.Minute)
+	switch {
+	case req.StartsAt != nil:
+		startsAt = *req.StartsAt
+	case task.DueDate != nil:
+		startsAt = *task.DueDate
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = domain.RecurrenceOnSchedule
+	}
+
+	rec, err := u.recurrences.Set(domain.Recurrence{
+		TaskID:   taskID,
+		Rule:     rule.String(),
+		Timezone: loc.String(),
+		StartsAt: startsAt,
+		Mode:     mode,
+	})
+	if err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+
+	if _, err := u.generate(rec, time.Now()); err != nil {
+		log.Printf("recurrence: task %d: %v", taskID, err)
+	} else if rec, err = u.recurrences.Get(taskID); err != nil {
+		return dto.RecurrenceDTO{}, err
+	}
+	return toRecurrenceDTO(rec, time.Now()), nil
+}
+
+// DeleteRecurrence stops the task recurring. Occurrences already created
+// are kept.
+func (u *RecurrenceUsecase) DeleteRecurrence(ctx context.Context, taskID int) error {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return err
+	}
+	if err := u.access.canWriteTask(ctx, task); err != nil {
+		return err
+	}
+	return u.recurrences.Delete(taskID)
+}
+
+// PreviewOccurrences lists the next count occurrences that haven't been
+// created yet.
+func (u *RecurrenceUsecase) PreviewOccurrences(ctx context.Context, taskID, count int) (dto.OccurrencesDTO, error) {
+	if count < 1 || count > maxPreviewOccurrences {
+		return dto.OccurrencesDTO{}, &domain.ValidationError{Field: "count", Message: fmt.Sprintf("must be between 1 and %d", maxPreviewOccurrences)}
+	}
+
+	if _, err := u.readableTask(ctx, taskID); err != nil {
+		return dto.OccurrencesDTO{}, err
+	}
+
+	rec, err := u.recurrences.Get(taskID)
+	if err != nil {
+		return dto.OccurrencesDTO{}, err
+	}
+
+	schedule, err := scheduleFor(rec)
+	if err != nil {
+		return dto.OccurrencesDTO{}, err
+	}
+
+	occurrences := schedule.After(latest(createdThrough(rec), time.Now()), count)
+	if occurrences == nil {
+		occurrences = []time.Time{}
+	}
+	return dto.OccurrencesDTO{TaskID: taskID, Timezone: rec.Timezone, Occurrences: occurrences}, nil
+}
+
+// Generate creates every occurrence that is due and returns how many tasks
+// it created. Each template is handled on its own; one that fails is
+// logged and retried on the next run.
+func (u *RecurrenceUsecase) Generate() (int, error) {
+	recurrences, err := u.recurrences.List()
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	now := time.Now()
+	for _, rec := range recurrences {
+		ok, err := u.generate(rec, now)
+		if err != nil {
+			log.Printf("recurrence: task %d: %v", rec.TaskID, err)
+			continue
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// generate creates the template's next occurrence if it is due. A
+// "schedule" template is due once an occurrence's time has passed; if
+// several have, only the latest is created. A "completion" template is due
+// as soon as the task for its previous occurrence is done.
+func (u *RecurrenceUsecase) generate(rec domain.Recurrence, now time.Time) (bool, error) {
+	schedule, err := scheduleFor(rec)
+	if err != nil {
+		return false, err
+	}
+
+	var occursAt time.Time
+	var due bool
+
+	switch rec.Mode {
+	case domain.RecurrenceOnCompletion:
+		if !rec.LastOpen {
+			occursAt, due = schedule.Next(latest(createdThrough(rec), now))
+		}
+	default:
+		occursAt, due = schedule.Latest(createdThrough(rec), now)
+	}
+	if !due {
+		return false, nil
+	}
+
+	template, err := u.tasks.GetByID(rec.TaskID)
+	if err != nil {
+		return false, err
+	}
+
+	dueDate := occursAt.UTC()
+	task, created, err := u.recurrences.Materialize(rec.TaskID, occursAt, domain.Task{
+		Title:           template.Title,
+		Description:     template.Description,
+		Status:          domain.StatusPending,
+		Priority:        template.Priority,
+		DueDate:         &dueDate,
+		ProjectID:       template.ProjectID,
+		ParentID:        template.ParentID,
+		AutoComplete:    template.AutoComplete,
+		EstimateMinutes: template.EstimateMinutes,
+	})
+	if err != nil || !created {
+		return false, err
+	}
+
+	invalidateTasks(u.cache, task.ID, task.ParentID)
+	// Nobody is signed in to the scheduler, so the event has no actor.
+	publishTask(context.Background(), u.events, EventTaskCreated, task)
+	log.Printf("recurrence: created task %d for the %s occurrence of task %d", task.ID, occursAt.Format(time.RFC3339), rec.TaskID)
+	return true, nil
+}
+
+// Start runs Generate every interval until Close is called.
+func (u *RecurrenceUsecase) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-ticker.C:
+				if _, err := u.Generate(); err != nil {
+					log.Printf("recurrence scheduler: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (u *RecurrenceUsecase) Close() {
+	u.stopOnce.Do(func() { close(u.stop) })
+}
+
+func (u *RecurrenceUsecase) readableTask(ctx context.Context, taskID int) (domain.Task, error) {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.access.canReadTask(ctx, task.ID, task.ProjectID); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+func scheduleFor(rec domain.Recurrence) (recurrence.Schedule, error) {
+	rule, err := recurrence.Parse(rec.Rule)
+	if err != nil {
+		return recurrence.Schedule{}, err
+	}
+	loc, err := loadTimezone(rec.Timezone)
+	if err != nil {
+		return recurrence.Schedule{}, err
+	}
+	return recurrence.Schedule{Rule: rule, Start: rec.StartsAt.In(loc)}, nil
+}
+
+// loadTimezone accepts IANA names such as "Europe/Berlin". "Local" is
+// refused: it would depend on the server.
+func loadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, &domain.ValidationError{Field: "timezone", Message: fmt.Sprintf("%q is not an IANA time zone such as Europe/Berlin", name)}
+	}
+	return loc, nil
+}
+
+// createdThrough is the time up to which occurrences have been created:
+// the last one, or just before the first if none has been.
+func createdThrough(rec domain.Recurrence) time.Time {
+	if rec.Last != nil {
+		return *rec.Last
+	}
+	return rec.StartsAt.Add(-time.Nanosecond)
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func toRecurrenceDTO(rec domain.Recurrence, now time.Time) dto.RecurrenceDTO {
+	result := dto.RecurrenceDTO{
+		TaskID:     rec.TaskID,
+		Rule:       rec.Rule,
+		Timezone:   rec.Timezone,
+		StartsAt:   rec.StartsAt,
+		Mode:       rec.Mode,
+		Last:       rec.Last,
+		LastTaskID: rec.LastTaskID,
+	}
+
+	if schedule, err := scheduleFor(rec); err == nil {
+		if next, ok := schedule.Next(latest(createdThrough(rec), now)); ok {
+			result.Next = &next
+		}
+		result.StartsAt = rec.StartsAt.In(schedule.Start.Location())
+	}
+	return result
+}
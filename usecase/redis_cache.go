@@ -0,0 +1,361 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"task-manager-api/domain"
+	"task-manager-api/resp"
+	"time"
+)
+
+const (
+	redisCommandTimeout = time.Second
+	// redisRetryAfter is how long the cache stays bypassed after the backend
+	// stops answering, so requests don't each wait on a dead connection.
+	redisRetryAfter = 5 * time.Second
+	redisScanCount  = "500"
+)
+
+// redisValue is what a RedisCache stores under each key. ExpiresAt is kept
+// in the payload because the key itself outlives it by the stale window.
+type redisValue struct {
+	Value     interface{}
+	NotFound  *domain.NotFoundError
+	ExpiresAt time.Time
+}
+
+// RedisCache is a Cache kept in a Redis-compatible server so that every API
+// replica sees the same entries. Keys are namespaced by prefix so several
+// environments can share one server. Each tag is a set, under prefix+"tag:",
+// of the keys carrying it.
+//
+// When the server is unreachable the cache degrades to a no-op: reads miss,
+// writes are dropped and GetOrLoad calls its loader directly.
+type RedisCache struct {
+	client *resp.Client
+	prefix string
+	config CacheConfig
+
+	loadMu sync.Mutex
+	loads  map[string]*cacheLoad
+
+	mu        sync.Mutex // guards downUntil and the counters
+	downUntil time.Time
+
+	hits      uint64
+	misses    uint64
+	errors    uint64
+	staleHits uint64
+	loadCount uint64
+}
+
+func NewRedisCache(client *resp.Client, prefix string, config CacheConfig) *RedisCache {
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+		config: config,
+		loads:  make(map[string]*cacheLoad),
+	}
+}
+
+func (c *RedisCache) Get(key string) (interface{}, bool) {
+	stored, found := c.fetch(key)
+	if !found || stored.NotFound != nil || !time.Now().Before(stored.ExpiresAt) {
+		c.count(&c.misses)
+		return nil, false
+	}
+
+	c.count(&c.hits)
+	return stored.Value, true
+}
+
+func (c *RedisCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.config.TTL)
+}
+
+func (c *RedisCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.store(key, redisValue{Value: value, ExpiresAt: time.Now().Add(ttl)}, ttl+c.config.StaleWhileRevalidate, nil)
+}
+
+func (c *RedisCache) SetWithTags(key string, value interface{}, tags ...string) {
+	ttl := c.config.TTL
+	c.store(key, redisValue{Value: value, ExpiresAt: time.Now().Add(ttl)}, ttl+c.config.StaleWhileRevalidate, tags)
+}
+
+func (c *RedisCache) Delete(key string) {
+	c.loadMu.Lock()
+	if load, loading := c.loads[key]; loading {
+		load.invalidated = true
+	}
+	c.loadMu.Unlock()
+
+	c.do("DEL", c.prefix+key)
+}
+
+func (c *RedisCache) InvalidateTag(tag string) int {
+	c.loadMu.Lock()
+	for _, load := range c.loads {
+		if slices.Contains(load.tags, tag) {
+			load.invalidated = true
+		}
+	}
+	c.loadMu.Unlock()
+
+	reply, ok := c.do("SMEMBERS", c.tagKey(tag))
+	members, isArray := reply.([]interface{})
+	if !ok || !isArray {
+		return 0
+	}
+
+	args := []string{"DEL"}
+	for _, member := range members {
+		if key, isBulk := member.([]byte); isBulk {
+			args = append(args, c.prefix+string(key))
+		}
+	}
+
+	removed := int64(0)
+	if len(args) > 1 {
+		reply, _ = c.do(args...)
+		removed, _ = reply.(int64)
+	}
+	c.do("DEL", c.tagKey(tag))
+
+	return int(removed)
+}
+
+// Clear deletes every key under the prefix, leaving other environments alone.
+func (c *RedisCache) Clear() {
+	c.loadMu.Lock()
+	for _, load := range c.loads {
+		load.invalidated = true
+	}
+	c.loadMu.Unlock()
+
+	c.scan(func(keys []string) {
+		c.do(append([]string{"DEL"}, keys...)...)
+	})
+}
+
+// GetOrLoad behaves like CacheService.GetOrLoad. Loads are coalesced per
+// process; replicas may each load the same key once.
+func (c *RedisCache) GetOrLoad(ctx context.Context, key string, loader CacheLoader, tags ...string) (interface{}, error) {
+	stored, found := c.fetch(key)
+	if found {
+		if time.Now().Before(stored.ExpiresAt) {
+			c.count(&c.hits)
+			if stored.NotFound != nil {
+				return nil, stored.NotFound
+			}
+			return stored.Value, nil
+		}
+
+		if stored.NotFound == nil {
+			c.count(&c.hits)
+			c.count(&c.staleHits)
+			c.loadMu.Lock()
+			c.startLoad(ctx, key, loader, tags)
+			c.loadMu.Unlock()
+			return stored.Value, nil
+		}
+	}
+
+	c.count(&c.misses)
+	c.loadMu.Lock()
+	load := c.startLoad(ctx, key, loader, tags)
+	c.loadMu.Unlock()
+
+	select {
+	case <-load.done:
+		return load.value, load.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startLoad returns the in-flight load for key, starting one if there is none.
+// c.loadMu must be held.
+func (c *RedisCache) startLoad(ctx context.Context, key string, loader CacheLoader, tags []string) *cacheLoad {
+	if load, loading := c.loads[key]; loading {
+		return load
+	}
+
+	load := &cacheLoad{tags: tags, done: make(chan struct{})}
+	c.loads[key] = load
+	c.count(&c.loadCount)
+
+	go func() {
+		load.value, load.err = runLoader(context.WithoutCancel(ctx), key, loader)
+
+		// Storing while holding c.loadMu orders the write against Delete,
+		// which marks the load under c.loadMu before it sends DEL.
+		c.loadMu.Lock()
+		if !load.invalidated {
+			var notFound *domain.NotFoundError
+			switch {
+			case load.err == nil:
+				c.SetWithTags(key, load.value, tags...)
+			case errors.As(load.err, &notFound) && c.config.NegativeTTL > 0:
+				c.store(key, redisValue{NotFound: notFound, ExpiresAt: time.Now().Add(c.config.NegativeTTL)}, c.config.NegativeTTL, tags)
+			}
+		}
+		delete(c.loads, key)
+		c.loadMu.Unlock()
+
+		close(load.done)
+	}()
+
+	return load
+}
+
+// GetStats reports this process's counters. TotalEntries is the size of the
+// whole database, tag sets and other prefixes included: counting only this
+// prefix would mean scanning every key on each call.
+func (c *RedisCache) GetStats() CacheStats {
+	reply, _ := c.do("DBSIZE")
+	entries, _ := reply.(int64)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Backend:      "redis",
+		Available:    !time.Now().Before(c.downUntil),
+		TotalEntries: int(entries),
+		TTL:          int(c.config.TTL.Seconds()),
+		Hits:         c.hits,
+		Misses:       c.misses,
+		StaleHits:    c.staleHits,
+		Loads:        c.loadCount,
+		Errors:       c.errors,
+	}
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+func (c *RedisCache) fetch(key string) (redisValue, bool) {
+	reply, ok := c.do("GET", c.prefix+key)
+	data, isBulk := reply.([]byte)
+	if !ok || !isBulk {
+		return redisValue{}, false
+	}
+
+	var stored redisValue
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); err != nil {
+		log.Printf("cache: could not decode %q: %v", key, err)
+		c.count(&c.errors)
+		return redisValue{}, false
+	}
+	return stored, true
+}
+
+// store writes stored under key, kept for keep, and adds key to each tag set.
+// Tags are written first so an entry is never visible without them.
+func (c *RedisCache) store(key string, stored redisValue, keep time.Duration, tags []string) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(stored); err != nil {
+		log.Printf("cache: could not encode %q: %v", key, err)
+		c.count(&c.errors)
+		return
+	}
+
+	millis := keep.Milliseconds()
+	if millis <= 0 {
+		return
+	}
+
+	for _, tag := range tags {
+		c.do("SADD", c.tagKey(tag), key)
+		c.do("PEXPIRE", c.tagKey(tag), strconv.FormatInt(c.tagKeep().Milliseconds(), 10))
+	}
+	c.do("SET", c.prefix+key, buf.String(), "PX", strconv.FormatInt(millis, 10))
+}
+
+func (c *RedisCache) tagKey(tag string) string {
+	return c.prefix + "tag:" + tag
+}
+
+// tagKeep outlives any tagged entry, so a tag set never expires while one of
+// its keys is still readable.
+func (c *RedisCache) tagKeep() time.Duration {
+	return max(c.config.TTL+c.config.StaleWhileRevalidate, c.config.NegativeTTL)
+}
+
+// scan calls fn with each batch of keys under the prefix.
+func (c *RedisCache) scan(fn func(keys []string)) {
+	cursor := "0"
+	for {
+		reply, ok := c.do("SCAN", cursor, "MATCH", c.prefix+"*", "COUNT", redisScanCount)
+		parts, isArray := reply.([]interface{})
+		if !ok || !isArray || len(parts) != 2 {
+			return
+		}
+
+		next, _ := parts[0].([]byte)
+		items, _ := parts[1].([]interface{})
+
+		var keys []string
+		for _, item := range items {
+			if key, isBulk := item.([]byte); isBulk {
+				keys = append(keys, string(key))
+			}
+		}
+		if len(keys) > 0 {
+			fn(keys)
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return
+		}
+	}
+}
+
+// do runs a command unless the backend is known to be down. A connection
+// failure marks it down for redisRetryAfter.
+func (c *RedisCache) do(args ...string) (interface{}, bool) {
+	c.mu.Lock()
+	down := time.Now().Before(c.downUntil)
+	c.mu.Unlock()
+	if down {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisCommandTimeout)
+	defer cancel()
+
+	reply, err := c.client.Do(ctx, args...)
+	if err == nil {
+		return reply, true
+	}
+
+	var replyErr *resp.Error
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errors++
+	if errors.As(err, &replyErr) {
+		log.Printf("cache: %s failed: %v", args[0], err)
+		return nil, false
+	}
+
+	if !time.Now().Before(c.downUntil) {
+		log.Printf("cache: backend unreachable, bypassing cache for %v: %v", redisRetryAfter, err)
+	}
+	c.downUntil = time.Now().Add(redisRetryAfter)
+	return nil, false
+}
+
+func (c *RedisCache) count(counter *uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*counter++
+}
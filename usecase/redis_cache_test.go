@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/resp"
+	"testing"
+	"time"
+)
+
+// respServer is an in-process stand-in for Redis implementing the handful of
+// commands RedisCache sends.
+type respServer struct {
+	listener net.Listener
+
+	mu   sync.Mutex
+	data map[string]respItem
+	sets map[string]map[string]bool
+}
+
+type respItem struct {
+	value     string
+	expiresAt time.Time
+}
+
+func startRESPServer(t *testing.T) *respServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	server := &respServer{
+		listener: listener,
+		data:     make(map[string]respItem),
+		sets:     make(map[string]map[string]bool),
+	}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *respServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *respServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *respServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		io.WriteString(conn, s.exec(args))
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || line[0] != '*' {
+		return nil, fmt.Errorf("bad command header %q", line)
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (s *respServer) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, item := range s.data {
+		if !item.expiresAt.IsZero() && !now.Before(item.expiresAt) {
+			delete(s.data, key)
+		}
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		item, ok := s.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(item.value)
+	case "SET":
+		item := respItem{value: args[2]}
+		if len(args) == 5 && strings.EqualFold(args[3], "PX") {
+			millis, _ := strconv.Atoi(args[4])
+			item.expiresAt = now.Add(time.Duration(millis) * time.Millisecond)
+		}
+		s.data[args[1]] = item
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.data[key]; ok {
+				delete(s.data, key)
+				deleted++
+			}
+			if _, ok := s.sets[key]; ok {
+				delete(s.sets, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "SADD":
+		if s.sets[args[1]] == nil {
+			s.sets[args[1]] = make(map[string]bool)
+		}
+		for _, member := range args[2:] {
+			s.sets[args[1]][member] = true
+		}
+		return fmt.Sprintf(":%d\r\n", len(args)-2)
+	case "SMEMBERS":
+		reply := fmt.Sprintf("*%d\r\n", len(s.sets[args[1]]))
+		for member := range s.sets[args[1]] {
+			reply += bulk(member)
+		}
+		return reply
+	case "PEXPIRE":
+		// Set expiry is not modelled; tests never outlive a tag set.
+		return ":1\r\n"
+	case "DBSIZE":
+		return fmt.Sprintf(":%d\r\n", len(s.keys()))
+	case "SCAN":
+		// Everything is returned in one batch, with cursor 0 to end the scan.
+		var keys []string
+		for _, key := range s.keys() {
+			if matched, _ := path.Match(args[3], key); matched {
+				keys = append(keys, key)
+			}
+		}
+		reply := "*2\r\n" + bulk("0") + fmt.Sprintf("*%d\r\n", len(keys))
+		for _, key := range keys {
+			reply += bulk(key)
+		}
+		return reply
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+// keys lists string and set keys. s.mu must be held.
+func (s *respServer) keys() []string {
+	var keys []string
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	for key := range s.sets {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func newTestRedisCache(addr, prefix string) *RedisCache {
+	client := resp.NewClient(addr, resp.Options{DialTimeout: 200 * time.Millisecond})
+	return NewRedisCache(client, prefix, CacheConfig{
+		TTL:                  time.Minute,
+		StaleWhileRevalidate: time.Minute,
+		NegativeTTL:          time.Minute,
+	})
+}
+
+func TestRedisCacheRoundTripsDTOs(t *testing.T) {
+	server := startRESPServer(t)
+	cache := newTestRedisCache(server.addr(), "test:")
+	defer cache.Close()
+
+	tasks := []dto.TaskResponseDTO{{
+		ID: 1, Title: "Write tests", Status: domain.StatusPending,
+		Labels: []dto.TaskLabelDTO{{ID: 7, Name: "backend", Color: "#1e90ff"}},
+	}}
+	cache.Set("all_tasks", tasks)
+
+	cached, found := cache.Get("all_tasks")
+	if !found {
+		t.Fatal("expected a hit after Set")
+	}
+	got, ok := cached.([]dto.TaskResponseDTO)
+	if !ok || !reflect.DeepEqual(got, tasks) {
+		t.Fatalf("got %#v, want %#v", cached, tasks)
+	}
+
+	server.mu.Lock()
+	keys := server.keys()
+	server.mu.Unlock()
+	if len(keys) != 1 || keys[0] != "test:all_tasks" {
+		t.Fatalf("stored keys %v, want [test:all_tasks]", keys)
+	}
+
+	stats := cache.GetStats()
+	if stats.TotalEntries != 1 || stats.Hits != 1 {
+		t.Fatalf("stats report %d entries and %d hits, want 1 and 1", stats.TotalEntries, stats.Hits)
+	}
+}
+
+func TestRedisCachePrefixesIsolateEnvironments(t *testing.T) {
+	server := startRESPServer(t)
+	staging := newTestRedisCache(server.addr(), "app:staging:")
+	production := newTestRedisCache(server.addr(), "app:production:")
+
+	staging.Set("task_1", dto.TaskResponseDTO{ID: 1})
+	production.Set("task_1", dto.TaskResponseDTO{ID: 2})
+
+	staging.Clear()
+
+	if _, found := staging.Get("task_1"); found {
+		t.Fatal("staging entry survived Clear")
+	}
+	cached, found := production.Get("task_1")
+	if !found || cached.(dto.TaskResponseDTO).ID != 2 {
+		t.Fatalf("production entry = %v, %v; want task 2", cached, found)
+	}
+}
+
+func TestRedisCacheHonoursTTL(t *testing.T) {
+	server := startRESPServer(t)
+	cache := newTestRedisCache(server.addr(), "test:")
+
+	cache.SetWithTTL("short", "value", 20*time.Millisecond)
+	time.Sleep(40 * time.Millisecond)
+
+	if _, found := cache.Get("short"); found {
+		t.Fatal("expected the entry to have expired")
+	}
+}
+
+func TestRedisCacheGetOrLoadCachesNotFound(t *testing.T) {
+	server := startRESPServer(t)
+	cache := newTestRedisCache(server.addr(), "test:")
+
+	calls := 0
+	loader := func(ctx context.Context) (interface{}, error) {
+		calls++
+		return nil, &domain.NotFoundError{Resource: "Task", ID: 7}
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetOrLoad(context.Background(), "task_7", loader)
+		var notFound *domain.NotFoundError
+		if !errors.As(err, &notFound) || notFound.ID != 7 {
+			t.Fatalf("attempt %d: err = %v, want NotFoundError for 7", i, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("loader called %d times, want 1", calls)
+	}
+}
+
+func TestRedisCacheDegradesWhenUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	cache := newTestRedisCache(addr, "test:")
+	cache.Set("task_1", dto.TaskResponseDTO{ID: 1})
+
+	if _, found := cache.Get("task_1"); found {
+		t.Fatal("expected a miss with no backend")
+	}
+
+	value, err := cache.GetOrLoad(context.Background(), "task_1", func(ctx context.Context) (interface{}, error) {
+		return dto.TaskResponseDTO{ID: 1}, nil
+	})
+	if err != nil || value.(dto.TaskResponseDTO).ID != 1 {
+		t.Fatalf("GetOrLoad = %v, %v; want the loaded task", value, err)
+	}
+
+	if stats := cache.GetStats(); stats.Available {
+		t.Fatal("expected stats to report the backend unavailable")
+	}
+}
+
+func TestRedisCacheInvalidateTag(t *testing.T) {
+	server := startRESPServer(t)
+	cache := newTestRedisCache(server.addr(), "test:")
+
+	cache.SetWithTags("all_tasks", []dto.TaskResponseDTO{{ID: 1}, {ID: 2}}, "tasks")
+	cache.SetWithTags("task_1", dto.TaskResponseDTO{ID: 1}, "task:1")
+	cache.SetWithTags("task_2", dto.TaskResponseDTO{ID: 2}, "task:2")
+
+	if removed := cache.InvalidateTag("task:1"); removed != 1 {
+		t.Fatalf("InvalidateTag removed %d entries, want 1", removed)
+	}
+	cache.InvalidateTag("tasks")
+
+	for key, want := range map[string]bool{"all_tasks": false, "task_1": false, "task_2": true} {
+		if _, found := cache.Get(key); found != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, found, want)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+	"time"
+)
+
+const (
+	// reminderBatch is how many due reminders one instance claims at a time.
+	reminderBatch           = 10
+	reminderDeliveryTimeout = 10 * time.Second
+	// reminderLease is how long a claim lasts. It outlives the slowest
+	// batch, reminderBatch deliveries that each time out, so a live
+	// instance never loses a claim it is still working through.
+	reminderLease = 5 * time.Minute
+	// maxReminderAttempts is how many failed deliveries mark a reminder
+	// failed. Retries back off 1, 2, 4 and 8 minutes.
+	maxReminderAttempts = 5
+)
+
+// ReminderUsecase manages task reminders and delivers them. Pending
+// reminders live in the database, so they survive restarts; each run
+// claims due reminders under a lease, so with several API instances every
+// reminder is delivered by exactly one of them.
+type ReminderUsecase struct {
+	reminders     repository.ReminderRepository
+	notifications repository.NotificationRepository
+	tasks         repository.TaskRepository
+	users         repository.UserRepository
+	access        *TaskAccess
+	notifiers     map[string]Notifier // by channel
+
+	owner    string // identifies this instance's claims
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewReminderUsecase(reminders repository.ReminderRepository, notifications repository.NotificationRepository, tasks repository.TaskRepository, users repository.UserRepository, access *TaskAccess, notifiers map[string]Notifier) *ReminderUsecase {
+	return &ReminderUsecase{
+		reminders:     reminders,
+		notifications: notifications,
+		tasks:         tasks,
+		users:         users,
+		access:        access,
+		notifiers:     notifiers,
+		owner:         instanceOwner(),
+		stop:          make(chan struct{}),
+	}
+}
+
+// CreateReminder reminds userID about a task they can see.
+func (u *ReminderUsecase) CreateReminder(ctx context.Context, userID, taskID int, req dto.CreateReminderDTO) (dto.ReminderDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.ReminderDTO{}, err
+	}
+	if (req.RemindAt == nil) == (req.OffsetMinutes == nil) {
+		return dto.ReminderDTO{}, &domain.ValidationError{Field: "remind_at", Message: "give either remind_at or offset_minutes"}
+	}
+	if req.RemindAt != nil && !req.RemindAt.After(time.Now()) {
+		return dto.ReminderDTO{}, &domain.ValidationError{Field: "remind_at", Message: "must be in the future"}
+	}
+
+	if _, err := u.readableTask(ctx, taskID); err != nil {
+		return dto.ReminderDTO{}, err
+	}
+
+	channel := req.Channel
+	if channel == "" {
+		channel = domain.ChannelInApp
+	}
+	if u.notifiers[channel] == nil {
+		return dto.ReminderDTO{}, &domain.ValidationError{Field: "channel", Message: fmt.Sprintf("the %s channel is not available", channel)}
+	}
+	if channel == domain.ChannelWebhook {
+		settings, err := u.notifications.GetSettings(userID)
+		if err != nil {
+			return dto.ReminderDTO{}, err
+		}
+		if settings.WebhookURL == "" {
+			return dto.ReminderDTO{}, &domain.ValidationError{Field: "channel", Message: "set webhook_url in your notification settings first"}
+		}
+	}
+
+	reminder, err := u.reminders.Create(domain.Reminder{
+		TaskID:        taskID,
+		UserID:        userID,
+		RemindAt:      req.RemindAt,
+		OffsetMinutes: req.OffsetMinutes,
+		Channel:       channel,
+	})
+	if err != nil {
+		return dto.ReminderDTO{}, err
+	}
+	return toReminderDTO(reminder), nil
+}
+
+// ListReminders returns the caller's own reminders on a task.
+func (u *ReminderUsecase) ListReminders(ctx context.Context, userID, taskID int) ([]dto.ReminderDTO, error) {
+	if _, err := u.readableTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	reminders, err := u.reminders.ListByTask(taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.ReminderDTO{}
+	for _, reminder := range reminders {
+		result = append(result, toReminderDTO(reminder))
+	}
+	return result, nil
+}
+
+func (u *ReminderUsecase) DeleteReminder(ctx context.Context, userID, taskID, reminderID int) error {
+	reminder, err := u.reminders.GetByID(reminderID)
+	if err != nil {
+		return err
+	}
+	if reminder.TaskID != taskID || reminder.UserID != userID {
+		return &domain.NotFoundError{Resource: "Reminder", ID: reminderID}
+	}
+	return u.reminders.Delete(reminderID)
+}
+
+func (u *ReminderUsecase) ListNotifications(ctx context.Context, userID int, unreadOnly bool) ([]dto.NotificationDTO, error) {
+	notifications, err := u.notifications.ListByUser(userID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.NotificationDTO{}
+	for _, notification := range notifications {
+		result = append(result, toNotificationDTO(notification))
+	}
+	return result, nil
+}
+
+func (u *ReminderUsecase) MarkNotificationRead(ctx context.Context, userID, notificationID int) (dto.NotificationDTO, error) {
+	notification, err := u.notifications.MarkRead(notificationID, userID)
+	if err != nil {
+		return dto.NotificationDTO{}, err
+	}
+	return toNotificationDTO(notification), nil
+}
+
+func (u *ReminderUsecase) GetSettings(ctx context.Context, userID int) (dto.NotificationSettingsDTO, error) {
+	settings, err := u.notifications.GetSettings(userID)
+	if err != nil {
+		return dto.NotificationSettingsDTO{}, err
+	}
+	return toNotificationSettingsDTO(settings), nil
+}
+
+func (u *ReminderUsecase) SaveSettings(ctx context.Context, userID int, req dto.NotificationSettingsDTO) (dto.NotificationSettingsDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.NotificationSettingsDTO{}, err
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return dto.NotificationSettingsDTO{}, err
+	}
+
+	if (req.QuietStart == "") != (req.QuietEnd == "") {
+		return dto.NotificationSettingsDTO{}, &domain.ValidationError{Field: "quiet_end", Message: "give both quiet_start and quiet_end, or neither"}
+	}
+	if req.QuietStart != "" && req.QuietStart == req.QuietEnd {
+		return dto.NotificationSettingsDTO{}, &domain.ValidationError{Field: "quiet_end", Message: "must differ from quiet_start"}
+	}
+
+	if req.WebhookURL != "" {
+		if err := checkWebhookURL("webhook_url", req.WebhookURL); err != nil {
+			return dto.NotificationSettingsDTO{}, err
+		}
+	}
+
+	settings, err := u.notifications.SaveSettings(domain.NotificationSettings{
+		UserID:     userID,
+		Timezone:   loc.String(),
+		QuietStart: req.QuietStart,
+		QuietEnd:   req.QuietEnd,
+		WebhookURL: req.WebhookURL,
+	})
+	if err != nil {
+		return dto.NotificationSettingsDTO{}, err
+	}
+	return toNotificationSettingsDTO(settings), nil
+}
+
+// Dispatch delivers every reminder due at now that no other instance has
+// claimed and returns how many it sent.
+func (u *ReminderUsecase) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	sent := 0
+	for {
+		reminders, err := u.reminders.Claim(u.owner, now, reminderLease, reminderBatch)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, reminder := range reminders {
+			if u.deliver(ctx, reminder, now) {
+				sent++
+			}
+		}
+
+		if len(reminders) < reminderBatch {
+			return sent, nil
+		}
+	}
+}
+
+// deliver handles one claimed reminder. Reminders for tasks that are
+// completed or that the user can no longer see are skipped; those due in
+// the user's quiet hours wait until the quiet hours end.
+func (u *ReminderUsecase) deliver(ctx context.Context, reminder domain.Reminder, now time.Time) bool {
+	task, err := u.tasks.GetByID(reminder.TaskID)
+	if _, ok := err.(*domain.NotFoundError); ok {
+		u.finish(reminder, domain.ReminderSkipped, "the task was deleted")
+		return false
+	}
+	if err != nil {
+		u.retry(reminder, now, err)
+		return false
+	}
+	if task.Status == domain.StatusCompleted {
+		u.finish(reminder, domain.ReminderSkipped, "the task is completed")
+		return false
+	}
+
+	canRead, err := u.access.userCanRead(reminder.UserID, task.ProjectID)
+	if err != nil {
+		u.retry(reminder, now, err)
+		return false
+	}
+	if !canRead {
+		u.finish(reminder, domain.ReminderSkipped, "the user can no longer see the task")
+		return false
+	}
+
+	settings, err := u.notifications.GetSettings(reminder.UserID)
+	if err != nil {
+		u.retry(reminder, now, err)
+		return false
+	}
+	loc, err := loadTimezone(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	if until, quiet := quietUntil(settings, loc, now); quiet {
+		if err := u.reminders.Postpone(reminder.ID, u.owner, until, ""); err != nil {
+			log.Printf("reminder %d: %v", reminder.ID, err)
+		}
+		return false
+	}
+
+	user, err := u.users.GetByID(reminder.UserID)
+	if err != nil {
+		u.retry(reminder, now, err)
+		return false
+	}
+
+	notifier := u.notifiers[reminder.Channel]
+	if notifier == nil {
+		u.finish(reminder, domain.ReminderFailed, fmt.Sprintf("the %s channel is not available", reminder.Channel))
+		return false
+	}
+
+	deliverCtx, cancel := context.WithTimeout(ctx, reminderDeliveryTimeout)
+	defer cancel()
+
+	err = notifier.Notify(deliverCtx, ReminderNotice{
+		ID:         reminder.ID,
+		TaskID:     task.ID,
+		Title:      task.Title,
+		Message:    reminderMessage(task, loc),
+		DueDate:    task.DueDate,
+		UserID:     user.ID,
+		Email:      user.Email,
+		WebhookURL: settings.WebhookURL,
+	})
+	if err != nil {
+		u.retry(reminder, now, err)
+		return false
+	}
+
+	u.finish(reminder, domain.ReminderSent, "")
+	return true
+}
+
+// retry schedules another attempt with exponential backoff, or gives up
+// after maxReminderAttempts.
+func (u *ReminderUsecase) retry(reminder domain.Reminder, now time.Time, cause error) {
+	log.Printf("reminder %d: delivery failed: %v", reminder.ID, cause)
+
+	attempts := reminder.Attempts + 1
+	if attempts >= maxReminderAttempts {
+		u.finish(reminder, domain.ReminderFailed, cause.Error())
+		return
+	}
+
+	until := now.Add(time.Minute << (attempts - 1))
+	if err := u.reminders.Postpone(reminder.ID, u.owner, until, cause.Error()); err != nil {
+		log.Printf("reminder %d: %v", reminder.ID, err)
+	}
+}
+
+func (u *ReminderUsecase) finish(reminder domain.Reminder, status, reason string) {
+	if err := u.reminders.Finish(reminder.ID, u.owner, status, reason); err != nil {
+		log.Printf("reminder %d: %v", reminder.ID, err)
+	}
+}
+
+// Start runs Dispatch every interval until Close is called.
+func (u *ReminderUsecase) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case now := <-ticker.C:
+				if _, err := u.Dispatch(context.Background(), now); err != nil {
+					log.Printf("reminder scheduler: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (u *ReminderUsecase) Close() {
+	u.stopOnce.Do(func() { close(u.stop) })
+}
+
+func (u *ReminderUsecase) readableTask(ctx context.Context, taskID int) (domain.Task, error) {
+	task, err := u.tasks.GetByID(taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.access.canReadTask(ctx, task.ID, task.ProjectID); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+// quietUntil reports whether now falls in the user's quiet hours and, if
+// so, when they end. Quiet hours may span midnight, such as 22:00-07:00.
+func quietUntil(settings domain.NotificationSettings, loc *time.Location, now time.Time) (time.Time, bool) {
+	start, okStart := clockMinutes(settings.QuietStart)
+	end, okEnd := clockMinutes(settings.QuietEnd)
+	if !okStart || !okEnd || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	quiet := start <= minute && minute < end
+	if start > end {
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = time.Date(local.Year(), local.Month(), local.Day()+1, end/60, end%60, 0, 0, loc)
+	}
+	return until, true
+}
+
+// clockMinutes parses "HH:MM" into minutes after midnight.
+func clockMinutes(clock string) (int, bool) {
+	if len(clock) != 5 || clock[2] != ':' {
+		return 0, false
+	}
+	hours, errHours := strconv.Atoi(clock[:2])
+	minutes, errMinutes := strconv.Atoi(clock[3:])
+	if errHours != nil || errMinutes != nil || hours > 23 || minutes > 59 {
+		return 0, false
+	}
+	return hours*60 + minutes, true
+}
+
+// reminderMessage describes the task, with its due date in the user's
+// time zone.
+func reminderMessage(task domain.Task, loc *time.Location) string {
+	if task.DueDate == nil {
+		return fmt.Sprintf("Reminder: %q", task.Title)
+	}
+	return fmt.Sprintf("Reminder: %q is due %s", task.Title, task.DueDate.In(loc).Format("Mon 2 Jan 2006 15:04 MST"))
+}
+
+func toReminderDTO(reminder domain.Reminder) dto.ReminderDTO {
+	return dto.ReminderDTO{
+		ID:            reminder.ID,
+		TaskID:        reminder.TaskID,
+		RemindAt:      reminder.RemindAt,
+		OffsetMinutes: reminder.OffsetMinutes,
+		Channel:       reminder.Channel,
+		Status:        reminder.Status,
+		FireAt:        reminder.FireAt,
+		DeliverAfter:  reminder.DeliverAfter,
+		Attempts:      reminder.Attempts,
+		LastError:     reminder.LastError,
+		SentAt:        reminder.SentAt,
+		CreatedAt:     reminder.CreatedAt,
+	}
+}
+
+func toNotificationDTO(notification domain.Notification) dto.NotificationDTO {
+	return dto.NotificationDTO{
+		ID:         notification.ID,
+		TaskID:     notification.TaskID,
+		ReminderID: notification.ReminderID,
+		Message:    notification.Message,
+		CreatedAt:  notification.CreatedAt,
+		ReadAt:     notification.ReadAt,
+	}
+}
+
+func toNotificationSettingsDTO(settings domain.NotificationSettings) dto.NotificationSettingsDTO {
+	return dto.NotificationSettingsDTO{
+		Timezone:   settings.Timezone,
+		QuietStart: settings.QuietStart,
+		QuietEnd:   settings.QuietEnd,
+		WebhookURL: settings.WebhookURL,
+	}
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"task-manager-api/domain"
 	"time"
 )
 
@@ -20,6 +22,12 @@ func RetryWithBackoff(ctx context.Context, operation func() error) error {
 
 		lastErr = err
 
+		// A missing row will still be missing after a backoff.
+		var notFound *domain.NotFoundError
+		if errors.As(err, &notFound) {
+			return err
+		}
+
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/taskquery"
+	"task-manager-api/validation"
+	"time"
+)
+
+// viewColumns are the task fields a view may show, in TaskResponseDTO's JSON
+// names. defaultViewColumns is used when a view lists none.
+var (
+	viewColumns        = []string{"id", "title", "description", "status", "priority", "version", "updated_at", "due_date"}
+	defaultViewColumns = []string{"id", "title", "status", "priority", "due_date"}
+)
+
+type SavedViewUsecase struct {
+	views  repository.SavedViewRepository
+	tasks  repository.TaskRepository
+	cache  Cache
+	access *TaskAccess
+}
+
+func NewSavedViewUsecase(views repository.SavedViewRepository, tasks repository.TaskRepository, cache Cache, access *TaskAccess) *SavedViewUsecase {
+	return &SavedViewUsecase{views: views, tasks: tasks, cache: cache, access: access}
+}
+
+func viewTag(id int) string {
+	return fmt.Sprintf("view:%d", id)
+}
+
+func (u *SavedViewUsecase) CreateView(ctx context.Context, userID int, req dto.SavedViewDTO) (dto.SavedViewResponseDTO, error) {
+	if err := u.validate(userID, 0, req); err != nil {
+		return dto.SavedViewResponseDTO{}, err
+	}
+
+	view, err := u.views.Create(domain.SavedView{
+		UserID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		Query:   req.Query,
+		Sort:    req.Sort,
+		Columns: req.Columns,
+		Shared:  req.Shared,
+	})
+	if err != nil {
+		return dto.SavedViewResponseDTO{}, err
+	}
+
+	return u.toResponse(ctx, view)
+}
+
+// ListViews returns the user's views and those shared by others, each with
+// its current task count.
+func (u *SavedViewUsecase) ListViews(ctx context.Context, userID int) ([]dto.SavedViewResponseDTO, error) {
+	views, err := u.views.ListVisible(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SavedViewResponseDTO, 0, len(views))
+	for _, view := range views {
+		response, err := u.toResponse(ctx, view)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (u *SavedViewUsecase) GetView(ctx context.Context, userID, id int) (dto.SavedViewResponseDTO, error) {
+	view, err := u.visibleView(userID, id)
+	if err != nil {
+		return dto.SavedViewResponseDTO{}, err
+	}
+	return u.toResponse(ctx, view)
+}
+
+func (u *SavedViewUsecase) UpdateView(ctx context.Context, userID, id int, req dto.SavedViewDTO) (dto.SavedViewResponseDTO, error) {
+	view, err := u.ownedView(userID, id)
+	if err != nil {
+		return dto.SavedViewResponseDTO{}, err
+	}
+	if err := u.validate(userID, id, req); err != nil {
+		return dto.SavedViewResponseDTO{}, err
+	}
+
+	view.Name = strings.TrimSpace(req.Name)
+	view.Query = req.Query
+	view.Sort = req.Sort
+	view.Columns = req.Columns
+	view.Shared = req.Shared
+
+	view, err = u.views.Update(view)
+	if err != nil {
+		return dto.SavedViewResponseDTO{}, err
+	}
+	u.cache.InvalidateTag(viewTag(id))
+
+	return u.toResponse(ctx, view)
+}
+
+func (u *SavedViewUsecase) DeleteView(ctx context.Context, userID, id int) error {
+	if _, err := u.ownedView(userID, id); err != nil {
+		return err
+	}
+	if err := u.views.Delete(id); err != nil {
+		return err
+	}
+	u.cache.InvalidateTag(viewTag(id))
+	return nil
+}
+
+// ViewTasks runs a view. Results are cached per view until a task changes or
+// the view is edited, and narrowed to the tasks the caller can see, since a
+// shared view's viewers may belong to different workspaces.
+func (u *SavedViewUsecase) ViewTasks(ctx context.Context, userID, id int) (dto.SavedViewTasksResponseDTO, error) {
+	view, err := u.visibleView(userID, id)
+	if err != nil {
+		return dto.SavedViewTasksResponseDTO{}, err
+	}
+
+	tasks, err := u.viewResults(ctx, view)
+	if err != nil {
+		return dto.SavedViewTasksResponseDTO{}, err
+	}
+
+	return dto.SavedViewTasksResponseDTO{
+		View:  toSavedViewResponse(view, len(tasks)),
+		Tasks: tasks,
+	}, nil
+}
+
+func (u *SavedViewUsecase) viewResults(ctx context.Context, view domain.SavedView) ([]dto.TaskResponseDTO, error) {
+	// Results are cached per caller, since a query may say assignee:me.
+	env := queryEnv(ctx)
+	key := fmt.Sprintf("view_%d_tasks_%d", view.ID, env.UserID)
+	cached, err := u.cache.GetOrLoad(ctx, key, func(ctx context.Context) (interface{}, error) {
+		filter, err := taskquery.New(view.Query, env)
+		if err != nil {
+			return nil, err
+		}
+		order, err := taskquery.ParseSort(view.Sort)
+		if err != nil {
+			return nil, err
+		}
+
+		var tasks []domain.Task
+		err = RetryWithBackoff(ctx, func() error {
+			var repoErr error
+			tasks, repoErr = u.tasks.Find(filter)
+			return repoErr
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		order.Apply(tasks)
+
+		responses := make([]dto.TaskResponseDTO, len(tasks))
+		for i, task := range tasks {
+			responses[i] = toTaskResponse(task)
+		}
+		return responses, nil
+	}, tagTasks, viewTag(view.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty list comes back from the Redis cache as nil; visibleTasks
+	// always returns a non-nil slice.
+	tasks, _ := cached.([]dto.TaskResponseDTO)
+	return u.access.visibleTasks(ctx, tasks)
+}
+
+func (u *SavedViewUsecase) toResponse(ctx context.Context, view domain.SavedView) (dto.SavedViewResponseDTO, error) {
+	tasks, err := u.viewResults(ctx, view)
+	if err != nil {
+		return dto.SavedViewResponseDTO{}, err
+	}
+	return toSavedViewResponse(view, len(tasks)), nil
+}
+
+func toSavedViewResponse(view domain.SavedView, count int) dto.SavedViewResponseDTO {
+	columns := view.Columns
+	if len(columns) == 0 {
+		columns = defaultViewColumns
+	}
+
+	return dto.SavedViewResponseDTO{
+		ID:        view.ID,
+		Name:      view.Name,
+		Query:     view.Query,
+		Sort:      view.Sort,
+		Columns:   columns,
+		Shared:    view.Shared,
+		OwnerID:   view.UserID,
+		Count:     count,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: view.UpdatedAt,
+	}
+}
+
+// visibleView returns a view the user owns or that is shared. Other users'
+// private views are reported as missing rather than forbidden.
+func (u *SavedViewUsecase) visibleView(userID, id int) (domain.SavedView, error) {
+	view, err := u.views.GetByID(id)
+	if err != nil {
+		return domain.SavedView{}, err
+	}
+	if view.UserID != userID && !view.Shared {
+		return domain.SavedView{}, &domain.NotFoundError{Resource: "View", ID: id}
+	}
+	return view, nil
+}
+
+func (u *SavedViewUsecase) ownedView(userID, id int) (domain.SavedView, error) {
+	view, err := u.visibleView(userID, id)
+	if err != nil {
+		return domain.SavedView{}, err
+	}
+	if view.UserID != userID {
+		return domain.SavedView{}, &domain.ForbiddenError{Message: "only the owner can change a shared view"}
+	}
+	return view, nil
+}
+
+// validate checks the request and that the filter, sort and columns would
+// run, so a broken view can't be saved. exceptID is the view being updated.
+func (u *SavedViewUsecase) validate(userID, exceptID int, req dto.SavedViewDTO) error {
+	if err := validation.Validate(req); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return &domain.ValidationError{Field: "name", Message: "is required"}
+	}
+
+	if _, err := taskquery.New(req.Query, taskquery.Env{Now: time.Now(), UserID: userID}); err != nil {
+		return err
+	}
+	if _, err := taskquery.ParseSort(req.Sort); err != nil {
+		return err
+	}
+	for _, column := range req.Columns {
+		if !slices.Contains(viewColumns, column) {
+			return &domain.ValidationError{
+				Field:   "columns",
+				Message: "unknown column \"" + column + "\"; expected one of " + strings.Join(viewColumns, ", "),
+			}
+		}
+	}
+
+	views, err := u.views.ListVisible(userID)
+	if err != nil {
+		return err
+	}
+	for _, view := range views {
+		if view.UserID == userID && view.ID != exceptID && strings.EqualFold(view.Name, strings.TrimSpace(req.Name)) {
+			return &domain.ValidationError{Field: "name", Message: "a view with this name already exists"}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/taskquery"
+)
+
+// TaskAccess decides who may see and change tasks. Tasks outside any project
+// stay open to every caller, as they were before workspaces existed. Tasks in
+// a project follow the project's workspace membership: viewers can read
+// them, members and above can change them, and nobody can change them while
+// the project is archived.
+type TaskAccess struct {
+	workspaces repository.WorkspaceRepository
+	cache      Cache
+}
+
+func NewTaskAccess(workspaces repository.WorkspaceRepository, cache Cache) *TaskAccess {
+	return &TaskAccess{workspaces: workspaces, cache: cache}
+}
+
+// authorizeProject returns the project if the caller holds at least role in
+// its workspace. Non-members get NotFound, so project IDs don't leak.
+func (a *TaskAccess) authorizeProject(ctx context.Context, projectID int, role string) (domain.Project, error) {
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return domain.Project{}, &domain.UnauthorizedError{Message: "sign in to access project tasks"}
+	}
+
+	project, err := a.workspaces.GetProject(projectID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	have, err := a.workspaces.MemberRole(project.WorkspaceID, user.ID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	if have == "" {
+		return domain.Project{}, &domain.NotFoundError{Resource: "Project", ID: projectID}
+	}
+	if !domain.RoleAtLeast(have, role) {
+		return domain.Project{}, &domain.ForbiddenError{Message: "this requires the " + role + " role in the project's workspace"}
+	}
+	return project, nil
+}
+
+// canWriteProject checks that the caller may put tasks into, or change tasks
+// in, the project. Project 0 means no project.
+func (a *TaskAccess) canWriteProject(ctx context.Context, projectID int) error {
+	if projectID == 0 {
+		return nil
+	}
+
+	project, err := a.authorizeProject(ctx, projectID, domain.RoleMember)
+	if err != nil {
+		return err
+	}
+	if project.Archived() {
+		return &domain.ValidationError{Field: "project_id", Message: "project is archived; unarchive it to change its tasks"}
+	}
+	return nil
+}
+
+// canReadTask reports tasks the caller can't see as missing.
+func (a *TaskAccess) canReadTask(ctx context.Context, taskID, projectID int) error {
+	if projectID == 0 {
+		return nil
+	}
+
+	_, err := a.authorizeProject(ctx, projectID, domain.RoleViewer)
+	switch err.(type) {
+	case nil:
+		return nil
+	case *domain.NotFoundError, *domain.UnauthorizedError:
+		return &domain.NotFoundError{Resource: "Task", ID: taskID}
+	default:
+		return err
+	}
+}
+
+// canWriteTask checks the caller may change a task as it is now.
+func (a *TaskAccess) canWriteTask(ctx context.Context, task domain.Task) error {
+	if err := a.canReadTask(ctx, task.ID, task.ProjectID); err != nil {
+		return err
+	}
+	return a.canWriteProject(ctx, task.ProjectID)
+}
+
+// userCanRead reports whether userID could see a task in the project, so
+// nobody is assigned work they can't open.
+func (a *TaskAccess) userCanRead(userID, projectID int) (bool, error) {
+	if projectID == 0 {
+		return true, nil
+	}
+
+	project, err := a.workspaces.GetProject(projectID)
+	if err != nil {
+		return false, err
+	}
+	role, err := a.workspaces.MemberRole(project.WorkspaceID, userID)
+	if err != nil {
+		return false, err
+	}
+	return domain.RoleAtLeast(role, domain.RoleViewer), nil
+}
+
+// readableProjects lists the projects whose tasks the caller can see. Every
+// task list needs it, so it is cached until WorkspaceUsecase changes the
+// caller's memberships or their workspaces' projects.
+func (a *TaskAccess) readableProjects(ctx context.Context) ([]int, error) {
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	return a.projectsReadableBy(ctx, user.ID)
+}
+
+// projectsReadableBy is readableProjects for a user other than the caller.
+func (a *TaskAccess) projectsReadableBy(ctx context.Context, userID int) ([]int, error) {
+	cacheKey := fmt.Sprintf("readable_projects_%d", userID)
+	cached, err := a.cache.GetOrLoad(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		projects, err := a.workspaces.ProjectIDsForUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		if projects == nil {
+			projects = []int{}
+		}
+		return projects, nil
+	}, memberTag(userID))
+	if err != nil {
+		return nil, err
+	}
+	return cached.([]int), nil
+}
+
+// scope is a query condition selecting the tasks the caller can see.
+func (a *TaskAccess) scope(ctx context.Context) (taskquery.Condition, error) {
+	projects, err := a.readableProjects(ctx)
+	if err != nil {
+		return taskquery.Condition{}, err
+	}
+	return taskquery.ProjectScope(projects, true), nil
+}
+
+// scopeFilter compiles scope on its own.
+func (a *TaskAccess) scopeFilter(ctx context.Context) (*taskquery.Filter, error) {
+	condition, err := a.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return taskquery.Compile(&taskquery.Query{Conditions: []taskquery.Condition{condition}}, taskquery.Env{})
+}
+
+// visibleTasks drops the tasks the caller can't see from a list.
+func (a *TaskAccess) visibleTasks(ctx context.Context, tasks []dto.TaskResponseDTO) ([]dto.TaskResponseDTO, error) {
+	projects, err := a.readableProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make(map[int]bool, len(projects))
+	for _, id := range projects {
+		readable[id] = true
+	}
+
+	visible := []dto.TaskResponseDTO{}
+	for _, task := range tasks {
+		if task.ProjectID == 0 || readable[task.ProjectID] {
+			visible = append(visible, task)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/validation"
+)
+
+// bulkEvents is the event published for each kind of bulk operation.
+var bulkEvents = map[string]string{
+	domain.OperationCreate: EventTaskCreated,
+	domain.OperationUpdate: EventTaskUpdated,
+	domain.OperationDelete: EventTaskDeleted,
+}
+
+// BulkTaskResult is the outcome of one operation in a bulk request. Err is
+// nil when the operation was applied.
+type BulkTaskResult struct {
+	Op   string
+	ID   int
+	Task *dto.TaskResponseDTO
+	Err  error
+}
+
+type BulkTaskOutcome struct {
+	Mode      string
+	Committed bool
+	Results   []BulkTaskResult
+}
+
+// BulkTasks applies a list of create/update/delete operations in a single
+// repository transaction. In atomic mode any failure (including validation)
+// leaves the database untouched; in partial mode every valid operation that
+// succeeds is committed. The task cache is invalidated once afterwards.
+func (u *TaskUsecase) BulkTasks(ctx context.Context, req dto.BulkTaskRequestDTO) (BulkTaskOutcome, error) {
+	if req.Mode == "" {
+		req.Mode = dto.BulkModeAtomic
+	}
+	atomic := req.Mode == dto.BulkModeAtomic
+
+	itemErrs, err := splitBulkValidation(validation.Validate(req), len(req.Operations))
+	if err != nil {
+		return BulkTaskOutcome{}, err
+	}
+
+	outcome := BulkTaskOutcome{
+		Mode:    req.Mode,
+		Results: make([]BulkTaskResult, len(req.Operations)),
+	}
+
+	var ops []domain.TaskOperation
+	var opIndex []int // position in req.Operations of each entry in ops
+	firstInvalid := -1
+
+	for i, op := range req.Operations {
+		outcome.Results[i] = BulkTaskResult{Op: op.Op, ID: op.ID}
+
+		if itemErrs[i] == nil {
+			itemErrs[i] = checkBulkOperation(i, op)
+		}
+		var operation domain.TaskOperation
+		if itemErrs[i] == nil {
+			operation = toTaskOperation(op)
+			itemErrs[i] = u.authorizeOperation(ctx, &operation, op.Task)
+		}
+		if itemErrs[i] != nil {
+			outcome.Results[i].Err = itemErrs[i]
+			if firstInvalid < 0 {
+				firstInvalid = i
+			}
+			continue
+		}
+
+		ops = append(ops, operation)
+		opIndex = append(opIndex, i)
+	}
+
+	// Dropping a failed placement in partial mode changes the tree the rest
+	// were checked against, so check again until nothing more fails.
+	for len(ops) > 0 {
+		failed, err := u.checkBatchPlacement(ctx, ops)
+		if err != nil {
+			return BulkTaskOutcome{}, err
+		}
+		if len(failed) == 0 {
+			break
+		}
+
+		var keptOps []domain.TaskOperation
+		var keptIndex []int
+		for j, i := range opIndex {
+			if failed[j] == nil {
+				keptOps = append(keptOps, ops[j])
+				keptIndex = append(keptIndex, i)
+				continue
+			}
+			outcome.Results[i].Err = failed[j]
+			if firstInvalid < 0 || i < firstInvalid {
+				firstInvalid = i
+			}
+		}
+		ops, opIndex = keptOps, keptIndex
+		if atomic {
+			break
+		}
+	}
+
+	if len(ops) == 0 {
+		return outcome, nil
+	}
+
+	if atomic && firstInvalid >= 0 {
+		for _, i := range opIndex {
+			outcome.Results[i].Err = &domain.BatchAbortedError{FailedIndex: firstInvalid}
+		}
+		return outcome, nil
+	}
+
+	var repoResults []domain.TaskOperationResult
+
+	// ApplyBatch only returns an error when the transaction itself failed and
+	// was rolled back, so retrying cannot apply an operation twice.
+	err = RetryWithBackoff(ctx, func() error {
+		var repoErr error
+		repoResults, repoErr = u.repo.ApplyBatch(ops, atomic)
+		return repoErr
+	})
+	if err != nil {
+		return BulkTaskOutcome{}, err
+	}
+
+	var touched, parents []int
+	for j, res := range repoResults {
+		i := opIndex[j]
+
+		if res.Err != nil {
+			if aborted, ok := res.Err.(*domain.BatchAbortedError); ok {
+				res.Err = &domain.BatchAbortedError{FailedIndex: opIndex[aborted.FailedIndex]}
+			}
+			outcome.Results[i].Err = res.Err
+			continue
+		}
+
+		outcome.Committed = true
+		outcome.Results[i].ID = res.Task.ID
+		if ops[j].Kind != domain.OperationDelete {
+			response := toTaskResponse(res.Task)
+			outcome.Results[i].Task = &response
+		}
+		if ops[j].Kind != domain.OperationCreate {
+			touched = append(touched, res.Task.ID)
+		}
+		if res.Task.ParentID != 0 {
+			parents = append(parents, res.Task.ParentID)
+		}
+		touched = append(touched, res.Task.Blocks...)
+	}
+
+	if outcome.Committed {
+		u.invalidateTasks(append(touched, parents...)...)
+		for j, res := range repoResults {
+			if res.Err == nil {
+				u.publishTask(ctx, bulkEvents[ops[j].Kind], res.Task)
+			}
+		}
+		for _, parentID := range parents {
+			finishParents(ctx, u.repo, u.cache, u.events, parentID)
+		}
+	}
+
+	return outcome, nil
+}
+
+// splitBulkValidation separates errors on individual operations, which are
+// reported per item, from errors on the request itself, which are returned.
+func splitBulkValidation(err error, count int) ([]error, error) {
+	itemErrs := make([]error, count)
+	if err == nil {
+		return itemErrs, nil
+	}
+
+	validationErrs, ok := err.(*domain.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+
+	requestErrs := &domain.ValidationErrors{}
+	perItem := make([]*domain.ValidationErrors, count)
+
+	for _, fieldErr := range validationErrs.Errors {
+		index, ok := operationIndex(fieldErr.Field)
+		if !ok || index >= count {
+			requestErrs.Errors = append(requestErrs.Errors, fieldErr)
+			continue
+		}
+
+		if perItem[index] == nil {
+			perItem[index] = &domain.ValidationErrors{}
+		}
+		perItem[index].Errors = append(perItem[index].Errors, fieldErr)
+	}
+
+	if len(requestErrs.Errors) > 0 {
+		return nil, requestErrs
+	}
+
+	for i, errs := range perItem {
+		if errs != nil {
+			itemErrs[i] = errs
+		}
+	}
+	return itemErrs, nil
+}
+
+// operationIndex extracts 2 from a field path such as "operations[2].task.title".
+func operationIndex(field string) (int, bool) {
+	rest, found := strings.CutPrefix(field, "operations[")
+	if !found {
+		return 0, false
+	}
+	end := strings.Index(rest, "]")
+	if end < 0 {
+		return 0, false
+	}
+	index, err := strconv.Atoi(rest[:end])
+	return index, err == nil
+}
+
+func checkBulkOperation(index int, op dto.BulkTaskOperationDTO) error {
+	errs := &domain.ValidationErrors{}
+	prefix := fmt.Sprintf("operations[%d]", index)
+
+	if op.Op != domain.OperationCreate && op.ID == 0 {
+		errs.Add(prefix+".id", "is required for "+op.Op)
+	}
+	if op.Op == domain.OperationCreate && op.ID != 0 {
+		errs.Add(prefix+".id", "must not be set for create")
+	}
+	if op.Op != domain.OperationDelete && op.Task == nil {
+		errs.Add(prefix+".task", "is required for "+op.Op)
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// authorizeOperation applies the task access and subtask rules to one
+// operation, whose fields came from fields. An update without a project_id
+// or parent_id keeps the task where it is, one without auto_complete or
+// estimate_minutes keeps those values, and a task with subtasks can't be
+// deleted in bulk.
+func (u *TaskUsecase) authorizeOperation(ctx context.Context, op *domain.TaskOperation, fields *dto.BulkTaskDTO) error {
+	if op.Kind == domain.OperationCreate {
+		return u.placeTask(ctx, &op.Task, domain.Task{})
+	}
+
+	existing, err := u.repo.GetByID(op.Task.ID)
+	if err != nil {
+		return err
+	}
+	if err := u.access.canWriteTask(ctx, existing); err != nil {
+		return err
+	}
+
+	if op.Kind == domain.OperationDelete {
+		if existing.Subtasks > 0 {
+			return &domain.ValidationError{Field: "id", Message: "task has subtasks; delete it with DELETE /tasks/{id}?cascade=delete or cascade=promote"}
+		}
+		op.Task = existing
+		return nil
+	}
+
+	if op.Task.ProjectID == 0 {
+		op.Task.ProjectID = existing.ProjectID
+	}
+	if op.Task.ParentID == 0 {
+		op.Task.ParentID = existing.ParentID
+	}
+	if fields.AutoComplete == nil {
+		op.Task.AutoComplete = existing.AutoComplete
+	}
+	if fields.EstimateMinutes == nil {
+		op.Task.EstimateMinutes = existing.EstimateMinutes
+	}
+	if op.Task.Status == domain.StatusCompleted && existing.Status != domain.StatusCompleted {
+		if err := checkUnblocked(existing); err != nil {
+			return err
+		}
+	}
+	return u.placeTask(ctx, &op.Task, existing)
+}
+
+func toTaskOperation(op dto.BulkTaskOperationDTO) domain.TaskOperation {
+	task := domain.Task{ID: op.ID}
+	if op.Task != nil {
+		task.Title = op.Task.Title
+		task.Description = op.Task.Description
+		task.Status = op.Task.Status
+		task.Priority = op.Task.Priority
+		task.DueDate = op.Task.DueDate
+		task.ProjectID = op.Task.ProjectID
+		task.ParentID = op.Task.ParentID
+		if op.Task.AutoComplete != nil {
+			task.AutoComplete = *op.Task.AutoComplete
+		}
+		if op.Task.EstimateMinutes != nil {
+			task.EstimateMinutes = *op.Task.EstimateMinutes
+		}
+	}
+	return domain.TaskOperation{Kind: op.Op, Task: task}
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"task-manager-api/dto"
+	"task-manager-api/search"
+	"task-manager-api/validation"
+)
+
+// SearchTasks runs a full-text query (see package search for the syntax) and
+// returns one page of ranked, highlighted results. Results are not cached:
+// queries rarely repeat and the index answers them directly.
+func (u *TaskUsecase) SearchTasks(ctx context.Context, req dto.TaskSearchQueryDTO) (dto.TaskSearchResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.TaskSearchResponseDTO{}, err
+	}
+
+	query, err := search.Parse(req.Q)
+	if err != nil {
+		return dto.TaskSearchResponseDTO{}, err
+	}
+
+	scope, err := u.access.scopeFilter(ctx)
+	if err != nil {
+		return dto.TaskSearchResponseDTO{}, err
+	}
+
+	hits, total, err := u.repo.Search(query, scope, req.Limit, req.Offset)
+	if err != nil {
+		return dto.TaskSearchResponseDTO{}, err
+	}
+
+	results := make([]dto.TaskSearchResultDTO, len(hits))
+	for i, hit := range hits {
+		results[i] = dto.TaskSearchResultDTO{
+			Task:           toTaskResponse(hit.Task),
+			Score:          hit.Score,
+			TitleHighlight: search.MarkHTML(hit.TitleHighlight),
+			Snippet:        search.MarkHTML(hit.Snippet),
+		}
+	}
+
+	return dto.TaskSearchResponseDTO{
+		Query:   req.Q,
+		Total:   total,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+		Results: results,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"slices"
+	"task-manager-api/domain"
+)
+
+// topologicalOrder orders nodes so that every task comes after the tasks
+// blocking it. Ties go to the lowest ID, so the order is stable. edges must
+// form a DAG over nodes.
+func topologicalOrder(nodes []int, edges []domain.Dependency) []int {
+	blockers := make(map[int]int, len(nodes))
+	dependents := make(map[int][]int)
+	for _, edge := range edges {
+		blockers[edge.TaskID]++
+		dependents[edge.BlockedByID] = append(dependents[edge.BlockedByID], edge.TaskID)
+	}
+
+	var ready []int
+	for _, id := range nodes {
+		if blockers[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	order := make([]int, 0, len(nodes))
+	for len(ready) > 0 {
+		slices.Sort(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+
+		for _, dependent := range dependents[id] {
+			blockers[dependent]--
+			if blockers[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	return order
+}
+
+// criticalPath finds the chain of dependencies with the largest total
+// weight, given the nodes in topological order. The chain starts with its
+// first blocker. Among equally heavy chains the one ending at the earliest
+// node in order wins.
+func criticalPath(order []int, edges []domain.Dependency, weight map[int]int) ([]int, int) {
+	blockers := make(map[int][]int)
+	for _, edge := range edges {
+		blockers[edge.TaskID] = append(blockers[edge.TaskID], edge.BlockedByID)
+	}
+
+	// total[id] is the weight of the heaviest chain ending at id, and
+	// previous[id] the blocker it comes through.
+	total := make(map[int]int, len(order))
+	previous := make(map[int]int, len(order))
+	end := 0
+	for _, id := range order {
+		best, through := 0, 0
+		for _, blocker := range blockers[id] {
+			if total[blocker] > best || through == 0 {
+				best, through = total[blocker], blocker
+			}
+		}
+		total[id] = best + weight[id]
+		previous[id] = through
+
+		if end == 0 || total[id] > total[end] {
+			end = id
+		}
+	}
+	if end == 0 {
+		return []int{}, 0
+	}
+
+	path := []int{end}
+	for id := previous[end]; id != 0; id = previous[id] {
+		path = append(path, id)
+	}
+	slices.Reverse(path)
+	return path, total[end]
+}
@@ -0,0 +1,438 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/taskquery"
+	"time"
+)
+
+// TaskConfig holds the task rules a deployment may tune.
+type TaskConfig struct {
+	// MaxSubtaskDepth is how many levels of subtasks may hang below a
+	// top-level task. 0 disables subtasks.
+	MaxSubtaskDepth int
+}
+
+// Subtasks lists the direct subtasks of a task the caller can see. They are
+// always in the parent's project, so the parent's access check covers them.
+func (u *TaskUsecase) Subtasks(ctx context.Context, id int) (TaskList, error) {
+	if _, err := u.GetByID(ctx, id); err != nil {
+		return TaskList{}, err
+	}
+
+	tasks, err := u.children(ctx, id)
+	if err != nil {
+		return TaskList{}, err
+	}
+
+	responseDTOs := []dto.TaskResponseDTO{}
+	for _, task := range tasks {
+		responseDTOs = append(responseDTOs, toTaskResponse(task))
+	}
+	// Subtask lists aren't served conditionally, so their validators don't
+	// need the last delete.
+	return newTaskList(responseDTOs, time.Time{}), nil
+}
+
+func (u *TaskUsecase) children(ctx context.Context, id int) ([]domain.Task, error) {
+	filter, err := taskquery.Compile(&taskquery.Query{
+		Conditions: []taskquery.Condition{{Field: "parent", Op: taskquery.OpEqual, Value: strconv.Itoa(id), Token: "parent:", Column: 1}},
+	}, taskquery.Env{Now: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []domain.Task
+
+	err = RetryWithBackoff(ctx, func() error {
+		var repoErr error
+		tasks, repoErr = u.repo.Find(filter)
+		return repoErr
+	})
+	return tasks, err
+}
+
+// subtree returns the subtasks below id level by level: direct subtasks
+// first, then theirs, and so on. Each task is listed once, so a cycle in the
+// stored tree can't keep the walk going.
+func (u *TaskUsecase) subtree(ctx context.Context, id int) ([][]domain.Task, error) {
+	var levels [][]domain.Task
+	parents := []int{id}
+	seen := map[int]bool{id: true}
+
+	for len(parents) > 0 {
+		var level []domain.Task
+		for _, parentID := range parents {
+			tasks, err := u.children(ctx, parentID)
+			if err != nil {
+				return nil, err
+			}
+			for _, task := range tasks {
+				if !seen[task.ID] {
+					seen[task.ID] = true
+					level = append(level, task)
+				}
+			}
+		}
+		if len(level) == 0 {
+			break
+		}
+
+		levels = append(levels, level)
+		parents = parents[:0]
+		for _, task := range level {
+			parents = append(parents, task.ID)
+		}
+	}
+	return levels, nil
+}
+
+// placeTask checks that task may be saved under task.ParentID and in
+// task.ProjectID. A subtask takes its parent's project; asking for any other
+// is an error. current is the task as stored, or zero for a new task.
+func (u *TaskUsecase) placeTask(ctx context.Context, task *domain.Task, current domain.Task) error {
+	if task.ParentID != 0 {
+		parent, err := u.parentFor(ctx, *task, current)
+		if err != nil {
+			return err
+		}
+		if task.ProjectID != 0 && task.ProjectID != parent.ProjectID {
+			return &domain.ValidationError{Field: "project_id", Message: "a subtask stays in its parent's project"}
+		}
+		task.ProjectID = parent.ProjectID
+	}
+
+	if current.ID == 0 {
+		return u.access.canWriteProject(ctx, task.ProjectID)
+	}
+	if task.ProjectID == current.ProjectID {
+		return nil
+	}
+	if current.Subtasks > 0 {
+		return &domain.ValidationError{Field: "project_id", Message: "move or delete the task's subtasks before changing its project"}
+	}
+	return u.access.canWriteProject(ctx, task.ProjectID)
+}
+
+// parentFor loads the parent task.ParentID names and, when the task is
+// moving there, checks the move keeps the tree acyclic and within
+// MaxSubtaskDepth.
+func (u *TaskUsecase) parentFor(ctx context.Context, task, current domain.Task) (domain.Task, error) {
+	maxDepth := u.config.MaxSubtaskDepth
+	if maxDepth <= 0 {
+		return domain.Task{}, &domain.ValidationError{Field: "parent_id", Message: "subtasks are disabled"}
+	}
+
+	parent, err := u.repo.GetByID(task.ParentID)
+	if err == nil {
+		err = u.access.canWriteTask(ctx, parent)
+	}
+	if _, ok := err.(*domain.NotFoundError); ok {
+		return domain.Task{}, &domain.ValidationError{Field: "parent_id", Message: fmt.Sprintf("task %d does not exist", task.ParentID)}
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if task.ParentID == current.ParentID {
+		return parent, nil
+	}
+
+	// depth is the task's level once moved: 1 directly below a top-level
+	// task. The walk stops early once the limit is exceeded.
+	depth := 1
+	for ancestor := parent; depth <= maxDepth; depth++ {
+		if task.ID != 0 && ancestor.ID == task.ID {
+			return domain.Task{}, &domain.ValidationError{Field: "parent_id", Message: "a task cannot be placed under itself or one of its subtasks"}
+		}
+		if ancestor.ParentID == 0 {
+			break
+		}
+		ancestor, err = u.repo.GetByID(ancestor.ParentID)
+		if err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	height := 0
+	if current.Subtasks > 0 {
+		levels, err := u.subtree(ctx, current.ID)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		height = len(levels)
+	}
+
+	if depth+height > maxDepth {
+		return domain.Task{}, &domain.ValidationError{Field: "parent_id", Message: fmt.Sprintf("subtasks can be nested at most %d levels deep", maxDepth)}
+	}
+	return parent, nil
+}
+
+// checkBatchPlacement checks every task a batch places under a parent against
+// the tree as the whole batch leaves it. placeTask only sees the stored tree,
+// so two moves that pass alone could together close a cycle or nest subtasks
+// too deep. It returns the failures by index in ops.
+func (u *TaskUsecase) checkBatchPlacement(ctx context.Context, ops []domain.TaskOperation) (map[int]error, error) {
+	tree := batchTree{
+		u:       u,
+		ctx:     ctx,
+		parents: make(map[int]int),
+		deleted: make(map[int]bool),
+		created: make(map[int]bool),
+	}
+	placements := 0
+	for _, op := range ops {
+		switch op.Kind {
+		case domain.OperationCreate:
+			if op.Task.ParentID != 0 {
+				tree.created[op.Task.ParentID] = true
+				placements++
+			}
+		case domain.OperationUpdate:
+			tree.parents[op.Task.ID] = op.Task.ParentID
+			placements++
+		case domain.OperationDelete:
+			tree.deleted[op.Task.ID] = true
+		}
+	}
+	// A single placement was already checked against the only tree it
+	// changes.
+	if placements < 2 {
+		return nil, nil
+	}
+
+	maxDepth := u.config.MaxSubtaskDepth
+	failed := make(map[int]error)
+	for j, op := range ops {
+		if op.Kind == domain.OperationDelete || op.Task.ParentID == 0 {
+			continue
+		}
+
+		depth, cycle, err := tree.level(op.Task, maxDepth)
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			failed[j] = &domain.ValidationError{Field: "parent_id", Message: "a task cannot be placed under itself or one of its subtasks"}
+			continue
+		}
+
+		height := 0
+		if op.Task.ID != 0 {
+			height, err = tree.height(op.Task.ID, maxDepth)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if depth+height > maxDepth {
+			failed[j] = &domain.ValidationError{Field: "parent_id", Message: fmt.Sprintf("subtasks can be nested at most %d levels deep", maxDepth)}
+		}
+	}
+	return failed, nil
+}
+
+// batchTree is the task tree as a batch will leave it: the stored tree with
+// the batch's parent changes, deletions and new subtasks applied.
+type batchTree struct {
+	u       *TaskUsecase
+	ctx     context.Context
+	parents map[int]int  // parent after the batch of each task it updates
+	deleted map[int]bool // tasks the batch deletes
+	created map[int]bool // tasks the batch creates subtasks under
+}
+
+func (t *batchTree) parentOf(id int) (int, error) {
+	if parentID, updated := t.parents[id]; updated {
+		return parentID, nil
+	}
+	task, err := t.u.repo.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+	return task.ParentID, nil
+}
+
+func (t *batchTree) children(id int) ([]int, error) {
+	stored, err := t.u.children(t.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, task := range stored {
+		if parentID, updated := t.parents[task.ID]; (!updated || parentID == id) && !t.deleted[task.ID] {
+			ids = append(ids, task.ID)
+		}
+	}
+	for taskID, parentID := range t.parents {
+		if parentID == id && !slices.Contains(ids, taskID) {
+			ids = append(ids, taskID)
+		}
+	}
+	return ids, nil
+}
+
+// level is like the walk in parentFor: task's level below its top-level
+// ancestor, 1 for a direct subtask, giving up once it passes maxDepth. It
+// also reports whether task is among its own ancestors.
+func (t *batchTree) level(task domain.Task, maxDepth int) (int, bool, error) {
+	depth := 1
+	for ancestor := task.ParentID; depth <= maxDepth; depth++ {
+		if task.ID != 0 && ancestor == task.ID {
+			return depth, true, nil
+		}
+		parentID, err := t.parentOf(ancestor)
+		if err != nil {
+			return 0, false, err
+		}
+		if parentID == 0 {
+			break
+		}
+		ancestor = parentID
+	}
+	return depth, false, nil
+}
+
+// height counts the levels of subtasks below id, giving up once it passes
+// maxDepth.
+func (t *batchTree) height(id, maxDepth int) (int, error) {
+	height := 0
+	level := []int{id}
+	seen := map[int]bool{id: true}
+
+	for height <= maxDepth {
+		var next []int
+		created := false
+		for _, parentID := range level {
+			created = created || t.created[parentID]
+			ids, err := t.children(parentID)
+			if err != nil {
+				return 0, err
+			}
+			for _, child := range ids {
+				if !seen[child] {
+					seen[child] = true
+					next = append(next, child)
+				}
+			}
+		}
+		if len(next) == 0 && !created {
+			break
+		}
+		height++
+		level = next
+	}
+	return height, nil
+}
+
+// deleteWithSubtasks deletes a task that has subtasks, handling them as mode
+// says, in one atomic batch. It returns the IDs of every task it changed or
+// whose blockers it removed.
+func (u *TaskUsecase) deleteWithSubtasks(ctx context.Context, task domain.Task, mode string) ([]int, error) {
+	var ops []domain.TaskOperation
+
+	switch mode {
+	case dto.DeleteSubtasksCascade:
+		levels, err := u.subtree(ctx, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(levels) - 1; i >= 0; i-- {
+			for _, subtask := range levels[i] {
+				ops = append(ops, domain.TaskOperation{Kind: domain.OperationDelete, Task: subtask})
+			}
+		}
+
+	case dto.DeleteSubtasksPromote:
+		children, err := u.children(ctx, task.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			child.ParentID = task.ParentID
+			ops = append(ops, domain.TaskOperation{Kind: domain.OperationUpdate, Task: child})
+		}
+
+	default:
+		return nil, &domain.ValidationError{
+			Field:   "cascade",
+			Message: fmt.Sprintf("task has %d subtasks; delete them first, or use cascade=delete or cascade=promote", task.Subtasks),
+		}
+	}
+	ops = append(ops, domain.TaskOperation{Kind: domain.OperationDelete, Task: task})
+
+	var results []domain.TaskOperationResult
+
+	err := RetryWithBackoff(ctx, func() error {
+		var repoErr error
+		results, repoErr = u.repo.ApplyBatch(ops, true)
+		return repoErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for i, res := range results {
+		if _, aborted := res.Err.(*domain.BatchAbortedError); res.Err != nil && !aborted {
+			return nil, res.Err
+		}
+		ids = append(ids, ops[i].Task.ID)
+		ids = append(ids, ops[i].Task.Blocks...)
+	}
+	for i, res := range results {
+		if ops[i].Kind == domain.OperationDelete {
+			u.publishTask(ctx, EventTaskDeleted, ops[i].Task)
+		} else {
+			u.publishTask(ctx, EventTaskUpdated, res.Task)
+		}
+	}
+	return ids, nil
+}
+
+// completeFinishedParents walks up from taskID, completing every task that
+// asked to be auto-completed once all of its subtasks and checklist items
+// are done, and stops at the first one that stays open. Blocked tasks stay
+// open too. It returns the tasks it completed, as saved. Failures are only
+// logged: the change that triggered the roll-up is already saved.
+func completeFinishedParents(repo repository.TaskRepository, taskID int) []domain.Task {
+	var completed []domain.Task
+	for taskID != 0 {
+		task, err := repo.GetByID(taskID)
+		if err != nil {
+			log.Printf("auto-complete: could not load task %d: %v", taskID, err)
+			return completed
+		}
+		if !task.AutoComplete || task.Status == domain.StatusCompleted || !task.ChildrenDone() || task.Blocked {
+			return completed
+		}
+
+		task.Status = domain.StatusCompleted
+		saved, err := repo.Update(task)
+		if err != nil {
+			log.Printf("auto-complete: could not complete task %d: %v", taskID, err)
+			return completed
+		}
+		completed = append(completed, saved)
+		taskID = task.ParentID
+	}
+	return completed
+}
+
+// finishParents runs the auto-complete roll-up from taskID and treats each
+// completed task like any other update: its dependents, which cache whether
+// they are blocked, are invalidated with it, and task.updated is published.
+func finishParents(ctx context.Context, repo repository.TaskRepository, cache Cache, events *EventBus, taskID int) {
+	for _, task := range completeFinishedParents(repo, taskID) {
+		invalidateTasks(cache, append([]int{task.ID}, task.Blocks...)...)
+		publishTask(ctx, events, EventTaskUpdated, task)
+	}
+}
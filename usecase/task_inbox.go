@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/taskquery"
+)
+
+// inboxSort puts the most pressing work first: soonest due, then highest
+// priority.
+const inboxSort = "due,-priority"
+
+// AssignedTasks is the caller's inbox: the tasks assigned to them that they
+// can still see, leaving out completed ones unless includeCompleted is set.
+func (u *TaskUsecase) AssignedTasks(ctx context.Context, includeCompleted bool) ([]dto.TaskResponseDTO, error) {
+	env := queryEnv(ctx)
+	if env.UserID == 0 {
+		return nil, &domain.UnauthorizedError{Message: "sign in to see your tasks"}
+	}
+
+	scope, err := u.access.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &taskquery.Query{Conditions: []taskquery.Condition{
+		{Field: "assignee", Op: taskquery.OpEqual, Value: "me", Token: "assignee:me", Column: 1},
+		scope,
+	}}
+	if !includeCompleted {
+		query.Conditions = append(query.Conditions, taskquery.Condition{
+			Field: "status", Op: taskquery.OpEqual, Value: "completed", Negated: true, Token: "-status:completed", Column: 1,
+		})
+	}
+
+	filter, err := taskquery.Compile(query, env)
+	if err != nil {
+		return nil, err
+	}
+	order, err := taskquery.ParseSort(inboxSort)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []domain.Task
+
+	err = RetryWithBackoff(ctx, func() error {
+		var repoErr error
+		tasks, repoErr = u.repo.Find(filter)
+		return repoErr
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	order.Apply(tasks)
+
+	responseDTOs := make([]dto.TaskResponseDTO, len(tasks))
+	for i, task := range tasks {
+		responseDTOs[i] = toTaskResponse(task)
+	}
+	return responseDTOs, nil
+}
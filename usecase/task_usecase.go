@@ -3,26 +3,30 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strings"
 	"task-manager-api/domain"
 	"task-manager-api/dto"
 	"task-manager-api/repository"
+	"task-manager-api/taskquery"
+	"task-manager-api/validation"
+	"time"
 )
 
 type TaskUsecase struct {
-	repo  repository.TaskRepository
-	cache *CacheService
+	repo   repository.TaskRepository
+	cache  Cache
+	access *TaskAccess
+	config TaskConfig
+	events *EventBus
 }
 
-func NewTaskUsecase(repo repository.TaskRepository, cache *CacheService) *TaskUsecase {
-	return &TaskUsecase{repo: repo, cache: cache}
+func NewTaskUsecase(repo repository.TaskRepository, cache Cache, access *TaskAccess, config TaskConfig, events *EventBus) *TaskUsecase {
+	return &TaskUsecase{repo: repo, cache: cache, access: access, config: config, events: events}
 }
 
 func (u *TaskUsecase) CreateTask(ctx context.Context, createReq dto.CreateTaskDTO) (dto.TaskResponseDTO, error) {
-	if createReq.Title == "" {
-		return dto.TaskResponseDTO{}, &domain.ValidationError{Field: "Title", Message: "title is required"}
-	}
-	if createReq.Description == "" {
-		return dto.TaskResponseDTO{}, &domain.ValidationError{Field: "Description", Message: "description is required"}
+	if err := validation.Validate(createReq); err != nil {
+		return dto.TaskResponseDTO{}, err
 	}
 
 	task := domain.Task{
@@ -30,6 +34,17 @@ func (u *TaskUsecase) CreateTask(ctx context.Context, createReq dto.CreateTaskDT
 		Description: createReq.Description,
 		Status:      createReq.Status,
 		Priority:    createReq.Priority,
+		DueDate:     createReq.DueDate,
+		ProjectID:   createReq.ProjectID,
+
+		ParentID:     createReq.ParentID,
+		AutoComplete: createReq.AutoComplete,
+
+		EstimateMinutes: createReq.EstimateMinutes,
+	}
+
+	if err := u.placeTask(ctx, &task, domain.Task{}); err != nil {
+		return dto.TaskResponseDTO{}, err
 	}
 
 	var createdTask domain.Task
@@ -44,88 +59,247 @@ func (u *TaskUsecase) CreateTask(ctx context.Context, createReq dto.CreateTaskDT
 		return dto.TaskResponseDTO{}, err
 	}
 
-	u.cache.Delete("all_tasks")
+	u.invalidateTasks(createdTask.ParentID)
+	u.publishTask(ctx, EventTaskCreated, createdTask)
+	finishParents(ctx, u.repo, u.cache, u.events, createdTask.ParentID)
 
-	return dto.TaskResponseDTO{
-		ID:          createdTask.ID,
-		Title:       createdTask.Title,
-		Description: createdTask.Description,
-		Status:      createdTask.Status,
-		Priority:    createdTask.Priority,
-	}, nil
+	return toTaskResponse(createdTask), nil
+}
+
+// TaskList is the full task list together with the validators clients use
+// for conditional GETs. It is cached as a whole, so answering a conditional
+// request from a warm cache needs no database query.
+type TaskList struct {
+	Tasks        []dto.TaskResponseDTO
+	ETag         string
+	LastModified time.Time
+	// DeletedAt is when a task was last deleted. A delete leaves no
+	// updated_at behind, so LastModified is never earlier than this.
+	DeletedAt time.Time
 }
 
 func (u *TaskUsecase) GetAllTasks(ctx context.Context) ([]dto.TaskResponseDTO, error) {
-	if cached, found := u.cache.Get("all_tasks"); found {
-		return cached.([]dto.TaskResponseDTO), nil
+	list, err := u.ListTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return list.Tasks, nil
+}
+
+// ListTasks returns the tasks the caller can see. The full list is cached
+// once for everyone and narrowed per caller.
+func (u *TaskUsecase) ListTasks(ctx context.Context) (TaskList, error) {
+	cached, err := u.cache.GetOrLoad(ctx, "task_list", func(ctx context.Context) (interface{}, error) {
+		var tasks []domain.Task
+		var deletedAt time.Time
+
+		err := RetryWithBackoff(ctx, func() error {
+			var repoErr error
+			tasks, repoErr = u.repo.GetAll()
+			if repoErr != nil {
+				return repoErr
+			}
+			deletedAt, repoErr = u.repo.LastDeletedAt()
+			return repoErr
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		var responseDTOs []dto.TaskResponseDTO
+		for _, task := range tasks {
+			responseDTOs = append(responseDTOs, toTaskResponse(task))
+		}
+		return newTaskList(responseDTOs, deletedAt), nil
+	}, tagTasks)
+
+	if err != nil {
+		return TaskList{}, err
+	}
+
+	list := cached.(TaskList)
+	visible, err := u.access.visibleTasks(ctx, list.Tasks)
+	if err != nil {
+		return TaskList{}, err
+	}
+	return newTaskList(visible, list.DeletedAt), nil
+}
+
+// FilterTasks lists the tasks matching a taskquery expression such as
+// "status:pending due<2026-11-01" and the label filter. Filtered lists are
+// not cached.
+func (u *TaskUsecase) FilterTasks(ctx context.Context, req dto.TaskFilterDTO) (TaskList, error) {
+	if err := validation.Validate(req); err != nil {
+		return TaskList{}, err
+	}
+
+	query, err := taskquery.Parse(req.Query)
+	if err != nil {
+		return TaskList{}, err
+	}
+	query.Conditions = append(query.Conditions, labelConditions(req.Labels, req.LabelMatch == "all")...)
+	if req.Assignee != "" {
+		query.Conditions = append(query.Conditions, taskquery.Condition{
+			Field: "assignee", Op: taskquery.OpEqual, Value: req.Assignee, Token: "assignee=" + req.Assignee, Column: 1,
+		})
+	}
+
+	scope, err := u.access.scope(ctx)
+	if err != nil {
+		return TaskList{}, err
+	}
+	query.Conditions = append(query.Conditions, scope)
+
+	filter, err := taskquery.Compile(query, queryEnv(ctx))
+	if err != nil {
+		return TaskList{}, err
 	}
 
 	var tasks []domain.Task
+	var deletedAt time.Time
 
-	err := RetryWithBackoff(ctx, func() error {
+	err = RetryWithBackoff(ctx, func() error {
 		var repoErr error
-		tasks, repoErr = u.repo.GetAll()
+		tasks, repoErr = u.repo.Find(filter)
+		if repoErr != nil {
+			return repoErr
+		}
+		deletedAt, repoErr = u.repo.LastDeletedAt()
 		return repoErr
 	})
 
 	if err != nil {
-		return nil, err
+		return TaskList{}, err
 	}
 
-	var responseDTOs []dto.TaskResponseDTO
+	responseDTOs := []dto.TaskResponseDTO{}
 	for _, task := range tasks {
-		responseDTOs = append(responseDTOs, dto.TaskResponseDTO{
-			ID:          task.ID,
-			Title:       task.Title,
-			Description: task.Description,
-			Status:      task.Status,
-			Priority:    task.Priority,
-		})
+		responseDTOs = append(responseDTOs, toTaskResponse(task))
 	}
-
-	u.cache.Set("all_tasks", responseDTOs)
-
-	return responseDTOs, nil
+	return newTaskList(responseDTOs, deletedAt), nil
 }
 
-func (u *TaskUsecase) GetByID(ctx context.Context, id int) (dto.TaskResponseDTO, error) {
-	cacheKey := fmt.Sprintf("task_%d", id)
-	if cached, found := u.cache.Get(cacheKey); found {
-		return cached.(dto.TaskResponseDTO), nil
+// ProjectTasks lists a project's tasks for anyone who can see the project.
+func (u *TaskUsecase) ProjectTasks(ctx context.Context, projectID int) (TaskList, error) {
+	if _, err := u.access.authorizeProject(ctx, projectID, domain.RoleViewer); err != nil {
+		return TaskList{}, err
 	}
 
-	var task domain.Task
+	filter, err := taskquery.Compile(&taskquery.Query{
+		Conditions: []taskquery.Condition{taskquery.ProjectScope([]int{projectID}, false)},
+	}, taskquery.Env{Now: time.Now()})
+	if err != nil {
+		return TaskList{}, err
+	}
 
-	err := RetryWithBackoff(ctx, func() error {
+	var tasks []domain.Task
+	var deletedAt time.Time
+
+	err = RetryWithBackoff(ctx, func() error {
 		var repoErr error
-		task, repoErr = u.repo.GetByID(id)
+		tasks, repoErr = u.repo.Find(filter)
+		if repoErr != nil {
+			return repoErr
+		}
+		deletedAt, repoErr = u.repo.LastDeletedAt()
 		return repoErr
 	})
 
 	if err != nil {
-		return dto.TaskResponseDTO{}, err
+		return TaskList{}, err
 	}
 
-	response := dto.TaskResponseDTO{
-		ID:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		Status:      task.Status,
-		Priority:    task.Priority,
+	responseDTOs := []dto.TaskResponseDTO{}
+	for _, task := range tasks {
+		responseDTOs = append(responseDTOs, toTaskResponse(task))
+	}
+	return newTaskList(responseDTOs, deletedAt), nil
+}
+
+// queryEnv is what the caller's queries are evaluated relative to.
+func queryEnv(ctx context.Context) taskquery.Env {
+	env := taskquery.Env{Now: time.Now()}
+	if user, ok := domain.UserFromContext(ctx); ok {
+		env.UserID = user.ID
+	}
+	return env
+}
+
+// labelConditions expresses ?labels= as taskquery conditions: one label
+// condition listing every name for "any", or one per name for "all".
+func labelConditions(names []string, all bool) []taskquery.Condition {
+	if len(names) == 0 {
+		return nil
 	}
 
-	u.cache.Set(cacheKey, response)
+	condition := func(value string) taskquery.Condition {
+		return taskquery.Condition{Field: "label", Op: taskquery.OpEqual, Value: value, Token: "labels=" + value, Column: 1}
+	}
 
-	return response, nil
+	if !all {
+		return []taskquery.Condition{condition(strings.Join(names, ","))}
+	}
+	conditions := make([]taskquery.Condition, len(names))
+	for i, name := range names {
+		conditions[i] = condition(name)
+	}
+	return conditions
 }
 
-func (u *TaskUsecase) UpdateTask(ctx context.Context, id int, updateReq dto.UpdateTaskDTO) (dto.TaskResponseDTO, error) {
-	if updateReq.Title == "" {
-		return dto.TaskResponseDTO{}, &domain.ValidationError{Field: "Title", Message: "Title is required"}
+// newTaskList derives a weak ETag from the task count, the newest update or
+// delete and the sum of versions. Any create, update or delete changes at
+// least one of them, and moves LastModified.
+func newTaskList(tasks []dto.TaskResponseDTO, deletedAt time.Time) TaskList {
+	lastModified := deletedAt
+	versions := 0
+	for _, task := range tasks {
+		if task.UpdatedAt.After(lastModified) {
+			lastModified = task.UpdatedAt
+		}
+		versions += task.Version
+	}
+
+	return TaskList{
+		Tasks:        tasks,
+		ETag:         fmt.Sprintf(`W/"tasks-%d-%d-%d"`, len(tasks), lastModified.UnixMilli(), versions),
+		LastModified: lastModified,
+		DeletedAt:    deletedAt,
+	}
+}
+
+func (u *TaskUsecase) GetByID(ctx context.Context, id int) (dto.TaskResponseDTO, error) {
+	cacheKey := fmt.Sprintf("task_%d", id)
+	cached, err := u.cache.GetOrLoad(ctx, cacheKey, func(ctx context.Context) (interface{}, error) {
+		var task domain.Task
+
+		err := RetryWithBackoff(ctx, func() error {
+			var repoErr error
+			task, repoErr = u.repo.GetByID(id)
+			return repoErr
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return toTaskResponse(task), nil
+	}, taskTag(id))
+
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
 	}
 
-	if updateReq.Description == "" {
-		return dto.TaskResponseDTO{}, &domain.ValidationError{Field: "Description", Message: "Description is required"}
+	task := cached.(dto.TaskResponseDTO)
+	if err := u.access.canReadTask(ctx, task.ID, task.ProjectID); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+	return task, nil
+}
+
+func (u *TaskUsecase) UpdateTask(ctx context.Context, id int, updateReq dto.UpdateTaskDTO) (dto.TaskResponseDTO, error) {
+	if err := validation.Validate(updateReq); err != nil {
+		return dto.TaskResponseDTO{}, err
 	}
 
 	var existingTask domain.Task
@@ -140,10 +314,42 @@ func (u *TaskUsecase) UpdateTask(ctx context.Context, id int, updateReq dto.Upda
 		return dto.TaskResponseDTO{}, err
 	}
 
+	if err := u.access.canWriteTask(ctx, existingTask); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
+	current := existingTask
+	if updateReq.ProjectID != nil {
+		existingTask.ProjectID = *updateReq.ProjectID
+	}
+	if updateReq.ParentID != nil {
+		existingTask.ParentID = *updateReq.ParentID
+	}
+	if err := u.placeTask(ctx, &existingTask, current); err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
+
 	existingTask.Title = updateReq.Title
 	existingTask.Description = updateReq.Description
 	existingTask.Status = updateReq.Status
 	existingTask.Priority = updateReq.Priority
+	existingTask.DueDate = updateReq.DueDate
+	if updateReq.EstimateMinutes != nil {
+		existingTask.EstimateMinutes = *updateReq.EstimateMinutes
+	}
+	if existingTask.Status == domain.StatusCompleted && current.Status != domain.StatusCompleted {
+		if err := checkUnblocked(current); err != nil {
+			return dto.TaskResponseDTO{}, err
+		}
+	}
+	if updateReq.AutoComplete != nil {
+		existingTask.AutoComplete = *updateReq.AutoComplete
+		// Turning auto-complete on for a task whose work is already done
+		// completes it straight away.
+		if existingTask.AutoComplete && !current.AutoComplete && existingTask.ChildrenDone() && !existingTask.Blocked {
+			existingTask.Status = domain.StatusCompleted
+		}
+	}
 
 	var updatedTask domain.Task
 
@@ -153,38 +359,164 @@ func (u *TaskUsecase) UpdateTask(ctx context.Context, id int, updateReq dto.Upda
 		return repoErr
 	})
 
-	cacheKey := fmt.Sprintf("task_%d", id)
-	u.cache.Delete(cacheKey)
-	u.cache.Delete("all_tasks")
+	if err != nil {
+		return dto.TaskResponseDTO{}, err
+	}
 
-	return dto.TaskResponseDTO{
-		ID:          updatedTask.ID,
-		Title:       updatedTask.Title,
-		Description: updatedTask.Description,
-		Status:      updatedTask.Status,
-		Priority:    updatedTask.Priority,
-	}, nil
+	// Dependents cache whether they are blocked, which follows this task's
+	// status.
+	u.invalidateTasks(append([]int{id, current.ParentID, updatedTask.ParentID}, current.Blocks...)...)
+	u.publishTask(ctx, EventTaskUpdated, updatedTask)
+	finishParents(ctx, u.repo, u.cache, u.events, updatedTask.ParentID)
+	if current.ParentID != updatedTask.ParentID {
+		finishParents(ctx, u.repo, u.cache, u.events, current.ParentID)
+	}
+
+	return toTaskResponse(updatedTask), nil
 }
 
-func (u *TaskUsecase) DeleteTask(ctx context.Context, id int) error {
+// DeleteTask deletes a task. mode, one of the dto.DeleteSubtasks values,
+// says what happens to its subtasks; the default refuses to delete a task
+// that has any.
+func (u *TaskUsecase) DeleteTask(ctx context.Context, id int, mode string) error {
+	if mode == "" {
+		mode = dto.DeleteSubtasksRestrict
+	}
+	switch mode {
+	case dto.DeleteSubtasksRestrict, dto.DeleteSubtasksCascade, dto.DeleteSubtasksPromote:
+	default:
+		return &domain.ValidationError{Field: "cascade", Message: "must be one of restrict, delete or promote"}
+	}
+
+	var task domain.Task
+
 	err := RetryWithBackoff(ctx, func() error {
-		_, repoErr := u.repo.GetByID(id)
+		var repoErr error
+		task, repoErr = u.repo.GetByID(id)
 		return repoErr
 	})
 	if err != nil {
 		return err
 	}
 
-	err = RetryWithBackoff(ctx, func() error {
-		return u.repo.Delete(id)
-	})
+	if err := u.access.canWriteTask(ctx, task); err != nil {
+		return err
+	}
+
+	deleted := []int{id}
+	if task.Subtasks > 0 {
+		deleted, err = u.deleteWithSubtasks(ctx, task, mode)
+	} else {
+		err = RetryWithBackoff(ctx, func() error {
+			return u.repo.Delete(id)
+		})
+		if err == nil {
+			u.publishTask(ctx, EventTaskDeleted, task)
+		}
+	}
 	if err != nil {
 		return err
 	}
 
-	cacheKey := fmt.Sprintf("task_%d", id)
-	u.cache.Delete(cacheKey)
-	u.cache.Delete("all_tasks")
+	u.invalidateTasks(append(append(deleted, task.ParentID), task.Blocks...)...)
+	finishParents(ctx, u.repo, u.cache, u.events, task.ParentID)
 
 	return nil
 }
+
+// publishTask announces a committed change to task. Deleted tasks are
+// published as they were before the delete.
+func (u *TaskUsecase) publishTask(ctx context.Context, eventType string, task domain.Task) {
+	publishTask(ctx, u.events, eventType, task)
+}
+
+func publishTask(ctx context.Context, events *EventBus, eventType string, task domain.Task) {
+	var actorID int
+	if user, ok := domain.UserFromContext(ctx); ok {
+		actorID = user.ID
+	}
+	events.Publish(ctx, Event{Type: eventType, TaskID: task.ID, ActorID: actorID, Data: TaskData{Task: toTaskResponse(task)}})
+}
+
+func (u *TaskUsecase) invalidateTasks(ids ...int) {
+	invalidateTasks(u.cache, ids...)
+}
+
+// invalidateTasks drops every cached list of tasks and everything derived
+// from the given tasks. ID 0, which no task has, is skipped, so callers can
+// pass a parent ID without checking for one.
+func invalidateTasks(cache Cache, ids ...int) {
+	cache.InvalidateTag(tagTasks)
+	for _, id := range ids {
+		if id != 0 {
+			cache.InvalidateTag(taskTag(id))
+		}
+	}
+}
+
+func toTaskResponse(task domain.Task) dto.TaskResponseDTO {
+	return dto.TaskResponseDTO{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		Version:     task.Version,
+		UpdatedAt:   task.UpdatedAt,
+		DueDate:     task.DueDate,
+		Labels:      toTaskLabels(task.Labels),
+		ProjectID:   task.ProjectID,
+		Assignees:   toTaskAssignees(task.Assignees),
+
+		ParentID:     task.ParentID,
+		AutoComplete: task.AutoComplete,
+		Checklist:    toChecklistItems(task.Checklist),
+		Subtasks:     task.Subtasks,
+		SubtasksDone: task.SubtasksDone,
+		Progress:     task.Progress(),
+
+		EstimateMinutes: task.EstimateMinutes,
+		BlockedBy:       task.BlockedBy,
+		Blocks:          task.Blocks,
+		Blocked:         task.Blocked,
+
+		Comments:     task.Comments,
+		OccurrenceOf: task.OccurrenceOf,
+	}
+}
+
+func toChecklistItems(items []domain.ChecklistItem) []dto.ChecklistItemDTO {
+	if len(items) == 0 {
+		return nil
+	}
+
+	result := make([]dto.ChecklistItemDTO, len(items))
+	for i, item := range items {
+		result[i] = dto.ChecklistItemDTO{ID: item.ID, Text: item.Text, Done: item.Done, Position: item.Position}
+	}
+	return result
+}
+
+func toTaskAssignees(assignees []domain.Assignee) []dto.TaskAssigneeDTO {
+	if len(assignees) == 0 {
+		return nil
+	}
+
+	result := make([]dto.TaskAssigneeDTO, len(assignees))
+	for i, assignee := range assignees {
+		result[i] = dto.TaskAssigneeDTO{UserID: assignee.UserID, Email: assignee.Email, AssignedAt: assignee.AssignedAt}
+	}
+	return result
+}
+
+func toTaskLabels(labels []domain.Label) []dto.TaskLabelDTO {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	result := make([]dto.TaskLabelDTO, len(labels))
+	for i, label := range labels {
+		result[i] = dto.TaskLabelDTO{ID: label.ID, Name: label.Name, Color: label.Color}
+	}
+	return result
+}
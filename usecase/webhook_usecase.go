@@ -0,0 +1,580 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+	"time"
+)
+
+const (
+	// webhookBatch is how many due deliveries one instance claims at a time.
+	webhookBatch   = 10
+	webhookTimeout = 10 * time.Second
+	// webhookLease outlives the slowest batch, webhookBatch requests that
+	// each time out, so a live instance never loses a claim it is still
+	// working through.
+	webhookLease = 5 * time.Minute
+	// maxWebhookAttempts is how many failed attempts mark a delivery
+	// failed. Retries back off from webhookRetryDelay, doubling each time,
+	// up to webhookMaxRetryDelay: about 20 minutes in all.
+	maxWebhookAttempts   = 8
+	webhookRetryDelay    = 10 * time.Second
+	webhookMaxRetryDelay = time.Hour
+	// webhookDisableAfter is how many consecutive failed attempts, across
+	// deliveries, disable a webhook.
+	webhookDisableAfter = 20
+	// webhookRetention is how long finished deliveries and their logs are
+	// kept.
+	webhookRetention = 30 * 24 * time.Hour
+	maxDeliveryList  = 100
+)
+
+// webhookEvents are the events a webhook can subscribe to.
+var webhookEvents = []string{
+	EventTaskCreated, EventTaskUpdated, EventTaskDeleted,
+	EventTaskAssigned, EventTaskUnassigned, EventCommentCreated,
+}
+
+// WebhookUsecase manages webhook subscriptions and sends them events.
+// Events are queued in the database, an outbox that survives restarts, and
+// sent by a dispatcher that claims due deliveries under a lease, so with
+// several API instances each delivery is sent by one of them at a time.
+//
+// Every request is signed: X-Signature is "t=<unix time>,v1=<hex>", where
+// the hex is the HMAC-SHA256, keyed with the webhook's secret, of the time,
+// a ".", and the request body. Receivers should recompute it and reject old
+// timestamps.
+type WebhookUsecase struct {
+	hooks  repository.WebhookRepository
+	tasks  repository.TaskRepository
+	access *TaskAccess
+	client *http.Client
+
+	owner    string // identifies this instance's claims
+	wake     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewWebhookUsecase(hooks repository.WebhookRepository, tasks repository.TaskRepository, access *TaskAccess) *WebhookUsecase {
+	client := newOutboundClient(webhookTimeout)
+	// A redirect is reported as the endpoint's answer, a failure, rather
+	// than followed with the body dropped.
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	return &WebhookUsecase{
+		hooks:  hooks,
+		tasks:  tasks,
+		access: access,
+		client: client,
+		owner:  instanceOwner(),
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+}
+
+// CreateWebhook subscribes a URL for ownerID. The result is the only one
+// that includes the secret.
+func (u *WebhookUsecase) CreateWebhook(ctx context.Context, ownerID int, req dto.CreateWebhookDTO) (dto.WebhookDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.WebhookDTO{}, err
+	}
+	if err := checkWebhookURL("url", req.URL); err != nil {
+		return dto.WebhookDTO{}, err
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return dto.WebhookDTO{}, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = "whsec_" + rand.Text()
+	}
+
+	hook, err := u.hooks.Create(domain.Webhook{OwnerID: ownerID, URL: req.URL, Secret: secret, Events: events})
+	if err != nil {
+		return dto.WebhookDTO{}, err
+	}
+
+	result := toWebhookDTO(hook)
+	result.Secret = hook.Secret
+	return result, nil
+}
+
+func (u *WebhookUsecase) ListWebhooks(ctx context.Context, ownerID int) ([]dto.WebhookDTO, error) {
+	hooks, err := u.hooks.ListByOwner(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.WebhookDTO, len(hooks))
+	for i, hook := range hooks {
+		result[i] = toWebhookDTO(hook)
+	}
+	return result, nil
+}
+
+func (u *WebhookUsecase) GetWebhook(ctx context.Context, ownerID, id int) (dto.WebhookDTO, error) {
+	hook, err := u.ownedWebhook(ownerID, id)
+	if err != nil {
+		return dto.WebhookDTO{}, err
+	}
+	return toWebhookDTO(hook), nil
+}
+
+func (u *WebhookUsecase) UpdateWebhook(ctx context.Context, ownerID, id int, req dto.UpdateWebhookDTO) (dto.WebhookDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.WebhookDTO{}, err
+	}
+	if err := checkWebhookURL("url", req.URL); err != nil {
+		return dto.WebhookDTO{}, err
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return dto.WebhookDTO{}, err
+	}
+
+	hook, err := u.ownedWebhook(ownerID, id)
+	if err != nil {
+		return dto.WebhookDTO{}, err
+	}
+
+	hook.URL = req.URL
+	hook.Events = events
+	if req.Active != nil {
+		if *req.Active && !hook.Active {
+			hook.Failures = 0
+			hook.DisabledAt = nil
+		}
+		hook.Active = *req.Active
+	}
+
+	hook, err = u.hooks.Update(hook)
+	if err != nil {
+		return dto.WebhookDTO{}, err
+	}
+	if hook.Active {
+		u.wakeDispatcher() // deliveries held while it was disabled
+	}
+	return toWebhookDTO(hook), nil
+}
+
+// DeleteWebhook deletes the webhook and its queued deliveries.
+func (u *WebhookUsecase) DeleteWebhook(ctx context.Context, ownerID, id int) error {
+	if _, err := u.ownedWebhook(ownerID, id); err != nil {
+		return err
+	}
+	return u.hooks.Delete(id)
+}
+
+// ListDeliveries lists a webhook's most recent deliveries, newest first.
+func (u *WebhookUsecase) ListDeliveries(ctx context.Context, ownerID, id, limit int) ([]dto.WebhookDeliveryDTO, error) {
+	if limit < 1 || limit > maxDeliveryList {
+		return nil, &domain.ValidationError{Field: "limit", Message: fmt.Sprintf("must be between 1 and %d", maxDeliveryList)}
+	}
+	if _, err := u.ownedWebhook(ownerID, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := u.hooks.ListDeliveries(id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.WebhookDeliveryDTO, len(deliveries))
+	for i, delivery := range deliveries {
+		result[i] = toWebhookDeliveryDTO(delivery)
+	}
+	return result, nil
+}
+
+// GetDelivery returns a delivery with its payload and every attempt made.
+func (u *WebhookUsecase) GetDelivery(ctx context.Context, ownerID, id, deliveryID int) (dto.WebhookDeliveryDTO, error) {
+	delivery, err := u.ownedDelivery(ownerID, id, deliveryID)
+	if err != nil {
+		return dto.WebhookDeliveryDTO{}, err
+	}
+
+	result := toWebhookDeliveryDTO(delivery)
+	result.Payload = json.RawMessage(delivery.Payload)
+	return result, nil
+}
+
+// Redeliver queues a delivery's payload again as a new delivery, whatever
+// became of the original.
+func (u *WebhookUsecase) Redeliver(ctx context.Context, ownerID, id, deliveryID int) (dto.WebhookDeliveryDTO, error) {
+	delivery, err := u.ownedDelivery(ownerID, id, deliveryID)
+	if err != nil {
+		return dto.WebhookDeliveryDTO{}, err
+	}
+
+	queued, err := u.hooks.Enqueue(domain.WebhookDelivery{
+		WebhookID:    delivery.WebhookID,
+		Event:        delivery.Event,
+		Payload:      delivery.Payload,
+		RedeliveryOf: delivery.ID,
+	})
+	if err != nil {
+		return dto.WebhookDeliveryDTO{}, err
+	}
+
+	u.wakeDispatcher()
+	return toWebhookDeliveryDTO(queued), nil
+}
+
+// Enqueue queues event for every active webhook subscribed to it whose
+// owner can see the task. It is an EventHandler, subscribed to every event;
+// failures are logged, since the change that raised the event is already
+// committed.
+func (u *WebhookUsecase) Enqueue(ctx context.Context, event Event) {
+	hooks, err := u.hooks.ListActive()
+	if err != nil {
+		log.Printf("webhooks: %s on task %d: %v", event.Type, event.TaskID, err)
+		return
+	}
+
+	var subscribed []domain.Webhook
+	for _, hook := range hooks {
+		if hook.Subscribes(event.Type) {
+			subscribed = append(subscribed, hook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return
+	}
+
+	projectID, err := u.eventProject(event)
+	if err != nil {
+		log.Printf("webhooks: %s on task %d: %v", event.Type, event.TaskID, err)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("webhooks: %s on task %d: %v", event.Type, event.TaskID, err)
+		return
+	}
+
+	queued := false
+	for _, hook := range subscribed {
+		canRead, err := u.access.userCanRead(hook.OwnerID, projectID)
+		if err != nil {
+			log.Printf("webhook %d: %s on task %d: %v", hook.ID, event.Type, event.TaskID, err)
+			continue
+		}
+		if !canRead {
+			continue
+		}
+
+		_, err = u.hooks.Enqueue(domain.WebhookDelivery{WebhookID: hook.ID, Event: event.Type, Payload: payload})
+		if err != nil {
+			log.Printf("webhook %d: %s on task %d: %v", hook.ID, event.Type, event.TaskID, err)
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		u.wakeDispatcher()
+	}
+}
+
+// eventProject is the project of the event's task: carried in task events,
+// since a deleted task can't be looked up, and looked up for the rest.
+func (u *WebhookUsecase) eventProject(event Event) (int, error) {
+	if data, ok := event.Data.(TaskData); ok {
+		return data.Task.ProjectID, nil
+	}
+
+	task, err := u.tasks.GetByID(event.TaskID)
+	if err != nil {
+		return 0, err
+	}
+	return task.ProjectID, nil
+}
+
+// Dispatch sends every delivery due at now that no other instance has
+// claimed and returns how many the endpoints accepted.
+func (u *WebhookUsecase) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	delivered := 0
+	for {
+		deliveries, err := u.hooks.Claim(u.owner, now, webhookLease, webhookBatch)
+		if err != nil {
+			return delivered, err
+		}
+
+		for _, delivery := range deliveries {
+			if u.send(ctx, delivery, now) {
+				delivered++
+			}
+		}
+
+		if len(deliveries) < webhookBatch {
+			return delivered, nil
+		}
+	}
+}
+
+// send makes one attempt at a claimed delivery, logs it, and schedules a
+// retry or gives up after maxWebhookAttempts.
+func (u *WebhookUsecase) send(ctx context.Context, delivery domain.WebhookDelivery, now time.Time) bool {
+	hook, err := u.hooks.GetByID(delivery.WebhookID)
+	if err != nil {
+		// Deleted meanwhile, which deletes the delivery too; anything else
+		// is retried once the claim expires.
+		log.Printf("webhook delivery %d: %v", delivery.ID, err)
+		return false
+	}
+	if !hook.Active {
+		// Disabled since the batch was claimed. The claim lapses, and the
+		// delivery waits for the webhook to be re-enabled.
+		return false
+	}
+
+	start := time.Now()
+	code, err := u.post(ctx, hook, delivery)
+	attempt := domain.WebhookAttempt{
+		DeliveryID:   delivery.ID,
+		ResponseCode: code,
+		DurationMS:   int(time.Since(start) / time.Millisecond),
+		AttemptedAt:  start,
+	}
+
+	status := domain.DeliverySucceeded
+	var retryAt *time.Time
+	if err != nil {
+		attempt.Error = err.Error()
+		log.Printf("webhook %d: delivery %d failed: %v", hook.ID, delivery.ID, err)
+
+		status = domain.DeliveryFailed
+		if attempts := delivery.Attempts + 1; attempts < maxWebhookAttempts {
+			status = domain.DeliveryPending
+			at := now.Add(webhookBackoff(attempts))
+			retryAt = &at
+		}
+	}
+
+	if err := u.hooks.RecordAttempt(u.owner, attempt, status, retryAt); err != nil {
+		log.Printf("webhook delivery %d: %v", delivery.ID, err)
+	}
+
+	updated, healthErr := u.hooks.TrackHealth(hook.ID, err == nil, webhookDisableAfter)
+	if healthErr != nil {
+		log.Printf("webhook %d: %v", hook.ID, healthErr)
+	} else if hook.Active && !updated.Active {
+		log.Printf("webhook %d: disabled after %d consecutive failures", hook.ID, updated.Failures)
+	}
+
+	return err == nil
+}
+
+// post sends the delivery's payload to the webhook and returns the response
+// status. Anything but a 2xx answer is an error.
+func (u *WebhookUsecase) post(ctx context.Context, hook domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "task-manager-api-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.Itoa(delivery.ID))
+	req.Header.Set("X-Signature", signWebhook(hook.Secret, time.Now(), delivery.Payload))
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Start runs Dispatch every interval, and straight away when an event is
+// queued, until Close is called. Old finished deliveries are pruned hourly.
+func (u *WebhookUsecase) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var pruned time.Time
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-ticker.C:
+			case <-u.wake:
+			}
+
+			now := time.Now()
+			if _, err := u.Dispatch(context.Background(), now); err != nil {
+				log.Printf("webhook dispatcher: %v", err)
+			}
+
+			if now.Sub(pruned) >= time.Hour {
+				if _, err := u.hooks.PruneDeliveries(now.Add(-webhookRetention)); err != nil {
+					log.Printf("webhook dispatcher: %v", err)
+				}
+				pruned = now
+			}
+		}
+	}()
+}
+
+func (u *WebhookUsecase) Close() {
+	u.stopOnce.Do(func() { close(u.stop) })
+}
+
+func (u *WebhookUsecase) wakeDispatcher() {
+	select {
+	case u.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ownedWebhook reports other users' webhooks as missing.
+func (u *WebhookUsecase) ownedWebhook(ownerID, id int) (domain.Webhook, error) {
+	hook, err := u.hooks.GetByID(id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if hook.OwnerID != ownerID {
+		return domain.Webhook{}, &domain.NotFoundError{Resource: "Webhook", ID: id}
+	}
+	return hook, nil
+}
+
+func (u *WebhookUsecase) ownedDelivery(ownerID, id, deliveryID int) (domain.WebhookDelivery, error) {
+	if _, err := u.ownedWebhook(ownerID, id); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery, err := u.hooks.GetDelivery(deliveryID)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	if delivery.WebhookID != id {
+		return domain.WebhookDelivery{}, &domain.NotFoundError{Resource: "Delivery", ID: deliveryID}
+	}
+	return delivery, nil
+}
+
+// signWebhook computes the X-Signature header for body sent at t.
+func signWebhook(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff is the wait before retrying after the given number of
+// attempts. The upper half of each delay is random, so retries to an
+// endpoint that was down for everyone don't all arrive together.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookMaxRetryDelay
+	if attempts < 20 {
+		delay = min(webhookRetryDelay<<(attempts-1), webhookMaxRetryDelay)
+	}
+	return delay/2 + time.Duration(mathrand.Int63n(int64(delay/2)+1))
+}
+
+// checkWebhookURL checks a URL to POST to, reported as field. Whether its
+// address is public is only known when it is dialled; see newOutboundClient.
+func checkWebhookURL(field, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return &domain.ValidationError{Field: field, Message: "must be an http or https URL"}
+	}
+	return nil
+}
+
+// normalizeWebhookEvents checks every event can be subscribed to and drops
+// repeats.
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+
+	for i, event := range events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, &domain.ValidationError{
+				Field:   fmt.Sprintf("events[%d]", i),
+				Message: "must be one of: " + strings.Join(webhookEvents, ", "),
+			}
+		}
+
+		if !seen[event] {
+			seen[event] = true
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func toWebhookDTO(hook domain.Webhook) dto.WebhookDTO {
+	return dto.WebhookDTO{
+		ID:         hook.ID,
+		URL:        hook.URL,
+		Events:     hook.Events,
+		Active:     hook.Active,
+		Failures:   hook.Failures,
+		DisabledAt: hook.DisabledAt,
+		CreatedAt:  hook.CreatedAt,
+		UpdatedAt:  hook.UpdatedAt,
+	}
+}
+
+func toWebhookDeliveryDTO(delivery domain.WebhookDelivery) dto.WebhookDeliveryDTO {
+	result := dto.WebhookDeliveryDTO{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		Event:         delivery.Event,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		ResponseCode:  delivery.ResponseCode,
+		LastError:     delivery.LastError,
+		RedeliveryOf:  delivery.RedeliveryOf,
+		DeliveredAt:   delivery.DeliveredAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+
+	for _, attempt := range delivery.Log {
+		result.Log = append(result.Log, dto.WebhookAttemptDTO{
+			ResponseCode: attempt.ResponseCode,
+			Error:        attempt.Error,
+			DurationMS:   attempt.DurationMS,
+			AttemptedAt:  attempt.AttemptedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,453 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"testing"
+	"time"
+)
+
+// memWebhookRepository is an in-memory WebhookRepository.
+type memWebhookRepository struct {
+	mu         sync.Mutex
+	hooks      map[int]domain.Webhook
+	deliveries map[int]*memDelivery
+	nextID     int
+}
+
+type memDelivery struct {
+	delivery     domain.WebhookDelivery
+	claimedBy    string
+	claimExpires time.Time
+}
+
+func newMemWebhookRepository() *memWebhookRepository {
+	return &memWebhookRepository{hooks: make(map[int]domain.Webhook), deliveries: make(map[int]*memDelivery)}
+}
+
+func (r *memWebhookRepository) id() int {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *memWebhookRepository) Create(hook domain.Webhook) (domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hook.ID, hook.Active = r.id(), true
+	r.hooks[hook.ID] = hook
+	return hook, nil
+}
+
+func (r *memWebhookRepository) GetByID(id int) (domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hook, ok := r.hooks[id]
+	if !ok {
+		return domain.Webhook{}, &domain.NotFoundError{Resource: "Webhook", ID: id}
+	}
+	return hook, nil
+}
+
+func (r *memWebhookRepository) ListByOwner(ownerID int) ([]domain.Webhook, error) {
+	return r.list(func(hook domain.Webhook) bool { return hook.OwnerID == ownerID }), nil
+}
+
+func (r *memWebhookRepository) ListActive() ([]domain.Webhook, error) {
+	return r.list(func(hook domain.Webhook) bool { return hook.Active }), nil
+}
+
+func (r *memWebhookRepository) list(keep func(domain.Webhook) bool) []domain.Webhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var hooks []domain.Webhook
+	for id := 1; id <= r.nextID; id++ {
+		if hook, ok := r.hooks[id]; ok && keep(hook) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+func (r *memWebhookRepository) Update(hook domain.Webhook) (domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks[hook.ID] = hook
+	return hook, nil
+}
+
+func (r *memWebhookRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.hooks, id)
+	return nil
+}
+
+func (r *memWebhookRepository) Enqueue(delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	delivery.ID, delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt = r.id(), domain.DeliveryPending, &now, now
+	r.deliveries[delivery.ID] = &memDelivery{delivery: delivery}
+	return delivery, nil
+}
+
+func (r *memWebhookRepository) GetDelivery(id int) (domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[id]
+	if !ok {
+		return domain.WebhookDelivery{}, &domain.NotFoundError{Resource: "Delivery", ID: id}
+	}
+	return d.delivery, nil
+}
+
+func (r *memWebhookRepository) ListDeliveries(webhookID, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deliveries []domain.WebhookDelivery
+	for id := r.nextID; id > 0 && len(deliveries) < limit; id-- {
+		if d, ok := r.deliveries[id]; ok && d.delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, d.delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *memWebhookRepository) Claim(owner string, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []domain.WebhookDelivery
+	for id := 1; id <= r.nextID && len(claimed) < limit; id++ {
+		d, ok := r.deliveries[id]
+		if !ok || d.delivery.Status != domain.DeliveryPending || !r.hooks[d.delivery.WebhookID].Active ||
+			d.delivery.NextAttemptAt.After(now) || d.claimExpires.After(now) {
+			continue
+		}
+		d.claimedBy, d.claimExpires = owner, now.Add(lease)
+		claimed = append(claimed, d.delivery)
+	}
+	return claimed, nil
+}
+
+func (r *memWebhookRepository) RecordAttempt(owner string, attempt domain.WebhookAttempt, status string, retryAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := r.deliveries[attempt.DeliveryID]
+	if d == nil || d.claimedBy != owner {
+		return nil
+	}
+	d.delivery.Status, d.delivery.NextAttemptAt = status, retryAt
+	d.delivery.Attempts++
+	d.delivery.ResponseCode, d.delivery.LastError = attempt.ResponseCode, attempt.Error
+	d.delivery.Log = append(d.delivery.Log, attempt)
+	d.claimedBy, d.claimExpires = "", time.Time{}
+	return nil
+}
+
+func (r *memWebhookRepository) TrackHealth(id int, ok bool, disableAfter int) (domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hook := r.hooks[id]
+	if ok {
+		hook.Failures = 0
+	} else if hook.Failures++; hook.Failures >= disableAfter && hook.Active {
+		now := time.Now()
+		hook.Active, hook.DisabledAt = false, &now
+	}
+	r.hooks[id] = hook
+	return hook, nil
+}
+
+func (r *memWebhookRepository) PruneDeliveries(before time.Time) (int, error) { return 0, nil }
+
+func (r *memWebhookRepository) Close() error { return nil }
+
+// webhookReceiver is an httptest endpoint that records what it receives
+// and answers with status.
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func startWebhookReceiver(t *testing.T, status int) *webhookReceiver {
+	t.Helper()
+
+	receiver := &webhookReceiver{status: status}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := receiver.status
+		receiver.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+func newTestWebhooks(t *testing.T, url string, events ...string) (*WebhookUsecase, *memWebhookRepository, dto.WebhookDTO) {
+	t.Helper()
+
+	repo := newMemWebhookRepository()
+	uc := NewWebhookUsecase(repo, nil, NewTaskAccess(nil, nil))
+	// Test servers listen on loopback, which the real dialer refuses.
+	uc.client.Transport = http.DefaultTransport
+	hook, err := uc.CreateWebhook(context.Background(), 1, dto.CreateWebhookDTO{URL: url, Events: events})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	return uc, repo, hook
+}
+
+func taskEvent(eventType string, id int) Event {
+	return Event{Type: eventType, TaskID: id, ActorID: 1, Data: TaskData{Task: dto.TaskResponseDTO{ID: id, Title: "Ship it"}}}
+}
+
+// verifySignature checks X-Signature the way a receiver would.
+func verifySignature(t *testing.T, secret string, request receivedWebhook) {
+	t.Helper()
+
+	var timestamp, signature string
+	for _, part := range strings.Split(request.header.Get("X-Signature"), ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Fatalf("signature timestamp %q is missing or stale", timestamp)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(request.body)
+	if want := hex.EncodeToString(mac.Sum(nil)); !hmac.Equal([]byte(signature), []byte(want)) {
+		t.Fatalf("signature %q, want %q", signature, want)
+	}
+}
+
+func TestWebhookDeliveriesAreSignedAndLogged(t *testing.T) {
+	receiver := startWebhookReceiver(t, http.StatusNoContent)
+	uc, _, hook := newTestWebhooks(t, receiver.URL, EventTaskCreated)
+	ctx := context.Background()
+
+	if !strings.HasPrefix(hook.Secret, "whsec_") {
+		t.Fatalf("generated secret %q, want a whsec_ secret", hook.Secret)
+	}
+
+	uc.Enqueue(ctx, taskEvent(EventTaskCreated, 7))
+	uc.Enqueue(ctx, taskEvent(EventTaskDeleted, 7)) // not subscribed
+
+	delivered, err := uc.Dispatch(ctx, time.Now())
+	if err != nil || delivered != 1 {
+		t.Fatalf("Dispatch = %d, %v; want 1 delivery", delivered, err)
+	}
+
+	requests := receiver.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	request := requests[0]
+	verifySignature(t, hook.Secret, request)
+	if got := request.header.Get("X-Webhook-Event"); got != EventTaskCreated {
+		t.Errorf("X-Webhook-Event = %q, want %q", got, EventTaskCreated)
+	}
+
+	var payload struct {
+		Type string `json:"type"`
+		Data struct {
+			Task dto.TaskResponseDTO `json:"task"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(request.body, &payload); err != nil || payload.Type != EventTaskCreated || payload.Data.Task.ID != 7 {
+		t.Fatalf("payload %s (%v), want task.created for task 7", request.body, err)
+	}
+
+	deliveries, err := uc.ListDeliveries(ctx, 1, hook.ID, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("ListDeliveries = %v, %v; want one delivery", deliveries, err)
+	}
+	if d := deliveries[0]; d.Status != domain.DeliverySucceeded || d.ResponseCode != http.StatusNoContent || d.Attempts != 1 {
+		t.Fatalf("delivery %+v, want succeeded with 204 after one attempt", d)
+	}
+
+	if delivered, _ := uc.Dispatch(ctx, time.Now().Add(time.Hour)); delivered != 0 || len(receiver.received()) != 1 {
+		t.Fatal("a succeeded delivery was sent again")
+	}
+
+	redelivery, err := uc.Redeliver(ctx, 1, hook.ID, deliveries[0].ID)
+	if err != nil || redelivery.RedeliveryOf != deliveries[0].ID {
+		t.Fatalf("Redeliver = %+v, %v", redelivery, err)
+	}
+	uc.Dispatch(ctx, time.Now())
+	if requests := receiver.received(); len(requests) != 2 || string(requests[1].body) != string(request.body) {
+		t.Fatalf("redelivery sent %d requests, want the same payload again", len(requests))
+	}
+
+	if _, err := uc.Redeliver(ctx, 2, hook.ID, deliveries[0].ID); err == nil {
+		t.Fatal("another user redelivered the webhook's delivery")
+	}
+}
+
+func TestWebhookRetriesWithBackoffThenGivesUp(t *testing.T) {
+	receiver := startWebhookReceiver(t, http.StatusInternalServerError)
+	uc, repo, hook := newTestWebhooks(t, receiver.URL, EventTaskUpdated)
+	ctx := context.Background()
+
+	uc.Enqueue(ctx, taskEvent(EventTaskUpdated, 3))
+
+	now := time.Now()
+	for i := 0; i < maxWebhookAttempts+2; i++ {
+		if delivered, err := uc.Dispatch(ctx, now); err != nil || delivered != 0 {
+			t.Fatalf("Dispatch = %d, %v; want a failed attempt", delivered, err)
+		}
+
+		d, _ := repo.GetDelivery(2)
+		if d.Status == domain.DeliveryPending {
+			wait := d.NextAttemptAt.Sub(now)
+			if longest := webhookRetryDelay << (d.Attempts - 1); wait < longest/2 || wait > longest {
+				t.Fatalf("retry %d after %v, want between %v and %v", d.Attempts, wait, longest/2, longest)
+			}
+			if delivered, _ := uc.Dispatch(ctx, now.Add(wait-time.Millisecond)); delivered != 0 || len(receiver.received()) != d.Attempts {
+				t.Fatal("a retry was sent before it was due")
+			}
+		}
+		now = now.Add(2 * webhookMaxRetryDelay)
+	}
+
+	if got := len(receiver.received()); got != maxWebhookAttempts {
+		t.Fatalf("receiver got %d attempts, want %d", got, maxWebhookAttempts)
+	}
+
+	d, err := uc.GetDelivery(ctx, 1, hook.ID, 2)
+	if err != nil || d.Status != domain.DeliveryFailed || len(d.Log) != maxWebhookAttempts {
+		t.Fatalf("delivery %+v, %v; want failed with %d logged attempts", d, err, maxWebhookAttempts)
+	}
+	for _, attempt := range d.Log {
+		if attempt.ResponseCode != http.StatusInternalServerError || attempt.Error == "" {
+			t.Fatalf("attempt %+v, want a logged 500", attempt)
+		}
+	}
+}
+
+func TestWebhookDisabledAfterRepeatedFailures(t *testing.T) {
+	receiver := startWebhookReceiver(t, http.StatusServiceUnavailable)
+	uc, _, hook := newTestWebhooks(t, receiver.URL, EventTaskCreated, EventTaskDeleted)
+	ctx := context.Background()
+
+	for id := 1; id <= 3; id++ {
+		uc.Enqueue(ctx, taskEvent(EventTaskCreated, id))
+	}
+
+	now := time.Now()
+	for i := 0; i < 2*maxWebhookAttempts; i++ {
+		uc.Dispatch(ctx, now)
+		now = now.Add(2 * webhookMaxRetryDelay)
+	}
+
+	if got := len(receiver.received()); got != webhookDisableAfter {
+		t.Fatalf("receiver got %d attempts, want %d before the webhook was disabled", got, webhookDisableAfter)
+	}
+	disabled, _ := uc.GetWebhook(ctx, 1, hook.ID)
+	if disabled.Active || disabled.DisabledAt == nil {
+		t.Fatalf("webhook %+v, want it disabled", disabled)
+	}
+
+	uc.Enqueue(ctx, taskEvent(EventTaskDeleted, 1))
+	if deliveries, _ := uc.ListDeliveries(ctx, 1, hook.ID, 10); len(deliveries) != 3 {
+		t.Fatalf("a disabled webhook queued an event: %d deliveries", len(deliveries))
+	}
+
+	receiver.mu.Lock()
+	receiver.status = http.StatusOK
+	receiver.mu.Unlock()
+
+	active := true
+	enabled, err := uc.UpdateWebhook(ctx, 1, hook.ID, dto.UpdateWebhookDTO{URL: receiver.URL, Events: hook.Events, Active: &active})
+	if err != nil || !enabled.Active || enabled.Failures != 0 || enabled.DisabledAt != nil {
+		t.Fatalf("UpdateWebhook = %+v, %v; want it re-enabled", enabled, err)
+	}
+
+	if delivered, _ := uc.Dispatch(ctx, now); delivered != 3 {
+		t.Fatalf("Dispatch delivered %d, want the 3 deliveries held while disabled", delivered)
+	}
+}
+
+func TestWebhookRefusesNonPublicAddresses(t *testing.T) {
+	receiver := startWebhookReceiver(t, http.StatusNoContent)
+	uc := NewWebhookUsecase(newMemWebhookRepository(), nil, NewTaskAccess(nil, nil))
+	ctx := context.Background()
+
+	// localhost is only known to be loopback once it is resolved.
+	hostURL := strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)
+	for _, url := range []string{receiver.URL, hostURL} {
+		hook, err := uc.CreateWebhook(ctx, 1, dto.CreateWebhookDTO{URL: url, Events: []string{EventTaskCreated}})
+		if err != nil {
+			t.Fatalf("CreateWebhook(%s): %v", url, err)
+		}
+		uc.Enqueue(ctx, taskEvent(EventTaskCreated, 7))
+
+		if delivered, err := uc.Dispatch(ctx, time.Now()); err != nil || delivered != 0 {
+			t.Fatalf("Dispatch to %s = %d, %v; want a refused attempt", url, delivered, err)
+		}
+		deliveries, err := uc.ListDeliveries(ctx, 1, hook.ID, 10)
+		if err != nil || len(deliveries) != 1 {
+			t.Fatalf("ListDeliveries = %v, %v; want one delivery", deliveries, err)
+		}
+		d, err := uc.GetDelivery(ctx, 1, hook.ID, deliveries[0].ID)
+		if err != nil || len(d.Log) != 1 || d.Log[0].ResponseCode != 0 || !strings.Contains(d.Log[0].Error, "non-public address") {
+			t.Fatalf("delivery %+v, %v; want one attempt refused before connecting", d, err)
+		}
+		uc.DeleteWebhook(ctx, 1, hook.ID)
+	}
+
+	if got := len(receiver.received()); got != 0 {
+		t.Fatalf("receiver got %d requests, want none", got)
+	}
+}
+
+func TestWebhookEventsAreValidated(t *testing.T) {
+	uc := NewWebhookUsecase(newMemWebhookRepository(), nil, NewTaskAccess(nil, nil))
+
+	_, err := uc.CreateWebhook(context.Background(), 1, dto.CreateWebhookDTO{URL: "https://example.com/hook", Events: []string{"task.created", "task.exploded"}})
+	if verr, ok := err.(*domain.ValidationError); !ok || verr.Field != "events[1]" {
+		t.Fatalf("err = %v, want a validation error on events[1]", err)
+	}
+
+	_, err = uc.CreateWebhook(context.Background(), 1, dto.CreateWebhookDTO{URL: "ftp://example.com/hook", Events: []string{"task.created"}})
+	if verr, ok := err.(*domain.ValidationError); !ok || verr.Field != "url" {
+		t.Fatalf("err = %v, want a validation error on url", err)
+	}
+}
@@ -0,0 +1,391 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"task-manager-api/domain"
+	"task-manager-api/dto"
+	"task-manager-api/repository"
+	"task-manager-api/validation"
+	"time"
+)
+
+// WorkspaceUsecase manages workspaces, their members and their projects.
+// Which tasks a member can see and change is decided by TaskAccess.
+type WorkspaceUsecase struct {
+	workspaces repository.WorkspaceRepository
+	users      repository.UserRepository
+	cache      Cache
+}
+
+func NewWorkspaceUsecase(workspaces repository.WorkspaceRepository, users repository.UserRepository, cache Cache) *WorkspaceUsecase {
+	return &WorkspaceUsecase{workspaces: workspaces, users: users, cache: cache}
+}
+
+// CreateWorkspace creates a workspace owned by the caller.
+func (u *WorkspaceUsecase) CreateWorkspace(ctx context.Context, userID int, req dto.WorkspaceDTO) (dto.WorkspaceResponseDTO, error) {
+	name, err := requiredName(req, req.Name)
+	if err != nil {
+		return dto.WorkspaceResponseDTO{}, err
+	}
+
+	workspace, err := u.workspaces.CreateWorkspace(domain.Workspace{Name: name}, userID)
+	if err != nil {
+		return dto.WorkspaceResponseDTO{}, err
+	}
+	return toWorkspaceResponse(workspace, domain.RoleOwner), nil
+}
+
+// ListWorkspaces returns the workspaces the caller belongs to.
+func (u *WorkspaceUsecase) ListWorkspaces(ctx context.Context, userID int) ([]dto.WorkspaceResponseDTO, error) {
+	workspaces, err := u.workspaces.ListWorkspaces(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WorkspaceResponseDTO, len(workspaces))
+	for i, workspace := range workspaces {
+		role, err := u.workspaces.MemberRole(workspace.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = toWorkspaceResponse(workspace, role)
+	}
+	return responses, nil
+}
+
+func (u *WorkspaceUsecase) ListMembers(ctx context.Context, userID, workspaceID int) ([]dto.WorkspaceMemberResponseDTO, error) {
+	if _, err := u.requireRole(workspaceID, userID, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := u.workspaces.ListMembers(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WorkspaceMemberResponseDTO, len(members))
+	for i, member := range members {
+		responses[i] = toMemberResponse(member)
+	}
+	return responses, nil
+}
+
+// SetMember adds a user to the workspace or changes their role. Admins manage
+// viewers and members; only owners can grant or take away admin and owner.
+func (u *WorkspaceUsecase) SetMember(ctx context.Context, userID, workspaceID int, req dto.WorkspaceMemberDTO) (dto.WorkspaceMemberResponseDTO, error) {
+	if err := validation.Validate(req); err != nil {
+		return dto.WorkspaceMemberResponseDTO{}, err
+	}
+
+	callerRole, err := u.requireRole(workspaceID, userID, domain.RoleAdmin)
+	if err != nil {
+		return dto.WorkspaceMemberResponseDTO{}, err
+	}
+
+	user, err := u.users.GetByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		if _, ok := err.(*domain.NotFoundError); ok {
+			return dto.WorkspaceMemberResponseDTO{}, &domain.ValidationError{Field: "email", Message: "no user has this email"}
+		}
+		return dto.WorkspaceMemberResponseDTO{}, err
+	}
+
+	currentRole, err := u.workspaces.MemberRole(workspaceID, user.ID)
+	if err != nil {
+		return dto.WorkspaceMemberResponseDTO{}, err
+	}
+	if err := checkRoleChange(callerRole, currentRole, req.Role); err != nil {
+		return dto.WorkspaceMemberResponseDTO{}, err
+	}
+	if currentRole == domain.RoleOwner && req.Role != domain.RoleOwner {
+		if err := u.keepAnOwner(workspaceID); err != nil {
+			return dto.WorkspaceMemberResponseDTO{}, err
+		}
+	}
+
+	member := domain.WorkspaceMember{WorkspaceID: workspaceID, UserID: user.ID, Email: user.Email, Role: req.Role}
+	if err := u.workspaces.SetMember(member); err != nil {
+		return dto.WorkspaceMemberResponseDTO{}, err
+	}
+	u.cache.InvalidateTag(memberTag(user.ID))
+
+	members, err := u.workspaces.ListMembers(workspaceID)
+	if err != nil {
+		return dto.WorkspaceMemberResponseDTO{}, err
+	}
+	for _, m := range members {
+		if m.UserID == user.ID {
+			return toMemberResponse(m), nil
+		}
+	}
+	return toMemberResponse(member), nil
+}
+
+// RemoveMember takes a user out of the workspace. Anyone may leave; removing
+// someone else follows the same rules as changing their role.
+func (u *WorkspaceUsecase) RemoveMember(ctx context.Context, userID, workspaceID, memberID int) error {
+	callerRole, err := u.requireRole(workspaceID, userID, domain.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	memberRole, err := u.workspaces.MemberRole(workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if memberRole == "" {
+		return &domain.NotFoundError{Resource: "Member", ID: memberID}
+	}
+
+	if memberID != userID {
+		if !domain.RoleAtLeast(callerRole, domain.RoleAdmin) {
+			return &domain.ForbiddenError{Message: "only admins can remove other members"}
+		}
+		if err := checkRoleChange(callerRole, memberRole, ""); err != nil {
+			return err
+		}
+	}
+	if memberRole == domain.RoleOwner {
+		if err := u.keepAnOwner(workspaceID); err != nil {
+			return err
+		}
+	}
+
+	if err := u.workspaces.RemoveMember(workspaceID, memberID); err != nil {
+		return err
+	}
+	u.cache.InvalidateTag(memberTag(memberID))
+	return nil
+}
+
+// checkRoleChange checks that a caller with callerRole may move a member from
+// one role to another; "" means not a member.
+func checkRoleChange(callerRole, from, to string) error {
+	if callerRole == domain.RoleOwner {
+		return nil
+	}
+	if domain.RoleAtLeast(from, domain.RoleAdmin) || domain.RoleAtLeast(to, domain.RoleAdmin) {
+		return &domain.ForbiddenError{Message: "only owners can manage admins and owners"}
+	}
+	return nil
+}
+
+// keepAnOwner refuses a change that would leave the workspace without an
+// owner.
+func (u *WorkspaceUsecase) keepAnOwner(workspaceID int) error {
+	owners, err := u.workspaces.CountOwners(workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return &domain.ValidationError{Field: "role", Message: "a workspace must keep at least one owner"}
+	}
+	return nil
+}
+
+func (u *WorkspaceUsecase) CreateProject(ctx context.Context, userID, workspaceID int, req dto.ProjectDTO) (dto.ProjectResponseDTO, error) {
+	if _, err := u.requireRole(workspaceID, userID, domain.RoleAdmin); err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	name, err := requiredName(req, req.Name)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+
+	project, err := u.workspaces.CreateProject(domain.Project{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	if err := u.invalidateMembers(workspaceID); err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+// ListProjects lists a workspace's projects, leaving out archived ones
+// unless includeArchived is set.
+func (u *WorkspaceUsecase) ListProjects(ctx context.Context, userID, workspaceID int, includeArchived bool) ([]dto.ProjectResponseDTO, error) {
+	if _, err := u.requireRole(workspaceID, userID, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	projects, err := u.workspaces.ListProjects(workspaceID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ProjectResponseDTO, len(projects))
+	for i, project := range projects {
+		responses[i] = toProjectResponse(project)
+	}
+	return responses, nil
+}
+
+func (u *WorkspaceUsecase) GetProject(ctx context.Context, userID, id int) (dto.ProjectResponseDTO, error) {
+	project, err := u.projectWithRole(userID, id, domain.RoleViewer)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+func (u *WorkspaceUsecase) UpdateProject(ctx context.Context, userID, id int, req dto.ProjectDTO) (dto.ProjectResponseDTO, error) {
+	project, err := u.projectWithRole(userID, id, domain.RoleAdmin)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	name, err := requiredName(req, req.Name)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+
+	project.Name = name
+	project.Description = req.Description
+
+	project, err = u.workspaces.UpdateProject(project)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+// DeleteProject deletes an empty project. Projects that still have tasks
+// must have them moved or deleted first, or be archived instead.
+func (u *WorkspaceUsecase) DeleteProject(ctx context.Context, userID, id int) error {
+	project, err := u.projectWithRole(userID, id, domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	count, err := u.workspaces.CountProjectTasks(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return &domain.ValidationError{Field: "project", Message: "project still has tasks; move or delete them, or archive the project"}
+	}
+
+	if err := u.workspaces.DeleteProject(id); err != nil {
+		return err
+	}
+	return u.invalidateMembers(project.WorkspaceID)
+}
+
+// SetArchived archives or unarchives a project. Its tasks stay readable while
+// archived but can't be changed.
+func (u *WorkspaceUsecase) SetArchived(ctx context.Context, userID, id int, archived bool) (dto.ProjectResponseDTO, error) {
+	project, err := u.projectWithRole(userID, id, domain.RoleAdmin)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	if project.Archived() == archived {
+		return toProjectResponse(project), nil
+	}
+
+	project.ArchivedAt = nil
+	if archived {
+		now := time.UnixMilli(time.Now().UnixMilli()).UTC()
+		project.ArchivedAt = &now
+	}
+
+	project, err = u.workspaces.UpdateProject(project)
+	if err != nil {
+		return dto.ProjectResponseDTO{}, err
+	}
+	return toProjectResponse(project), nil
+}
+
+// invalidateMembers drops what is cached about the workspace's members'
+// readable projects after a project is added or removed.
+func (u *WorkspaceUsecase) invalidateMembers(workspaceID int) error {
+	members, err := u.workspaces.ListMembers(workspaceID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		u.cache.InvalidateTag(memberTag(member.UserID))
+	}
+	return nil
+}
+
+// requireRole returns the caller's role in the workspace if it is at least
+// role. Non-members get NotFound, so workspace IDs don't leak.
+func (u *WorkspaceUsecase) requireRole(workspaceID, userID int, role string) (string, error) {
+	have, err := u.workspaces.MemberRole(workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+	if have == "" {
+		return "", &domain.NotFoundError{Resource: "Workspace", ID: workspaceID}
+	}
+	if !domain.RoleAtLeast(have, role) {
+		return "", &domain.ForbiddenError{Message: "this requires the " + role + " role in the workspace"}
+	}
+	return have, nil
+}
+
+func (u *WorkspaceUsecase) projectWithRole(userID, id int, role string) (domain.Project, error) {
+	project, err := u.workspaces.GetProject(id)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	_, err = u.requireRole(project.WorkspaceID, userID, role)
+	if _, ok := err.(*domain.NotFoundError); ok {
+		return domain.Project{}, &domain.NotFoundError{Resource: "Project", ID: id}
+	}
+	if err != nil {
+		return domain.Project{}, err
+	}
+	return project, nil
+}
+
+// requiredName validates req and returns name trimmed, rejecting names that
+// are only whitespace.
+func requiredName(req interface{}, name string) (string, error) {
+	if err := validation.Validate(req); err != nil {
+		return "", err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", &domain.ValidationError{Field: "name", Message: "is required"}
+	}
+	return name, nil
+}
+
+func toWorkspaceResponse(workspace domain.Workspace, role string) dto.WorkspaceResponseDTO {
+	return dto.WorkspaceResponseDTO{
+		ID:        workspace.ID,
+		Name:      workspace.Name,
+		Role:      role,
+		CreatedBy: workspace.CreatedBy,
+		CreatedAt: workspace.CreatedAt,
+	}
+}
+
+func toMemberResponse(member domain.WorkspaceMember) dto.WorkspaceMemberResponseDTO {
+	return dto.WorkspaceMemberResponseDTO{
+		UserID:   member.UserID,
+		Email:    member.Email,
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}
+}
+
+func toProjectResponse(project domain.Project) dto.ProjectResponseDTO {
+	return dto.ProjectResponseDTO{
+		ID:          project.ID,
+		WorkspaceID: project.WorkspaceID,
+		Name:        project.Name,
+		Description: project.Description,
+		Archived:    project.Archived(),
+		ArchivedAt:  project.ArchivedAt,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"task-manager-api/domain"
+)
+
+// MaxBodyBytes is the largest JSON request body DecodeJSON will accept.
+const MaxBodyBytes int64 = 1 << 20
+
+// DecodeJSON decodes a single JSON object from the request body into dst.
+// Unknown fields, trailing data and bodies larger than MaxBodyBytes are
+// rejected with domain errors that HandleError knows how to render.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return translateDecodeError(err)
+	}
+
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return &domain.ValidationError{Field: "body", Message: "must contain a single JSON object"}
+	}
+
+	return nil
+}
+
+func translateDecodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &domain.PayloadTooLargeError{Limit: maxBytesErr.Limit}
+	case errors.As(err, &syntaxErr):
+		return &domain.ValidationError{
+			Field:   "body",
+			Message: fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset),
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &domain.ValidationError{Field: "body", Message: "malformed JSON"}
+	case errors.Is(err, io.EOF):
+		return &domain.ValidationError{Field: "body", Message: "is required"}
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return &domain.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &domain.ValidationError{Field: field, Message: "is not a recognized field"}
+	default:
+		return &domain.ValidationError{Field: "body", Message: err.Error()}
+	}
+}
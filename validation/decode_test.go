@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"task-manager-api/domain"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		field   string // field of the expected ValidationError; empty when decoding succeeds
+		message string
+	}{
+		{name: "valid", body: `{"op":"create","task":{"title":"Write","priority":1}}`},
+		{name: "empty body", body: "", field: "body", message: "is required"},
+		{name: "unknown field", body: `{"op":"create","owner":1}`, field: "owner", message: "is not a recognized field"},
+		{name: "unknown nested field", body: `{"op":"create","task":{"title":"t","color":"red"}}`, field: "color", message: "is not a recognized field"},
+		{name: "wrong type", body: `{"op":"create","task":{"title":"t","priority":"high"}}`, field: "task.priority", message: "must be of type int"},
+		{name: "syntax error", body: `{"op":}`, field: "body", message: "malformed JSON at offset 7"},
+		{name: "truncated", body: `{"op":"create"`, field: "body", message: "malformed JSON"},
+		{name: "trailing data", body: `{"op":"create"}{"op":"update"}`, field: "body", message: "must contain a single JSON object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			var dst testOperation
+			err := DecodeJSON(httptest.NewRecorder(), req, &dst)
+
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("DecodeJSON = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *domain.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("DecodeJSON = %v (%T), want *domain.ValidationError", err, err)
+			}
+			if validationErr.Field != tt.field || validationErr.Message != tt.message {
+				t.Errorf("DecodeJSON = %s %q, want %s %q", validationErr.Field, validationErr.Message, tt.field, tt.message)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONRejectsOversizedBodies(t *testing.T) {
+	title := strings.Repeat("a", int(MaxBodyBytes))
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"op":"create","task":{"title":"`+title+`"}}`))
+	var dst testOperation
+
+	err := DecodeJSON(httptest.NewRecorder(), req, &dst)
+	var tooLarge *domain.PayloadTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("DecodeJSON = %v (%T), want *domain.PayloadTooLargeError", err, err)
+	}
+	if tooLarge.Limit != MaxBodyBytes {
+		t.Errorf("limit = %d, want %d", tooLarge.Limit, MaxBodyBytes)
+	}
+}
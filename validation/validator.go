@@ -0,0 +1,335 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"task-manager-api/domain"
+	"time"
+	"unicode/utf8"
+)
+
+// Validate checks every field of a struct against its `validate` tag and
+// returns a *domain.ValidationErrors listing all failures, or nil.
+//
+// Supported rules: required, omitempty, min, max, len, email, oneof, url and
+// datetime. Nested structs, pointers and slices are validated recursively and
+// reported with their JSON path (e.g. "operations[2].title").
+func Validate(v interface{}) error {
+	errs := &domain.ValidationErrors{}
+
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			errs.Add("body", "is required")
+			return errs
+		}
+		value = value.Elem()
+	}
+
+	validateStruct(value, "", errs)
+
+	if len(errs.Errors) == 0 {
+		return nil
+	}
+	return errs
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func validateStruct(value reflect.Value, prefix string, errs *domain.ValidationErrors) {
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		rules := parseRules(field.Tag.Get("validate"))
+
+		if !validateField(fieldValue, path, rules, errs) {
+			continue
+		}
+
+		validateNested(fieldValue, path, errs)
+	}
+}
+
+// validateNested descends into structs, pointers to structs and slices of
+// structs so that every DTO in a request is checked, not just the outer one.
+func validateNested(value reflect.Value, path string, errs *domain.ValidationErrors) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if !value.IsNil() {
+			validateNested(value.Elem(), path, errs)
+		}
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		validateStruct(value, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateNested(value.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// validateField applies the rules of a single field. It reports whether
+// validation should continue into nested values.
+func validateField(value reflect.Value, path string, rules []rule, errs *domain.ValidationErrors) bool {
+	if len(rules) == 0 {
+		return true
+	}
+
+	empty := isEmpty(value)
+
+	for _, r := range rules {
+		switch r.name {
+		case "omitempty":
+			if empty {
+				return false
+			}
+		case "required":
+			if empty {
+				errs.Add(path, "is required")
+				return false
+			}
+		default:
+			if message, ok := applyRule(value, r); !ok {
+				errs.Add(path, message)
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func applyRule(value reflect.Value, r rule) (string, bool) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", true
+		}
+		value = value.Elem()
+	}
+
+	switch r.name {
+	case "min", "max", "len":
+		return checkSize(value, r)
+	case "email":
+		return checkEmail(value.String())
+	case "oneof":
+		return checkOneOf(value, r.param)
+	case "url":
+		return checkURL(value.String())
+	case "datetime":
+		return checkDatetime(value.String(), r.param)
+	default:
+		panic(fmt.Sprintf("validation: unknown rule %q", r.name))
+	}
+}
+
+func checkSize(value reflect.Value, r rule) (string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		limit := mustInt(r)
+		length := utf8.RuneCountInString(value.String())
+		return compareSize(r.name, length, limit, "characters")
+	case reflect.Slice, reflect.Array, reflect.Map:
+		limit := mustInt(r)
+		return compareSize(r.name, value.Len(), limit, "items")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		limit := mustInt(r)
+		return compareNumber(r.name, float64(value.Int()), float64(limit))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		limit := mustInt(r)
+		return compareNumber(r.name, float64(value.Uint()), float64(limit))
+	case reflect.Float32, reflect.Float64:
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validation: invalid %s parameter %q", r.name, r.param))
+		}
+		return compareNumber(r.name, value.Float(), limit)
+	}
+	return "", true
+}
+
+func compareSize(ruleName string, length, limit int, unit string) (string, bool) {
+	switch ruleName {
+	case "min":
+		if length < limit {
+			return fmt.Sprintf("must be at least %d %s long", limit, unit), false
+		}
+	case "max":
+		if length > limit {
+			return fmt.Sprintf("must be at most %d %s long", limit, unit), false
+		}
+	case "len":
+		if length != limit {
+			return fmt.Sprintf("must be exactly %d %s long", limit, unit), false
+		}
+	}
+	return "", true
+}
+
+func compareNumber(ruleName string, number, limit float64) (string, bool) {
+	switch ruleName {
+	case "min":
+		if number < limit {
+			return fmt.Sprintf("must be at least %v", limit), false
+		}
+	case "max":
+		if number > limit {
+			return fmt.Sprintf("must be at most %v", limit), false
+		}
+	case "len":
+		if number != limit {
+			return fmt.Sprintf("must be exactly %v", limit), false
+		}
+	}
+	return "", true
+}
+
+func checkEmail(s string) (string, bool) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || !strings.Contains(addr.Address[strings.LastIndex(addr.Address, "@"):], ".") {
+		return "must be a valid email address", false
+	}
+	return "", true
+}
+
+func checkOneOf(value reflect.Value, param string) (string, bool) {
+	options := SplitOptions(param)
+
+	var actual string
+	switch value.Kind() {
+	case reflect.String:
+		actual = value.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = strconv.FormatUint(value.Uint(), 10)
+	default:
+		return "", true
+	}
+
+	for _, option := range options {
+		if actual == option {
+			return "", true
+		}
+	}
+	return fmt.Sprintf("must be one of: %s", strings.Join(options, ", ")), false
+}
+
+func checkURL(s string) (string, bool) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "must be an absolute URL", false
+	}
+	return "", true
+}
+
+func checkDatetime(s, layout string) (string, bool) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if _, err := time.Parse(layout, s); err != nil {
+		return fmt.Sprintf("must be a date/time in the format %s", layout), false
+	}
+	return "", true
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+func parseRules(tag string) []rule {
+	if tag == "" {
+		return nil
+	}
+
+	var rules []rule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, rule{name: name, param: param})
+	}
+	return rules
+}
+
+// SplitOptions splits a oneof parameter on spaces. Values containing spaces
+// can be wrapped in single quotes: oneof=Pending 'In Progress' Completed.
+func SplitOptions(param string) []string {
+	var options []string
+	var current strings.Builder
+	quoted := false
+
+	for _, ch := range param {
+		switch {
+		case ch == '\'':
+			quoted = !quoted
+		case ch == ' ' && !quoted:
+			if current.Len() > 0 {
+				options = append(options, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(ch)
+		}
+	}
+	if current.Len() > 0 {
+		options = append(options, current.String())
+	}
+	return options
+}
+
+func fieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func mustInt(r rule) int {
+	n, err := strconv.Atoi(r.param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid %s parameter %q", r.name, r.param))
+	}
+	return n
+}
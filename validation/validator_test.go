@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"reflect"
+	"task-manager-api/domain"
+	"testing"
+	"time"
+)
+
+type testTask struct {
+	Title    string     `json:"title" validate:"required,max=10"`
+	Status   string     `json:"status,omitempty" validate:"omitempty,oneof=Pending 'In Progress' Completed"`
+	Priority int        `json:"priority" validate:"min=0,max=3"`
+	Estimate *int       `json:"estimate,omitempty" validate:"omitempty,min=1"`
+	Email    string     `json:"email,omitempty" validate:"omitempty,email"`
+	Due      *time.Time `json:"due,omitempty"`
+	Untagged string
+}
+
+type testOperation struct {
+	Op   string    `json:"op" validate:"required,oneof=create update"`
+	Task *testTask `json:"task,omitempty"`
+}
+
+type testBatch struct {
+	Operations []testOperation `json:"operations" validate:"required,min=1,max=3"`
+}
+
+func TestValidate(t *testing.T) {
+	zero := 0
+	three := 3
+	valid := testTask{Title: "Write", Status: "In Progress", Priority: 2, Estimate: &three, Email: "a@example.com"}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  map[string]string // field path to message; nil when valid
+	}{
+		{name: "valid", value: valid},
+		{name: "valid through a pointer", value: &valid},
+		{name: "nil pointer", value: (*testTask)(nil), want: map[string]string{"body": "is required"}},
+		{
+			name:  "required and max",
+			value: testTask{Title: "", Priority: 4},
+			want:  map[string]string{"title": "is required", "priority": "must be at most 3"},
+		},
+		{
+			name:  "max counts characters, not bytes",
+			value: testTask{Title: "ééééééééé"},
+		},
+		{
+			name:  "max on strings",
+			value: testTask{Title: "eleven char"},
+			want:  map[string]string{"title": "must be at most 10 characters long"},
+		},
+		{
+			name:  "oneof accepts a quoted option with a space",
+			value: testTask{Title: "t", Status: "In Progress"},
+		},
+		{
+			name:  "oneof lists the options unquoted",
+			value: testTask{Title: "t", Status: "in_progress"},
+			want:  map[string]string{"status": "must be one of: Pending, In Progress, Completed"},
+		},
+		{
+			name:  "omitempty skips empty strings and nil pointers",
+			value: testTask{Title: "t", Status: "", Email: "", Estimate: nil},
+		},
+		{
+			name:  "omitempty still checks a pointer to zero",
+			value: testTask{Title: "t", Estimate: &zero},
+			want:  map[string]string{"estimate": "must be at least 1"},
+		},
+		{
+			name:  "email",
+			value: testTask{Title: "t", Email: "Alice <a@example.com>"},
+			want:  map[string]string{"email": "must be a valid email address"},
+		},
+		{
+			name: "nested paths use JSON names and indexes",
+			value: testBatch{Operations: []testOperation{
+				{Op: "create", Task: &testTask{Title: "ok"}},
+				{Op: "delete"},
+				{Op: "update", Task: &testTask{Title: "", Status: "Done"}},
+			}},
+			want: map[string]string{
+				"operations[1].op":          "must be one of: create, update",
+				"operations[2].task.title":  "is required",
+				"operations[2].task.status": "must be one of: Pending, In Progress, Completed",
+			},
+		},
+		{
+			name:  "required slice",
+			value: testBatch{},
+			want:  map[string]string{"operations": "is required"},
+		},
+		{
+			name:  "max on slices",
+			value: testBatch{Operations: make([]testOperation, 4)},
+			want:  map[string]string{"operations": "must be at most 3 items long"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.value)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+
+			errs, ok := err.(*domain.ValidationErrors)
+			if !ok {
+				t.Fatalf("Validate = %v (%T), want *domain.ValidationErrors", err, err)
+			}
+			got := make(map[string]string)
+			for _, fieldErr := range errs.Errors {
+				got[fieldErr.Field] = fieldErr.Message
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Validate errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitOptions(t *testing.T) {
+	tests := []struct {
+		param string
+		want  []string
+	}{
+		{"a b c", []string{"a", "b", "c"}},
+		{"Pending 'In Progress' Completed", []string{"Pending", "In Progress", "Completed"}},
+		{"  a   b ", []string{"a", "b"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := SplitOptions(tt.param); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitOptions(%q) = %q, want %q", tt.param, got, tt.want)
+		}
+	}
+}